@@ -0,0 +1,47 @@
+package main
+
+import (
+	routes "okusuri-backend/internal"
+	"okusuri-backend/pkg/config"
+	"okusuri-backend/pkg/logger"
+	"okusuri-shared/clock"
+	_ "time/tzdata" // 実行環境にタイムゾーンデータがなくてもユーザーのタイムゾーンを読み込めるようにする
+
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	// ログ初期化
+	logger.InitLogger()
+
+	log.Info().Msg("アプリケーションを開始します")
+
+	// リポジトリの初期化
+	var deps routes.Dependencies
+	switch backend := config.GetRepositoryBackend(); backend {
+	case config.RepositoryBackendMemory:
+		deps = routes.NewMemoryDependencies(clock.System())
+		log.Warn().Msg("インメモリリポジトリを使用します（データは再起動で失われます）")
+	case config.RepositoryBackendDynamoDB:
+		// DynamoDB接続
+		config.SetupDB()
+		log.Info().Msg("DynamoDB接続が完了しました")
+		deps = routes.NewDynamoDependencies(config.GetDB(), clock.System())
+	default:
+		log.Fatal().Str("backend", backend).Msg("不明なリポジトリ種別です")
+	}
+
+	// Ginのルーターを作成
+	router := routes.SetupRoutes(deps)
+	log.Info().Msg("ルーター設定が完了しました")
+
+	// ポート設定（Lambda Web Adapter対応）
+	port := config.GetPort()
+
+	log.Info().Str("port", port).Msg("サーバーを起動します")
+
+	// サーバーを起動
+	if err := router.Run(":" + port); err != nil {
+		log.Fatal().Err(err).Msg("サーバーの起動に失敗しました")
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "okusuri-shared/status"
+
+// 休薬に入った時期
+const (
+	BreakTimingOnTime = string(status.BreakTimingOnTime) // レジメンどおり
+	BreakTimingEarly  = string(status.BreakTimingEarly)  // 休薬期間の前から服用していない
+	BreakTimingLate   = string(status.BreakTimingLate)   // 休薬期間に入ってからも服用した
+)
+
+// CycleResponse は服用期間とそれに続く休薬期間からなる1周期
+type CycleResponse struct {
+	StartDate            string   `json:"startDate"`               // 服用期間の初日（YYYY-MM-DD形式）
+	IntakeDays           int      `json:"intakeDays"`              // 服用期間の日数
+	TakenDays            int      `json:"takenDays"`               // 服用期間のうち服用した日数
+	TriggerBleedingDates []string `json:"triggerBleedingDates"`    // 休薬のきっかけとなった連続出血の日付（出血で休薬に入った場合）
+	RestStartDate        string   `json:"restStartDate,omitempty"` // 休薬期間の初日（まだ休薬に入っていない場合は省略）
+	RestEndDate          string   `json:"restEndDate,omitempty"`   // 休薬期間の最終日（休薬中の場合は当日、まだ休薬に入っていない場合は省略）
+	BreakTiming          string   `json:"breakTiming,omitempty"`   // 休薬に入った時期（on_time / early / late、まだ休薬に入っていない場合は省略）
+	BreakTimingDays      int      `json:"breakTimingDays"`         // 休薬期間より前に服用をやめた日数、または休薬期間に入ってから服用した日数
+	Ongoing              bool     `json:"ongoing"`                 // 進行中の周期かどうか
+}
+
+// CycleListResponse は周期一覧のレスポンス
+type CycleListResponse struct {
+	Cycles []CycleResponse `json:"cycles"` // 古い順
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// 在庫の登録リクエスト
+// 手持ちの錠数は packSize × packsOnHand + loosePills で登録する
+type InventoryRequest struct {
+	Name               string `json:"name" binding:"required,max=50"`                      // 薬の名前
+	PackSize           int    `json:"packSize" binding:"required,min=1,max=366"`           // 1箱（1シート）あたりの錠数
+	PacksOnHand        int    `json:"packsOnHand" binding:"min=0,max=100"`                 // 未開封の箱数
+	LoosePills         int    `json:"loosePills" binding:"min=0,max=366"`                  // 開封済みの箱に残っている錠数
+	PillsPerDose       int    `json:"pillsPerDose" binding:"min=0,max=10"`                 // 1回に服用する錠数（省略時は1）
+	RefillReminderDays *int   `json:"refillReminderDays" binding:"omitempty,min=0,max=60"` // 手持ちがなくなる何日前に補充のリマインダーを送るか（省略時は7、0の場合は送らない）
+}
+
+// 在庫レスポンス
+type InventoryResponse struct {
+	MedicationID   string `json:"medicationId"`
+	Name           string `json:"name"`
+	PackSize       int    `json:"packSize"`
+	PillsPerDose   int    `json:"pillsPerDose"`
+	RemainingPills int    `json:"remainingPills"` // 手持ちの錠数
+	PacksOnHand    int    `json:"packsOnHand"`    // 手持ちの錠数を1箱ずつに分けた場合の箱数
+	LoosePills     int    `json:"loosePills"`     // 箱に満たない端数の錠数
+	DosesLeft      int    `json:"dosesLeft"`      // 手持ちで服用できる回数
+	// LastDoseDate は手持ちで服用できる最後の日（YYYY-MM-DD形式、1回分もない場合は省略）
+	LastDoseDate string `json:"lastDoseDate,omitempty"`
+	// RunOutDate は今後の休薬期間を考慮して予測した、手持ちがなく服用できなくなる最初の日（YYYY-MM-DD形式、1年以内になくならない場合は省略）
+	RunOutDate         string `json:"runOutDate,omitempty"`
+	DaysUntilRunOut    *int   `json:"daysUntilRunOut,omitempty"` // 当日からRunOutDateまでの日数
+	RefillReminderDays int    `json:"refillReminderDays"`
+	// RefillReminderDate は補充のリマインダーを送る予定日（YYYY-MM-DD形式、送らない場合は省略）
+	RefillReminderDate string    `json:"refillReminderDate,omitempty"`
+	RefillReminderSent bool      `json:"refillReminderSent"` // 在庫を登録してから補充のリマインダーを送ったかどうか
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
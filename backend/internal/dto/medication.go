@@ -1,9 +1,41 @@
 package dto
 
-import "time"
+import (
+	"okusuri-backend/internal/model"
+	"time"
+)
 
 // 服用記録リクエスト
+// bleedingSeverityを省略した場合はhasBleedingから判定する（出血ありは中等量として扱う）
 type MedicationLogRequest struct {
-	HasBleeding bool       `json:"hasBleeding"`
-	Date        *time.Time `json:"date,omitempty"` // 指定された日付（省略時は現在日時）
+	HasBleeding      bool       `json:"hasBleeding"`
+	BleedingSeverity string     `json:"bleedingSeverity,omitempty"` // 出血の程度（none / spotting / light / moderate / heavy）
+	Date             *time.Time `json:"date,omitempty"`             // 指定された日付（省略時は現在日時）
+}
+
+// 服用記録更新リクエスト（省略した項目は変更しない）
+type MedicationLogUpdateRequest struct {
+	HasBleeding      *bool      `json:"hasBleeding,omitempty"`
+	BleedingSeverity *string    `json:"bleedingSeverity,omitempty"` // 出血の程度（指定した場合はhasBleedingより優先する）
+	Date             *time.Time `json:"date,omitempty"`             // 指定した場合はその日付へ記録を移動する
+}
+
+// 服用記録レスポンス
+type MedicationLogResponse struct {
+	BaseResponse
+	Log *model.MedicationLog `json:"log,omitempty"`
+}
+
+// 服用記録一覧の検索条件
+type MedicationLogListQuery struct {
+	From   string `form:"from"`   // 開始日（YYYY-MM-DD形式）
+	To     string `form:"to"`     // 終了日（YYYY-MM-DD形式）
+	Limit  int    `form:"limit"`  // 1ページあたりの件数
+	Cursor string `form:"cursor"` // 次ページ取得用のカーソル
+}
+
+// 服用記録一覧レスポンス
+type MedicationLogListResponse struct {
+	Logs       []model.MedicationLog `json:"logs"`
+	NextCursor string                `json:"nextCursor,omitempty"` // 続きがない場合は省略
 }
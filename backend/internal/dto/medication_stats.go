@@ -0,0 +1,34 @@
+package dto
+
+// MedicationStatsQuery は服薬統計の集計期間
+type MedicationStatsQuery struct {
+	From string `form:"from"` // 開始日（YYYY-MM-DD形式、ユーザーのタイムゾーン、省略時は最初の服用日）
+	To   string `form:"to"`   // 終了日（YYYY-MM-DD形式、ユーザーのタイムゾーン、省略時は当日）
+}
+
+// 服薬統計レスポンス
+type MedicationStatsResponse struct {
+	From                string                        `json:"from"`                // 集計の開始日（YYYY-MM-DD形式）
+	To                  string                        `json:"to"`                  // 集計の終了日（YYYY-MM-DD形式、当日より後の日は集計しない）
+	LongestStreak       int                           `json:"longestStreak"`       // 最長連続服用日数
+	BleedingDays        int                           `json:"bleedingDays"`        // 出血があった日数
+	MonthlyBleedingDays []MonthlyBleedingDaysResponse `json:"monthlyBleedingDays"` // 月ごとの出血日数
+	RestPeriodCount     int                           `json:"restPeriodCount"`     // 休薬期間の回数
+	RestPeriods         []RestPeriodResponse          `json:"restPeriods"`         // 休薬期間（古い順）
+	AverageCycleLength  float64                       `json:"averageCycleLength"`  // 平均周期（休薬開始日の間隔の平均日数、休薬期間が2回未満の場合は0）
+	ScheduledDays       int                           `json:"scheduledDays"`       // 服用期間だった日数（当日は服用済みの場合だけ数える）
+	TakenDays           int                           `json:"takenDays"`           // そのうち服用した日数
+	AdherenceRate       float64                       `json:"adherenceRate"`       // 服用率（%）
+}
+
+// MonthlyBleedingDaysResponse は月ごとの出血日数
+type MonthlyBleedingDaysResponse struct {
+	Month string `json:"month"` // YYYY-MM形式
+	Days  int    `json:"days"`
+}
+
+// RestPeriodResponse は休薬期間
+type RestPeriodResponse struct {
+	StartDate string `json:"startDate"` // 休薬期間の初日（YYYY-MM-DD形式）
+	EndDate   string `json:"endDate"`   // 休薬期間の最終日（YYYY-MM-DD形式、休薬中の場合は当日）
+}
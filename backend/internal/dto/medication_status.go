@@ -1,9 +1,20 @@
 package dto
 
+import "okusuri-shared/status"
+
+// 服薬フェーズ
+const (
+	PhaseIntake = string(status.PhaseIntake) // 服用期間
+	PhaseRest   = string(status.PhaseRest)   // 休薬期間
+)
+
 // 服薬ステータスレスポンス
 type MedicationStatusResponse struct {
-	CurrentStreak           int  `json:"currentStreak"`           // 現在の連続服用日数
-	IsRestPeriod            bool `json:"isRestPeriod"`            // 休薬期間中かどうか
-	RestDaysLeft            int  `json:"restDaysLeft"`            // 休薬期間の残り日数（休薬期間中の場合）
-	ConsecutiveBleedingDays int  `json:"consecutiveBleedingDays"` // 連続出血日数
+	CurrentStreak           int    `json:"currentStreak"`           // 現在の連続服用日数
+	IsRestPeriod            bool   `json:"isRestPeriod"`            // 休薬期間中かどうか
+	RestDaysLeft            int    `json:"restDaysLeft"`            // 休薬期間の残り日数（休薬期間中の場合）
+	ConsecutiveBleedingDays int    `json:"consecutiveBleedingDays"` // 連続出血日数
+	RegimenType             string `json:"regimenType"`             // 適用されているレジメンの種類
+	Phase                   string `json:"phase"`                   // 現在のフェーズ（intake / rest）
+	NextRestDate            string `json:"nextRestDate,omitempty"`  // 次の休薬開始予定日（YYYY-MM-DD形式、予測できない場合は省略）
 }
@@ -2,16 +2,70 @@ package dto
 
 // NotificationSettingRequest は通知設定のリクエスト用DTO
 type NotificationSettingRequest struct {
-	Platform     string `json:"platform" binding:"required"` // "web", "mobile"等
-	IsEnabled    bool   `json:"isEnabled"`                   // 通知の有効/無効
-	Subscription string `json:"subscription,omitempty"`      // WebPush用のサブスクリプション
+	Platform      string   `json:"platform" binding:"required"`  // "web", "mobile"等
+	IsEnabled     bool     `json:"isEnabled"`                    // 通知の有効/無効
+	Subscription  string   `json:"subscription,omitempty"`       // WebPush用のサブスクリプション
+	ReminderTimes []string `json:"reminderTimes,omitempty"`      // リマインダー時刻（HH:MM形式・5分単位、未指定の場合は09:00）
+	Weekdays      []int    `json:"weekdays,omitempty"`           // リマインダーを送る曜日（0=日曜日〜6=土曜日、未指定の場合は毎日）
+	DeviceLabel   string   `json:"deviceLabel" binding:"max=50"` // サブスクリプションを登録するデバイスの表示名（未指定の場合は登録済みの名前）
 }
 
 // NotificationSettingResponse は通知設定のレスポンス用DTO
 type NotificationSettingResponse struct {
-	Platform     string `json:"platform"`
-	IsEnabled    bool   `json:"isEnabled"`
-	Subscription string `json:"subscription,omitempty"`
-	CreatedAt    string `json:"createdAt"`
-	UpdatedAt    string `json:"updatedAt"`
+	Platform      string   `json:"platform"`
+	IsEnabled     bool     `json:"isEnabled"`
+	Subscription  string   `json:"subscription,omitempty"`
+	ReminderTimes []string `json:"reminderTimes"`
+	Weekdays      []int    `json:"weekdays"`
+	// DisabledReason は通知Lambdaが通知を無効化した理由（再度購読して設定を保存すると消える）
+	DisabledReason string `json:"disabledReason,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// NotificationDeviceResponse は通知先のデバイスのレスポンス用DTO
+// サブスクリプションには暗号鍵が含まれるため返さない
+type NotificationDeviceResponse struct {
+	ID             string `json:"id"`
+	Platform       string `json:"platform"`
+	Label          string `json:"label"`
+	IsEnabled      bool   `json:"isEnabled"`
+	DisabledReason string `json:"disabledReason,omitempty"`
+	LastSeenAt     string `json:"lastSeenAt"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// NotificationHistoryQuery は送信履歴の検索条件
+type NotificationHistoryQuery struct {
+	From   string `form:"from"`   // 開始日（YYYY-MM-DD形式、ユーザーのタイムゾーン）
+	To     string `form:"to"`     // 終了日（YYYY-MM-DD形式、ユーザーのタイムゾーン）
+	Limit  int    `form:"limit"`  // 1ページあたりの件数
+	Cursor string `form:"cursor"` // 次ページ取得用のカーソル
+}
+
+// NotificationLogResponse は通知1件の送信履歴のレスポンス用DTO
+type NotificationLogResponse struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`    // reminder / follow_up
+	Outcome     string `json:"outcome"` // sent / expired / failed / skipped
+	Platform    string `json:"platform"`
+	DeviceID    string `json:"deviceId,omitempty"`
+	DeviceLabel string `json:"deviceLabel,omitempty"`
+	Message     string `json:"message"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Attempts    int    `json:"attempts"`
+	Reason      string `json:"reason,omitempty"`
+	LatencyMs   int64  `json:"latencyMs"`
+	SentAt      string `json:"sentAt"`
+}
+
+// NotificationHistoryResponse は送信履歴一覧のレスポンス用DTO
+type NotificationHistoryResponse struct {
+	History    []NotificationLogResponse `json:"history"`
+	NextCursor string                    `json:"nextCursor,omitempty"` // 続きがない場合は省略
+}
+
+// NotificationDeviceListResponse は通知先のデバイス一覧のレスポンス用DTO
+type NotificationDeviceListResponse struct {
+	Devices []NotificationDeviceResponse `json:"devices"`
 }
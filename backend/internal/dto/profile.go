@@ -0,0 +1,14 @@
+package dto
+
+// ProfileRequest はプロフィール登録/更新のリクエスト用DTO
+type ProfileRequest struct {
+	Timezone string `json:"timezone" binding:"required"` // IANAタイムゾーン名（例: Asia/Tokyo）
+}
+
+// ProfileResponse はプロフィールのレスポンス用DTO
+type ProfileResponse struct {
+	Timezone  string `json:"timezone"`
+	IsDefault bool   `json:"isDefault"` // 未登録でデフォルトのタイムゾーンが適用されている場合はtrue
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
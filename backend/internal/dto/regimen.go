@@ -0,0 +1,37 @@
+package dto
+
+// RegimenRequest はレジメン登録/更新のリクエスト用DTO
+// templateを指定した場合は組み込みテンプレートの設定値を使用し、cycleStartDateとbleedingThreshold以外の項目は無視する
+type RegimenRequest struct {
+	Template                string `json:"template,omitempty"`                              // 組み込みテンプレート名
+	Type                    string `json:"type,omitempty"`                                  // レジメンの種類
+	RestPeriodDays          int    `json:"restPeriodDays" binding:"min=0,max=14"`           // 休薬期間の日数
+	BleedingTriggerDays     int    `json:"bleedingTriggerDays" binding:"min=0,max=14"`      // 休薬に入る連続出血日数
+	MinIntakeDaysBeforeRest int    `json:"minIntakeDaysBeforeRest" binding:"min=0,max=365"` // 休薬を開始できる最低連続服用日数
+	MaxContinuousDays       int    `json:"maxContinuousDays" binding:"min=0,max=365"`       // 強制的に休薬に入る連続服用日数
+	ActiveDays              int    `json:"activeDays" binding:"min=0,max=84"`               // 1周期あたりの服用日数
+	CycleStartDate          string `json:"cycleStartDate,omitempty"`                        // 周期の起点日（YYYY-MM-DD形式）
+	BleedingThreshold       string `json:"bleedingThreshold,omitempty"`                     // 休薬に入る連続出血として数える出血の最低の程度
+}
+
+// RegimenResponse はレジメンのレスポンス用DTO
+type RegimenResponse struct {
+	Type                    string `json:"type"`
+	RestPeriodDays          int    `json:"restPeriodDays"`
+	BleedingTriggerDays     int    `json:"bleedingTriggerDays"`
+	MinIntakeDaysBeforeRest int    `json:"minIntakeDaysBeforeRest"`
+	MaxContinuousDays       int    `json:"maxContinuousDays"`
+	ActiveDays              int    `json:"activeDays"`
+	CycleStartDate          string `json:"cycleStartDate,omitempty"`
+	BleedingThreshold       string `json:"bleedingThreshold,omitempty"` // 省略時は点状出血を含む全ての出血を数える
+	IsDefault               bool   `json:"isDefault"`                   // 未設定でデフォルトのルールが適用されている場合はtrue
+	CreatedAt               string `json:"createdAt,omitempty"`
+	UpdatedAt               string `json:"updatedAt,omitempty"`
+}
+
+// RegimenTemplateResponse は組み込みテンプレートのレスポンス用DTO
+type RegimenTemplateResponse struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Regimen     RegimenResponse `json:"regimen"`
+}
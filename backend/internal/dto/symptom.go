@@ -0,0 +1,67 @@
+package dto
+
+import "okusuri-backend/internal/model"
+
+// 症状の登録リクエスト
+type SymptomRequest struct {
+	Date      string `json:"date"`                                      // 症状があった日（YYYY-MM-DD形式、省略時はユーザーのタイムゾーンの当日）
+	Type      string `json:"type" binding:"required"`                   // 症状の種類（headache / cramps / mood_swings / nausea / other）
+	Intensity *int   `json:"intensity" binding:"required,min=0,max=10"` // 症状の強さ（0〜10）
+	Note      string `json:"note" binding:"max=500"`                    // 自由記述のメモ
+}
+
+// 症状の更新リクエスト（省略した項目は変更しない）
+type SymptomUpdateRequest struct {
+	Date      *string `json:"date,omitempty"`                                       // 指定した場合はその日付へ症状を移動する
+	Type      *string `json:"type,omitempty"`                                       // 症状の種類
+	Intensity *int    `json:"intensity,omitempty" binding:"omitempty,min=0,max=10"` // 症状の強さ（0〜10）
+	Note      *string `json:"note,omitempty" binding:"omitempty,max=500"`           // 自由記述のメモ（空文字で削除）
+}
+
+// 症状レスポンス
+type SymptomResponse struct {
+	BaseResponse
+	Symptom *model.Symptom `json:"symptom,omitempty"`
+}
+
+// 症状一覧の検索条件
+type SymptomListQuery struct {
+	From   string `form:"from"`   // 開始日（YYYY-MM-DD形式）
+	To     string `form:"to"`     // 終了日（YYYY-MM-DD形式）
+	Limit  int    `form:"limit"`  // 1ページあたりの件数
+	Cursor string `form:"cursor"` // 次ページ取得用のカーソル
+}
+
+// 症状一覧レスポンス
+type SymptomListResponse struct {
+	Symptoms   []model.Symptom `json:"symptoms"`
+	NextCursor string          `json:"nextCursor,omitempty"` // 続きがない場合は省略
+}
+
+// SymptomSummaryQuery は症状の集計期間
+type SymptomSummaryQuery struct {
+	From string `form:"from"` // 開始日（YYYY-MM-DD形式、ユーザーのタイムゾーン、省略時は最初に症状を記録した日）
+	To   string `form:"to"`   // 終了日（YYYY-MM-DD形式、ユーザーのタイムゾーン、省略時は当日）
+}
+
+// 症状のフェーズ別集計レスポンス
+type SymptomSummaryResponse struct {
+	From   string                        `json:"from"`   // 集計の開始日（YYYY-MM-DD形式）
+	To     string                        `json:"to"`     // 集計の終了日（YYYY-MM-DD形式、当日より後の日は集計しない）
+	Phases []SymptomPhaseSummaryResponse `json:"phases"` // フェーズごとの集計（服用期間・休薬期間の順）
+}
+
+// SymptomPhaseSummaryResponse は1つのフェーズの症状の集計
+type SymptomPhaseSummaryResponse struct {
+	Phase    string                     `json:"phase"`    // intake / rest
+	Days     int                        `json:"days"`     // 期間内でこのフェーズだった日数
+	Symptoms []SymptomFrequencyResponse `json:"symptoms"` // 記録があった症状の種類ごとの頻度
+}
+
+// SymptomFrequencyResponse は症状の種類ごとの頻度
+type SymptomFrequencyResponse struct {
+	Type             string  `json:"type"`
+	Count            int     `json:"count"`            // 記録の件数
+	Days             int     `json:"days"`             // 症状があった日数
+	AverageIntensity float64 `json:"averageIntensity"` // 強さの平均
+}
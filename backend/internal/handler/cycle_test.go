@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycles(t *testing.T) {
+	// 2025-09-01 8:00（日本時間）から毎朝服用し、9/21〜9/23の出血で休薬する
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), medicationRoutes, regimenRoutes)
+
+	getCycles := func(t *testing.T) []dto.CycleResponse {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/cycles", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res dto.CycleListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res.Cycles
+	}
+
+	t.Run("記録がない場合は空の一覧を返す", func(t *testing.T) {
+		assert.Empty(t, getCycles(t))
+	})
+
+	takeDays(t, router, clk, 20, false)
+	takeDays(t, router, clk, 3, true)
+	clk.AdvanceDays(2) // 9/26
+	takeDays(t, router, clk, 5, false)
+
+	cycles := getCycles(t)
+	require.Len(t, cycles, 2)
+	assert.Equal(t, dto.CycleResponse{
+		StartDate:            "2025-09-01",
+		IntakeDays:           22,
+		TakenDays:            22,
+		TriggerBleedingDates: []string{"2025-09-21", "2025-09-22", "2025-09-23"},
+		RestStartDate:        "2025-09-23",
+		RestEndDate:          "2025-09-25",
+		BreakTiming:          dto.BreakTimingOnTime,
+	}, cycles[0])
+	assert.Equal(t, dto.CycleResponse{
+		StartDate:            "2025-09-26",
+		IntakeDays:           6,
+		TakenDays:            5,
+		TriggerBleedingDates: []string{},
+		Ongoing:              true,
+	}, cycles[1], "まだ休薬に入っていない周期は休薬期間を省略する")
+
+	t.Run("周期投与では休薬期間に入ってからの服用を後ろ倒しとする", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/regimen",
+			`{"type":"fixed_cycle","activeDays":21,"restPeriodDays":7,"cycleStartDate":"2025-09-01"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		cycles := getCycles(t)
+		require.Len(t, cycles, 2)
+		assert.Equal(t, "2025-09-22", cycles[0].RestStartDate)
+		assert.Equal(t, "2025-09-28", cycles[0].RestEndDate)
+		assert.Equal(t, dto.BreakTimingLate, cycles[0].BreakTiming)
+		assert.Equal(t, 2, cycles[0].BreakTimingDays, "9/22・9/23に服用した")
+		assert.Empty(t, cycles[0].TriggerBleedingDates, "周期投与は出血で休薬に入らない")
+		assert.Equal(t, "2025-09-29", cycles[1].StartDate)
+	})
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/errors"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type InventoryHandler struct {
+	inventoryRepo    repository.InventoryRepository
+	inventoryService *service.InventoryService
+	clock            clock.Clock
+}
+
+func NewInventoryHandler(inventoryRepo repository.InventoryRepository, inventoryService *service.InventoryService, clk clock.Clock) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryRepo:    inventoryRepo,
+		inventoryService: inventoryService,
+		clock:            clk,
+	}
+}
+
+// ListInventories はユーザーの全ての薬の在庫と手持ちがなくなる日の予測を取得するハンドラー
+func (h *InventoryHandler) ListInventories(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	inventories, err := h.inventoryService.ListInventories(c.Request.Context(), userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "在庫取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inventories)
+}
+
+// GetInventory は薬の在庫と手持ちがなくなる日の予測を取得するハンドラー
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// URLから薬のIDを取得
+	medicationID := c.Param("medicationId")
+	if !helper.IsValidID(medicationID) {
+		errors.HandleBadRequest(c, "無効な薬のIDです", nil)
+		return
+	}
+
+	inventory, err := h.inventoryService.GetInventory(c.Request.Context(), userID, medicationID)
+	if err != nil {
+		if stderrors.Is(err, repository.ErrInventoryNotFound) {
+			errors.HandleNotFound(c, "在庫が登録されていません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "在庫取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inventory)
+}
+
+// AddInventory は薬を追加して在庫を登録するハンドラー
+func (h *InventoryHandler) AddInventory(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.InventoryRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+
+	now := h.clock.Now()
+	inventory := toInventory(req)
+	inventory.MedicationID = helper.NewID(now)
+	inventory.CreatedAt = now
+	inventory.UpdatedAt = now
+
+	h.saveInventory(c, userID, inventory)
+}
+
+// SaveInventory は薬の在庫を登録し直すハンドラー
+// 手持ちの錠数を登録し直すため、補充のリマインダーは再び送信されるようになる
+func (h *InventoryHandler) SaveInventory(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// URLから薬のIDを取得
+	medicationID := c.Param("medicationId")
+	if !helper.IsValidID(medicationID) {
+		errors.HandleBadRequest(c, "無効な薬のIDです", nil)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.InventoryRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+
+	// 登録済みの在庫の作成日時を引き継ぐ
+	existing, err := h.inventoryRepo.GetInventory(c.Request.Context(), userID, medicationID)
+	if err != nil {
+		if stderrors.Is(err, repository.ErrInventoryNotFound) {
+			errors.HandleNotFound(c, "在庫が登録されていません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "在庫取得", err)
+		return
+	}
+
+	inventory := toInventory(req)
+	inventory.MedicationID = medicationID
+	inventory.CreatedAt = existing.CreatedAt
+	inventory.UpdatedAt = h.clock.Now()
+
+	h.saveInventory(c, userID, inventory)
+}
+
+// DeleteInventory は薬の在庫を削除するハンドラー
+func (h *InventoryHandler) DeleteInventory(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// URLから薬のIDを取得
+	medicationID := c.Param("medicationId")
+	if !helper.IsValidID(medicationID) {
+		errors.HandleBadRequest(c, "無効な薬のIDです", nil)
+		return
+	}
+
+	if err := h.inventoryRepo.DeleteInventory(c.Request.Context(), userID, medicationID); err != nil {
+		if stderrors.Is(err, repository.ErrInventoryNotFound) {
+			errors.HandleNotFound(c, "在庫が登録されていません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "在庫削除", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "inventory deleted successfully",
+	})
+}
+
+// saveInventory は在庫を保存し、手持ちがなくなる日の予測を加えてレスポンスを返す
+func (h *InventoryHandler) saveInventory(c *gin.Context, userID string, inventory model.Inventory) {
+	ctx := c.Request.Context()
+	if err := h.inventoryRepo.SaveInventory(ctx, userID, inventory); err != nil {
+		errors.HandleDatabaseError(c, "在庫保存", err)
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("medication_id", inventory.MedicationID).
+		Int("pack_size", inventory.PackSize).
+		Int("remaining_pills", inventory.RemainingPills).
+		Int("pills_per_dose", inventory.PillsPerDose).
+		Int("refill_reminder_days", inventory.RefillReminderDays).
+		Msg("在庫を保存しました")
+
+	response, err := h.inventoryService.GetInventory(ctx, userID, inventory.MedicationID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "在庫取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// toInventory は登録リクエストから在庫を作成する（手持ちの錠数は箱数と端数から計算する）
+func toInventory(req dto.InventoryRequest) model.Inventory {
+	inventory := model.Inventory{
+		Name:               req.Name,
+		PackSize:           req.PackSize,
+		PillsPerDose:       req.PillsPerDose,
+		RefillReminderDays: model.DefaultRefillReminderDays,
+	}
+	inventory.SetRemainingPills(req.PackSize*req.PacksOnHand + req.LoosePills)
+	if inventory.PillsPerDose == 0 {
+		inventory.PillsPerDose = 1
+	}
+	if req.RefillReminderDays != nil {
+		inventory.RefillReminderDays = *req.RefillReminderDays
+	}
+	return inventory
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inventoryRoutes は在庫のルートを登録する
+func inventoryRoutes(router *gin.Engine, d *testDeps) {
+	h := NewInventoryHandler(d.inventoryRepo, d.inventoryService, d.clock)
+	router.GET("/api/inventory", h.ListInventories)
+	router.POST("/api/inventory", h.AddInventory)
+	router.GET("/api/inventory/:medicationId", h.GetInventory)
+	router.PUT("/api/inventory/:medicationId", h.SaveInventory)
+	router.DELETE("/api/inventory/:medicationId", h.DeleteInventory)
+}
+
+func getTestInventory(t *testing.T, router *gin.Engine, medicationID string) dto.InventoryResponse {
+	t.Helper()
+	return requestJSON[dto.InventoryResponse](t, router, http.MethodGet, "/api/inventory/"+medicationID, "")
+}
+
+func addTestInventory(t *testing.T, router *gin.Engine, body string) dto.InventoryResponse {
+	t.Helper()
+	return requestJSON[dto.InventoryResponse](t, router, http.MethodPost, "/api/inventory", body)
+}
+
+func TestInventory(t *testing.T) {
+	// 21日服用・7日休薬の周期の15日目（2025-09-15 8:00 日本時間）
+	clk := clock.NewFixed(time.Date(2025, 9, 15, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), inventoryRoutes, medicationRoutes, regimenRoutes)
+
+	w := doRequest(router, http.MethodPut, "/api/regimen", `{"template":"fixed_21_7","cycleStartDate":"2025-09-01"}`, testUserID)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("在庫が登録されていない場合は空の一覧と404を返す", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/inventory", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+
+		w = doRequest(router, http.MethodGet, "/api/inventory/01K3WMQ9X3Z8Q4H6B3F2A1C0DE", "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRequest(router, http.MethodPut, "/api/inventory/01K3WMQ9X3Z8Q4H6B3F2A1C0DE", `{"name":"ヤーズフレックス","packSize":28}`, testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRequest(router, http.MethodGet, "/api/inventory/invalid", "", testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("不正なリクエストは400を返す", func(t *testing.T) {
+		for _, body := range []string{
+			`{"packSize":28,"packsOnHand":1}`,
+			`{"name":"ヤーズフレックス","packsOnHand":1}`,
+			`{"name":"ヤーズフレックス","packSize":0,"packsOnHand":1}`,
+			`{"name":"ヤーズフレックス","packSize":28,"packsOnHand":-1}`,
+			`{"name":"ヤーズフレックス","packSize":28,"pillsPerDose":11}`,
+			`{"name":"ヤーズフレックス","packSize":28,"refillReminderDays":61}`,
+		} {
+			w := doRequest(router, http.MethodPost, "/api/inventory", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	medication := addTestInventory(t, router, `{"name":"ヤーズフレックス","packSize":28,"packsOnHand":1,"loosePills":2}`)
+
+	t.Run("箱数と端数の錠数から手持ちの錠数を登録する", func(t *testing.T) {
+		res := getTestInventory(t, router, medication.MedicationID)
+		assert.Equal(t, medication.MedicationID, res.MedicationID)
+		assert.Equal(t, "ヤーズフレックス", res.Name)
+		assert.Equal(t, 30, res.RemainingPills)
+		assert.Equal(t, 1, res.PacksOnHand)
+		assert.Equal(t, 2, res.LoosePills)
+		assert.Equal(t, 1, res.PillsPerDose, "省略時は1回1錠")
+		assert.Equal(t, 7, res.RefillReminderDays, "省略時は7日前")
+		assert.False(t, res.RefillReminderSent)
+	})
+
+	t.Run("今後の休薬期間を考慮してなくなる日を予測する", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/inventory/"+medication.MedicationID,
+			`{"name":"ヤーズフレックス","packSize":28,"loosePills":10,"refillReminderDays":3}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		// 9/15〜9/21に7回、9/22〜9/28の休薬を挟んで9/29〜10/1に3回服用する
+		res := getTestInventory(t, router, medication.MedicationID)
+		assert.Equal(t, 10, res.DosesLeft)
+		assert.Equal(t, "2025-10-01", res.LastDoseDate)
+		assert.Equal(t, "2025-10-02", res.RunOutDate)
+		require.NotNil(t, res.DaysUntilRunOut)
+		assert.Equal(t, 17, *res.DaysUntilRunOut)
+		assert.Equal(t, "2025-09-29", res.RefillReminderDate)
+	})
+
+	supplement := addTestInventory(t, router, `{"name":"鉄剤","packSize":10,"packsOnHand":2,"pillsPerDose":2}`)
+
+	t.Run("服用記録の登録で全ての薬が減り、削除で戻る", func(t *testing.T) {
+		logID := registerTestLog(t, router, `{"hasBleeding":false}`)
+		res := getTestInventory(t, router, medication.MedicationID)
+		assert.Equal(t, 9, res.RemainingPills)
+		assert.Equal(t, "2025-10-02", res.RunOutDate, "当日分は服用済みのため予測は変わらない")
+		res = getTestInventory(t, router, supplement.MedicationID)
+		assert.Equal(t, 18, res.RemainingPills)
+		assert.Equal(t, 1, res.PacksOnHand, "箱数と端数も計算し直す")
+		assert.Equal(t, 8, res.LoosePills)
+
+		w := doRequest(router, http.MethodDelete, "/api/medication-log/"+logID, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 10, getTestInventory(t, router, medication.MedicationID).RemainingPills)
+		assert.Equal(t, 20, getTestInventory(t, router, supplement.MedicationID).RemainingPills)
+
+		w = doRequest(router, http.MethodPost, "/api/medication-log/"+logID+"/restore", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 9, getTestInventory(t, router, medication.MedicationID).RemainingPills)
+		assert.Equal(t, 18, getTestInventory(t, router, supplement.MedicationID).RemainingPills)
+	})
+
+	t.Run("登録順に全ての薬の在庫を返す", func(t *testing.T) {
+		res := requestJSON[[]dto.InventoryResponse](t, router, http.MethodGet, "/api/inventory", "")
+		require.Len(t, res, 2)
+		assert.Equal(t, medication.MedicationID, res[0].MedicationID)
+		assert.Equal(t, supplement.MedicationID, res[1].MedicationID)
+		assert.Equal(t, 9, res[1].DosesLeft, "1回に複数錠服用する場合は服用できる回数で予測する")
+	})
+
+	t.Run("1回に複数錠服用する場合は服用できる回数で予測する", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/inventory/"+supplement.MedicationID,
+			`{"name":"鉄剤","packSize":10,"loosePills":5,"pillsPerDose":2,"refillReminderDays":0}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		registerTestLog(t, router, `{"hasBleeding":false}`)
+		res := getTestInventory(t, router, supplement.MedicationID)
+		assert.Equal(t, 3, res.RemainingPills)
+		assert.Equal(t, 1, res.DosesLeft)
+		assert.Equal(t, "2025-09-16", res.LastDoseDate)
+		assert.Equal(t, "2025-09-17", res.RunOutDate)
+		assert.Empty(t, res.RefillReminderDate, "0日の場合はリマインダーを送らない")
+
+		registerTestLog(t, router, `{"hasBleeding":false}`)
+		registerTestLog(t, router, `{"hasBleeding":false}`)
+		res = getTestInventory(t, router, supplement.MedicationID)
+		assert.Equal(t, 0, res.RemainingPills, "0未満にはならない")
+		assert.Equal(t, "2025-09-16", res.RunOutDate)
+		require.NotNil(t, res.DaysUntilRunOut)
+		assert.Equal(t, 1, *res.DaysUntilRunOut)
+	})
+
+	t.Run("在庫が0のときに登録した服用記録を削除しても減らせなかった分は戻さない", func(t *testing.T) {
+		logID := registerTestLog(t, router, `{"hasBleeding":false}`)
+		assert.Equal(t, 0, getTestInventory(t, router, supplement.MedicationID).RemainingPills)
+
+		w := doRequest(router, http.MethodDelete, "/api/medication-log/"+logID, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 0, getTestInventory(t, router, supplement.MedicationID).RemainingPills)
+	})
+
+	t.Run("削除した薬は減らさない", func(t *testing.T) {
+		w := doRequest(router, http.MethodDelete, "/api/inventory/"+supplement.MedicationID, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = doRequest(router, http.MethodDelete, "/api/inventory/"+supplement.MedicationID, "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		before := getTestInventory(t, router, medication.MedicationID).RemainingPills
+		registerTestLog(t, router, `{"hasBleeding":false}`)
+		assert.Equal(t, before-1, getTestInventory(t, router, medication.MedicationID).RemainingPills)
+		w = doRequest(router, http.MethodGet, "/api/inventory/"+supplement.MedicationID, "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
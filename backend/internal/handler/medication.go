@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
 	"net/http"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
@@ -8,20 +11,39 @@ import (
 	"okusuri-backend/internal/service"
 	"okusuri-backend/pkg/errors"
 	"okusuri-backend/pkg/helper"
-	"strconv"
+	"okusuri-shared/clock"
+	"okusuri-shared/status"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// defaultLogListLimit はlimit省略時の服用記録一覧の取得件数
+	defaultLogListLimit = 100
+	// maxLogListLimit は服用記録一覧で指定できるlimitの上限
+	maxLogListLimit = 500
+)
+
 type MedicationHandler struct {
-	medicationRepo *repository.MedicationRepository
+	medicationRepo    repository.MedicationRepository
+	medicationService *service.MedicationService
+	inventoryService  *service.InventoryService
+	clock             clock.Clock
 }
 
-func NewMedicationHandler(medicationRepo *repository.MedicationRepository) *MedicationHandler {
+func NewMedicationHandler(
+	medicationRepo repository.MedicationRepository,
+	medicationService *service.MedicationService,
+	inventoryService *service.InventoryService,
+	clk clock.Clock,
+) *MedicationHandler {
 	return &MedicationHandler{
-		medicationRepo: medicationRepo,
+		medicationRepo:    medicationRepo,
+		medicationService: medicationService,
+		inventoryService:  inventoryService,
+		clock:             clk,
 	}
 }
 
@@ -40,37 +62,59 @@ func (h *MedicationHandler) RegisterLog(c *gin.Context) {
 		return
 	}
 
+	severity, err := resolveBleedingSeverity(req.HasBleeding, req.BleedingSeverity)
+	if err != nil {
+		errors.HandleValidationError(c, err.Error(), nil)
+		return
+	}
+
 	log.Info().
 		Str("user_id", userID).
-		Bool("has_bleeding", req.HasBleeding).
+		Str("bleeding_severity", string(severity)).
 		Msg("服用記録の登録を開始します")
 
+	// 服用日はユーザーのタイムゾーンで決定する
+	ctx := c.Request.Context()
+	loc, err := h.medicationService.GetLocation(ctx, userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "プロフィール取得", err)
+		return
+	}
+
+	now := h.clock.Now()
 	medicationLog := model.MedicationLog{
-		HasBleeding: req.HasBleeding,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		HasBleeding:      severity.IsBleeding(),
+		BleedingSeverity: string(severity),
+		CreatedAt:        now.In(loc),
+		UpdatedAt:        now.In(loc),
 	}
 
 	// 日付が指定されている場合は、その日付を使用
 	if req.Date != nil {
-		medicationLog.CreatedAt = *req.Date
+		medicationLog.CreatedAt = req.Date.In(loc)
 	}
 
 	// リポジトリを呼び出す
-	ctx := c.Request.Context()
-	err = h.medicationRepo.RegisterLogWithContext(ctx, userID, medicationLog)
+	registeredLog, err := h.medicationRepo.RegisterLogWithContext(ctx, userID, medicationLog)
 	if err != nil {
 		errors.HandleDatabaseError(c, "服用記録登録", err)
 		return
 	}
 
+	// 服用した分を在庫から減らす
+	h.updateInventory(ctx, userID, h.inventoryService.ConsumeDose)
+
 	log.Info().
 		Str("user_id", userID).
+		Str("log_id", registeredLog.ID).
 		Msg("服用記録の登録が完了しました")
 
-	c.JSON(200, dto.BaseResponse{
-		Success: true,
-		Message: "medication log registered successfully",
+	c.JSON(200, dto.MedicationLogResponse{
+		BaseResponse: dto.BaseResponse{
+			Success: true,
+			Message: "medication log registered successfully",
+		},
+		Log: registeredLog,
 	})
 }
 
@@ -83,14 +127,48 @@ func (h *MedicationHandler) GetLogs(c *gin.Context) {
 		return
 	}
 
+	// クエリパラメータをバインド
+	var query dto.MedicationLogListQuery
+	if bindErr := c.ShouldBindQuery(&query); bindErr != nil {
+		errors.HandleValidationError(c, "クエリパラメータが無効です", bindErr)
+		return
+	}
+	if !isValidDateParam(query.From) || !isValidDateParam(query.To) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		errors.HandleValidationError(c, "fromはto以前の日付を指定してください", nil)
+		return
+	}
+	if query.Limit < 0 || query.Limit > maxLogListLimit {
+		errors.HandleValidationError(c, fmt.Sprintf("limitは1から%dの範囲で指定してください", maxLogListLimit), nil)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultLogListLimit
+	}
+
 	log.Debug().
 		Str("user_id", userID).
+		Str("from", query.From).
+		Str("to", query.To).
+		Int("limit", query.Limit).
 		Msg("服用記録の取得を開始します")
 
 	// 服用記録を取得
 	ctx := c.Request.Context()
-	logs, err := h.medicationRepo.GetLogsByUserIDWithContext(ctx, userID)
+	logs, nextCursor, err := h.medicationRepo.ListLogs(ctx, userID, repository.LogQuery{
+		From:   query.From,
+		To:     query.To,
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
 	if err != nil {
+		if stderrors.Is(err, repository.ErrInvalidCursor) {
+			errors.HandleBadRequest(c, "カーソルが無効です", err)
+			return
+		}
 		errors.HandleDatabaseError(c, "服用記録取得", err)
 		return
 	}
@@ -98,9 +176,13 @@ func (h *MedicationHandler) GetLogs(c *gin.Context) {
 	log.Info().
 		Str("user_id", userID).
 		Int("count", len(logs)).
+		Bool("has_next", nextCursor != "").
 		Msg("服用記録の取得が完了しました")
 
-	c.JSON(200, logs)
+	c.JSON(200, dto.MedicationLogListResponse{
+		Logs:       logs,
+		NextCursor: nextCursor,
+	})
 }
 
 // GetLogByID は特定のIDの服薬ログを取得するハンドラー
@@ -113,17 +195,20 @@ func (h *MedicationHandler) GetLogByID(c *gin.Context) {
 	}
 
 	// URLからIDパラメータを取得
-	logIDStr := c.Param("id")
-	logID, err := strconv.ParseUint(logIDStr, 10, 32)
-	if err != nil {
+	logID := c.Param("id")
+	if !isValidLogID(logID) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log ID"})
 		return
 	}
 
 	// 服薬ログを取得
-	log, err := h.medicationRepo.GetLogByID(userID, uint(logID))
+	log, err := h.medicationRepo.GetLogByID(c.Request.Context(), userID, logID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "medication log not found"})
+		if stderrors.Is(err, repository.ErrLogNotFound) {
+			errors.HandleMedicationNotFound(c, "服用記録が見つかりません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "服用記録取得", err)
 		return
 	}
 
@@ -135,39 +220,170 @@ func (h *MedicationHandler) UpdateLog(c *gin.Context) {
 	// ユーザーIDを取得
 	userID, err := helper.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
 		return
 	}
 
 	// URLからIDパラメータを取得
-	logIDStr := c.Param("id")
-	logID, err := strconv.ParseUint(logIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log ID"})
+	logID := c.Param("id")
+	if !isValidLogID(logID) {
+		errors.HandleBadRequest(c, "無効な服用記録IDです", nil)
 		return
 	}
 
 	// リクエストボディを構造体にバインド
-	var req dto.MedicationLogRequest
+	var req dto.MedicationLogUpdateRequest
 	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
 		return
 	}
 
-	err = h.medicationRepo.UpdateLog(userID, uint(logID), req.HasBleeding)
+	ctx := c.Request.Context()
+	update := model.MedicationLogUpdate{}
+	if req.HasBleeding != nil || req.BleedingSeverity != nil {
+		var current status.BleedingSeverity
+		if req.BleedingSeverity == nil {
+			// 出血の有無だけを更新する場合に記録済みの程度を失わないよう、現在の記録を取得する
+			existing, getErr := h.medicationRepo.GetLogByID(ctx, userID, logID)
+			if getErr != nil {
+				if stderrors.Is(getErr, repository.ErrLogNotFound) {
+					errors.HandleMedicationNotFound(c, "服用記録が見つかりません", getErr)
+					return
+				}
+				errors.HandleDatabaseError(c, "服用記録取得", getErr)
+				return
+			}
+			current = status.BleedingSeverity(existing.BleedingSeverity)
+		}
+		severity, severityErr := resolveBleedingSeverityUpdate(req.HasBleeding, req.BleedingSeverity, current)
+		if severityErr != nil {
+			errors.HandleValidationError(c, severityErr.Error(), nil)
+			return
+		}
+		hasBleeding, severityValue := severity.IsBleeding(), string(severity)
+		update.HasBleeding = &hasBleeding
+		update.BleedingSeverity = &severityValue
+	}
+	if req.Date != nil {
+		// 移動先の服用日はユーザーのタイムゾーンで決定する
+		loc, locErr := h.medicationService.GetLocation(ctx, userID)
+		if locErr != nil {
+			errors.HandleDatabaseError(c, "プロフィール取得", locErr)
+			return
+		}
+		date := req.Date.In(loc)
+		update.Date = &date
+	}
+
+	updatedLog, err := h.medicationRepo.UpdateLog(ctx, userID, logID, update)
 	if err != nil {
-		if err.Error() == "log not found or user not authorized" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if stderrors.Is(err, repository.ErrLogNotFound) {
+			errors.HandleMedicationNotFound(c, "服用記録が見つかりません", err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update medication log"})
+		errors.HandleDatabaseError(c, "服用記録更新", err)
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("log_id", logID).
+		Msg("服用記録の更新が完了しました")
+
+	c.JSON(http.StatusOK, dto.MedicationLogResponse{
+		BaseResponse: dto.BaseResponse{
+			Success: true,
+			Message: "medication log updated successfully",
+		},
+		Log: updatedLog,
+	})
+}
+
+// DeleteLog は指定されたIDの服薬ログを論理削除するハンドラー
+func (h *MedicationHandler) DeleteLog(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
 		return
+	}
 
+	// URLからIDパラメータを取得
+	logID := c.Param("id")
+	if !isValidLogID(logID) {
+		errors.HandleBadRequest(c, "無効な服用記録IDです", nil)
+		return
 	}
 
+	ctx := c.Request.Context()
+	err = h.medicationRepo.DeleteLog(ctx, userID, logID)
+	if err != nil {
+		if stderrors.Is(err, repository.ErrLogNotFound) {
+			errors.HandleMedicationNotFound(c, "服用記録が見つかりません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "服用記録削除", err)
+		return
+	}
+
+	// 削除した服用記録の分を在庫に戻す
+	h.updateInventory(ctx, userID, h.inventoryService.ReturnDose)
+
+	log.Info().
+		Str("user_id", userID).
+		Str("log_id", logID).
+		Msg("服用記録を削除しました")
+
 	c.JSON(http.StatusOK, dto.BaseResponse{
 		Success: true,
-		Message: "medication log updated successfully",
+		Message: "medication log deleted successfully",
+	})
+}
+
+// RestoreLog は論理削除された服薬ログを復元するハンドラー
+func (h *MedicationHandler) RestoreLog(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// URLからIDパラメータを取得
+	logID := c.Param("id")
+	if !isValidLogID(logID) {
+		errors.HandleBadRequest(c, "無効な服用記録IDです", nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	restoredLog, err := h.medicationRepo.RestoreLog(ctx, userID, logID)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, repository.ErrLogNotFound):
+			errors.HandleMedicationNotFound(c, "復元可能な服用記録が見つかりません", err)
+		case stderrors.Is(err, repository.ErrLogNotDeleted):
+			errors.HandleConflict(c, "服用記録は削除されていません", err)
+		default:
+			errors.HandleDatabaseError(c, "服用記録復元", err)
+		}
+		return
+	}
+
+	// 復元した服用記録の分を在庫から減らす
+	h.updateInventory(ctx, userID, h.inventoryService.ConsumeDose)
+
+	log.Info().
+		Str("user_id", userID).
+		Str("log_id", logID).
+		Msg("服用記録を復元しました")
+
+	c.JSON(http.StatusOK, dto.MedicationLogResponse{
+		BaseResponse: dto.BaseResponse{
+			Success: true,
+			Message: "medication log restored successfully",
+		},
+		Log: restoredLog,
 	})
 }
 
@@ -181,8 +397,7 @@ func (h *MedicationHandler) GetMedicationStatus(c *gin.Context) {
 	}
 
 	// サービスから服薬ステータスを取得
-	medicationService := service.NewMedicationService(h.medicationRepo)
-	status, err := medicationService.GetMedicationStatus(userID)
+	status, err := h.medicationService.GetMedicationStatus(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication status"})
 		return
@@ -190,3 +405,117 @@ func (h *MedicationHandler) GetMedicationStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, status)
 }
+
+// GetMedicationStats は期間内の服薬の統計を取得するハンドラー
+func (h *MedicationHandler) GetMedicationStats(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// クエリパラメータをバインド
+	var query dto.MedicationStatsQuery
+	if bindErr := c.ShouldBindQuery(&query); bindErr != nil {
+		errors.HandleValidationError(c, "クエリパラメータが無効です", bindErr)
+		return
+	}
+	if !isValidDateParam(query.From) || !isValidDateParam(query.To) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+
+	stats, err := h.medicationService.GetMedicationStats(c.Request.Context(), userID, query.From, query.To)
+	if err != nil {
+		if stderrors.Is(err, service.ErrInvalidStatsRange) {
+			errors.HandleValidationError(c, "fromはto以前の日付を指定してください", nil)
+			return
+		}
+		errors.HandleDatabaseError(c, "服薬統計取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetCycles は服用記録の全期間を周期ごとに分割して取得するハンドラー
+func (h *MedicationHandler) GetCycles(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	cycles, err := h.medicationService.GetCycles(c.Request.Context(), userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "周期取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cycles)
+}
+
+// updateInventory は服用記録の登録・削除・復元に合わせて在庫を更新する
+// 在庫の更新に失敗しても服用記録の変更は取り消さない
+func (h *MedicationHandler) updateInventory(ctx context.Context, userID string, update func(context.Context, string) error) {
+	if err := update(ctx, userID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("user_id", userID).
+			Msg("在庫の更新に失敗しました")
+	}
+}
+
+// errInvalidBleedingSeverity は不明な出血の程度を指定した場合のエラー
+var errInvalidBleedingSeverity = stderrors.New("bleedingSeverityはnone・spotting・light・moderate・heavyのいずれかを指定してください")
+
+// resolveBleedingSeverity は登録リクエストの出血の程度を決定する
+// 程度を指定しない従来のリクエストは出血の有無から変換する
+func resolveBleedingSeverity(hasBleeding bool, value string) (status.BleedingSeverity, error) {
+	if value == "" {
+		return status.SeverityFromHasBleeding(hasBleeding), nil
+	}
+	severity, ok := status.ParseBleedingSeverity(value)
+	if !ok {
+		return "", errInvalidBleedingSeverity
+	}
+	if hasBleeding && !severity.IsBleeding() {
+		return "", fmt.Errorf("hasBleedingとbleedingSeverityが矛盾しています")
+	}
+	return severity, nil
+}
+
+// resolveBleedingSeverityUpdate は更新リクエストの出血の程度を決定する（どちらかがnilでないこと）
+// 出血の有無だけを指定した場合は、記録済みの程度（current）が出血の有無と一致していればそのまま維持する
+func resolveBleedingSeverityUpdate(hasBleeding *bool, value *string, current status.BleedingSeverity) (status.BleedingSeverity, error) {
+	if value == nil {
+		if current != "" && current.IsBleeding() == *hasBleeding {
+			return current, nil
+		}
+		return status.SeverityFromHasBleeding(*hasBleeding), nil
+	}
+	severity, ok := status.ParseBleedingSeverity(*value)
+	if !ok {
+		return "", errInvalidBleedingSeverity
+	}
+	if hasBleeding != nil && *hasBleeding != severity.IsBleeding() {
+		return "", fmt.Errorf("hasBleedingとbleedingSeverityが矛盾しています")
+	}
+	return severity, nil
+}
+
+// isValidDateParam は日付パラメータが空またはYYYY-MM-DD形式かどうかを判定する
+func isValidDateParam(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+// isValidLogID はULID、またはULID導入前に採番した服用記録のIDかどうかを判定する
+func isValidLogID(id string) bool {
+	return helper.IsValidID(id) || helper.IsLegacyID(id)
+}
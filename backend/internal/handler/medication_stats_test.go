@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestStats(t *testing.T, router *gin.Engine, query string) dto.MedicationStatsResponse {
+	t.Helper()
+
+	return requestJSON[dto.MedicationStatsResponse](t, router, http.MethodGet, "/api/medication-stats"+query, "")
+}
+
+func TestMedicationStats(t *testing.T) {
+	// 2025-09-01 8:00（日本時間）から毎朝服用し、9/21〜9/23の出血で休薬、9/28は飲み忘れる
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), medicationRoutes, profileRoutes)
+
+	takeDays(t, router, clk, 20, false)
+	takeDays(t, router, clk, 3, true)
+	clk.AdvanceDays(2) // 9/26
+	takeDays(t, router, clk, 2, false)
+	clk.AdvanceDays(1) // 9/29
+	takeDays(t, router, clk, 2, false)
+	// 10/1（当日）はまだ服用していない
+
+	t.Run("期間内の統計を返す", func(t *testing.T) {
+		stats := getTestStats(t, router, "?from=2025-09-01&to=2025-09-30")
+		assert.Equal(t, "2025-09-01", stats.From)
+		assert.Equal(t, "2025-09-30", stats.To)
+		assert.Equal(t, 22, stats.LongestStreak, "出血日も休薬に入るまでは連続服用に数える")
+		assert.Equal(t, 3, stats.BleedingDays)
+		assert.Equal(t, []dto.MonthlyBleedingDaysResponse{{Month: "2025-09", Days: 3}}, stats.MonthlyBleedingDays)
+		assert.Equal(t, 1, stats.RestPeriodCount)
+		assert.Equal(t, []dto.RestPeriodResponse{{StartDate: "2025-09-23", EndDate: "2025-09-25"}}, stats.RestPeriods)
+		assert.Zero(t, stats.AverageCycleLength, "休薬期間が1回では周期を計算できない")
+		// 休薬期間の3日を除く27日のうち、飲み忘れた9/28以外の26日に服用した
+		assert.Equal(t, 27, stats.ScheduledDays)
+		assert.Equal(t, 26, stats.TakenDays)
+		assert.Equal(t, 96.3, stats.AdherenceRate)
+	})
+
+	t.Run("期間を省略した場合は最初の服用日から当日まで", func(t *testing.T) {
+		stats := getTestStats(t, router, "")
+		assert.Equal(t, "2025-09-01", stats.From)
+		assert.Equal(t, "2025-10-01", stats.To)
+		assert.Equal(t, []dto.MonthlyBleedingDaysResponse{
+			{Month: "2025-09", Days: 3},
+			{Month: "2025-10", Days: 0},
+		}, stats.MonthlyBleedingDays)
+		assert.Equal(t, 27, stats.ScheduledDays, "まだ服用していない当日は数えない")
+	})
+
+	t.Run("日付の境界はユーザーのタイムゾーンで判定する", func(t *testing.T) {
+		// 日本時間の8:00はロサンゼルスでは前日の16:00
+		w := doRequest(router, http.MethodPut, "/api/profile", `{"timezone":"America/Los_Angeles"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		stats := getTestStats(t, router, "?from=2025-09-01&to=2025-09-30")
+		assert.Equal(t, []dto.RestPeriodResponse{{StartDate: "2025-09-22", EndDate: "2025-09-24"}}, stats.RestPeriods)
+	})
+
+	t.Run("不正な期間は400を返す", func(t *testing.T) {
+		for _, query := range []string{"?from=2025-09-30&to=2025-09-01", "?from=2025/09/01", "?to=tomorrow", "?from=2025-10-02"} {
+			w := doRequest(router, http.MethodGet, "/api/medication-stats"+query, "", testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func getTestStatus(t *testing.T, router *gin.Engine) dto.MedicationStatusResponse {
+	t.Helper()
+
+	return requestJSON[dto.MedicationStatusResponse](t, router, http.MethodGet, "/api/medication-status", "")
+}
+
+// takeDays は1日1回服用を記録して時計を翌日に進めることをdays日分繰り返す
+func takeDays(t *testing.T, router *gin.Engine, clk *clock.Fixed, days int, hasBleeding bool) {
+	t.Helper()
+
+	body := `{"hasBleeding":false}`
+	if hasBleeding {
+		body = `{"hasBleeding":true}`
+	}
+	for i := 0; i < days; i++ {
+		registerTestLog(t, router, body)
+		clk.AdvanceDays(1)
+	}
+}
+
+func TestMedicationStatusFlexibleOverWeeks(t *testing.T) {
+	// 2025-09-01 8:00（日本時間）から毎朝服用する
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), medicationRoutes, regimenRoutes)
+
+	takeDays(t, router, clk, 20, false)
+	status := getTestStatus(t, router)
+	assert.Equal(t, 20, status.CurrentStreak, "前日まで服用していれば連続が続く")
+	assert.Equal(t, dto.PhaseIntake, status.Phase)
+
+	// 9/21〜9/23に3日連続で出血すると、出血初日から4日間の休薬に入る
+	takeDays(t, router, clk, 2, true)
+	registerTestLog(t, router, `{"hasBleeding":true}`)
+	status = getTestStatus(t, router)
+	assert.True(t, status.IsRestPeriod)
+	assert.Equal(t, dto.PhaseRest, status.Phase)
+	assert.Equal(t, 3, status.ConsecutiveBleedingDays)
+	assert.Equal(t, 3, status.RestDaysLeft)
+
+	clk.AdvanceDays(1) // 9/24
+	assert.Equal(t, 2, getTestStatus(t, router).RestDaysLeft, "休薬は明日まで")
+
+	clk.AdvanceDays(1) // 9/25
+	assert.Equal(t, 1, getTestStatus(t, router).RestDaysLeft, "休薬は今日で終わる")
+
+	clk.AdvanceDays(1) // 9/26
+	status = getTestStatus(t, router)
+	assert.False(t, status.IsRestPeriod, "休薬期間が明けた")
+	assert.Equal(t, dto.PhaseIntake, status.Phase)
+
+	// 休薬明けは休薬終了後の服用だけを数える
+	takeDays(t, router, clk, 10, false)
+	status = getTestStatus(t, router)
+	assert.Equal(t, 10, status.CurrentStreak)
+	assert.Equal(t, 0, status.ConsecutiveBleedingDays)
+}
+
+func TestMedicationStatusFixedCycleOverWeeks(t *testing.T) {
+	start := time.Date(2025, 9, 1, 8, 0, 0, 0, jst)
+	clk := clock.NewFixed(start)
+	router := newTestRouter(newTestDeps(clk), medicationRoutes, regimenRoutes)
+
+	w := doRequest(router, http.MethodPut, "/api/regimen", `{"template":"fixed_21_7","cycleStartDate":"2025-09-01"}`, testUserID)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	takeDays(t, router, clk, 20, false)
+	registerTestLog(t, router, `{"hasBleeding":false}`) // 21日目（9/21）
+	status := getTestStatus(t, router)
+	assert.Equal(t, dto.PhaseIntake, status.Phase)
+	assert.Equal(t, 21, status.CurrentStreak)
+	assert.Equal(t, "2025-09-22", status.NextRestDate)
+
+	clk.AdvanceDays(1) // 9/22 休薬1日目
+	status = getTestStatus(t, router)
+	assert.Equal(t, dto.PhaseRest, status.Phase)
+	assert.Equal(t, 7, status.RestDaysLeft)
+	assert.Equal(t, "2025-10-20", status.NextRestDate)
+
+	clk.AdvanceDays(6) // 9/28 休薬最終日
+	status = getTestStatus(t, router)
+	assert.Equal(t, dto.PhaseRest, status.Phase)
+	assert.Equal(t, 1, status.RestDaysLeft)
+
+	clk.AdvanceDays(1) // 9/29 2周期目の服用開始
+	registerTestLog(t, router, `{"hasBleeding":false}`)
+	status = getTestStatus(t, router)
+	assert.Equal(t, dto.PhaseIntake, status.Phase)
+	assert.Equal(t, 1, status.CurrentStreak)
+	assert.Equal(t, "2025-10-20", status.NextRestDate)
+}
+
+func TestMedicationStatusBleedingThreshold(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), medicationRoutes, regimenRoutes)
+
+	w := doRequest(router, http.MethodPut, "/api/regimen",
+		`{"type":"flexible_extended","restPeriodDays":4,"bleedingTriggerDays":3,"bleedingThreshold":"none"}`, testUserID)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "出血なしはしきい値にできない")
+
+	w = doRequest(router, http.MethodPut, "/api/regimen",
+		`{"type":"flexible_extended","restPeriodDays":4,"bleedingTriggerDays":3,"bleedingThreshold":"light"}`, testUserID)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"bleedingThreshold":"light"`)
+
+	takeDays(t, router, clk, 10, false)
+
+	// しきい値未満の点状出血は3日続いても休薬に入らない
+	for i := 0; i < 3; i++ {
+		registerTestLog(t, router, `{"bleedingSeverity":"spotting"}`)
+		clk.AdvanceDays(1)
+	}
+	clk.AdvanceDays(-1)
+	status := getTestStatus(t, router)
+	assert.False(t, status.IsRestPeriod)
+	assert.Equal(t, 0, status.ConsecutiveBleedingDays)
+	assert.Equal(t, 13, status.CurrentStreak)
+
+	// 少量以上の出血が3日続くと休薬に入る（従来の出血ありは中等量として数える）
+	clk.AdvanceDays(1)
+	registerTestLog(t, router, `{"bleedingSeverity":"light"}`)
+	clk.AdvanceDays(1)
+	registerTestLog(t, router, `{"bleedingSeverity":"heavy"}`)
+	clk.AdvanceDays(1)
+	registerTestLog(t, router, `{"hasBleeding":true}`)
+	status = getTestStatus(t, router)
+	assert.True(t, status.IsRestPeriod)
+	assert.Equal(t, 3, status.ConsecutiveBleedingDays)
+}
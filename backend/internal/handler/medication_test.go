@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/clock"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// medicationRoutes は服用記録・ステータス・統計・周期のルートを登録する
+func medicationRoutes(router *gin.Engine, d *testDeps) {
+	h := NewMedicationHandler(d.medicationRepo, d.medicationService, d.inventoryService, d.clock)
+	router.POST("/api/medication-log", h.RegisterLog)
+	router.GET("/api/medication-log", h.GetLogs)
+	router.GET("/api/medication-log/:id", h.GetLogByID)
+	router.PATCH("/api/medication-log/:id", h.UpdateLog)
+	router.DELETE("/api/medication-log/:id", h.DeleteLog)
+	router.POST("/api/medication-log/:id/restore", h.RestoreLog)
+	router.GET("/api/medication-status", h.GetMedicationStatus)
+	router.GET("/api/medication-stats", h.GetMedicationStats)
+	router.GET("/api/cycles", h.GetCycles)
+}
+
+func registerTestLog(t *testing.T, router *gin.Engine, body string) string {
+	t.Helper()
+
+	res := requestJSON[dto.MedicationLogResponse](t, router, http.MethodPost, "/api/medication-log", body)
+	require.NotNil(t, res.Log)
+	return res.Log.ID
+}
+
+func TestMedicationLogLifecycle(t *testing.T) {
+	router := newTestRouter(newTestDeps(clock.System()), medicationRoutes)
+
+	logID := registerTestLog(t, router, `{"hasBleeding":false,"date":"2025-08-30T09:00:00+09:00"}`)
+
+	t.Run("登録した記録をIDで取得できる", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/medication-log/"+logID, "", testUserID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"`+logID+`"`)
+		assert.Contains(t, w.Body.String(), `"date":"2025-08-30"`)
+	})
+
+	t.Run("他のユーザーの記録は取得できない", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/medication-log/"+logID, "", "other-user-0001")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("出血状態と日付を更新できる", func(t *testing.T) {
+		body := `{"hasBleeding":true,"date":"2025-08-31T09:00:00+09:00"}`
+		w := doRequest(router, http.MethodPatch, "/api/medication-log/"+logID, body, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res dto.MedicationLogResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, logID, res.Log.ID)
+		assert.True(t, res.Log.HasBleeding)
+		assert.Equal(t, "2025-08-31", res.Log.Date)
+	})
+
+	t.Run("削除した記録は一覧から除外され、復元すると戻る", func(t *testing.T) {
+		w := doRequest(router, http.MethodDelete, "/api/medication-log/"+logID, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doRequest(router, http.MethodGet, "/api/medication-log", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"logs":[]}`, w.Body.String())
+
+		w = doRequest(router, http.MethodGet, "/api/medication-log/"+logID, "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, http.MethodPost, "/api/medication-log/"+logID+"/restore", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doRequest(router, http.MethodGet, "/api/medication-log/"+logID, "", testUserID)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("削除されていない記録の復元は競合になる", func(t *testing.T) {
+		w := doRequest(router, http.MethodPost, "/api/medication-log/"+logID+"/restore", "", testUserID)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("不正なIDは400になる", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/medication-log/12345", "", testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ULID導入前の形式のIDは受け付ける", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/medication-log/1756515600000000000", "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestMedicationLogBleedingSeverity(t *testing.T) {
+	router := newTestRouter(newTestDeps(clock.System()), medicationRoutes)
+
+	register := func(t *testing.T, body string) model.MedicationLog {
+		t.Helper()
+		w := doRequest(router, http.MethodPost, "/api/medication-log", body, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res dto.MedicationLogResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return *res.Log
+	}
+
+	t.Run("出血の程度を記録できる", func(t *testing.T) {
+		log := register(t, `{"bleedingSeverity":"spotting","date":"2025-08-30T09:00:00+09:00"}`)
+		assert.Equal(t, model.BleedingSpotting, log.BleedingSeverity)
+		assert.True(t, log.HasBleeding)
+	})
+
+	t.Run("程度を指定しない従来のリクエストは出血の有無から変換する", func(t *testing.T) {
+		log := register(t, `{"hasBleeding":true,"date":"2025-08-31T09:00:00+09:00"}`)
+		assert.Equal(t, model.BleedingModerate, log.BleedingSeverity)
+
+		log = register(t, `{"hasBleeding":false,"date":"2025-09-01T09:00:00+09:00"}`)
+		assert.Equal(t, model.BleedingNone, log.BleedingSeverity)
+		assert.False(t, log.HasBleeding)
+
+		t.Run("出血の有無だけを更新した場合も程度を更新する", func(t *testing.T) {
+			w := doRequest(router, http.MethodPatch, "/api/medication-log/"+log.ID, `{"hasBleeding":true}`, testUserID)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"bleedingSeverity":"moderate"`)
+		})
+
+		t.Run("程度を更新すると出血の有無も更新する", func(t *testing.T) {
+			w := doRequest(router, http.MethodPatch, "/api/medication-log/"+log.ID, `{"bleedingSeverity":"none"}`, testUserID)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"hasBleeding":false`)
+		})
+	})
+
+	t.Run("出血の有無だけを更新した場合は記録済みの程度と一致していれば維持する", func(t *testing.T) {
+		for _, severity := range []string{model.BleedingSpotting, model.BleedingHeavy} {
+			logID := register(t, `{"bleedingSeverity":"`+severity+`","date":"2025-09-03T09:00:00+09:00"}`).ID
+
+			w := doRequest(router, http.MethodPatch, "/api/medication-log/"+logID, `{"hasBleeding":true}`, testUserID)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"bleedingSeverity":"`+severity+`"`)
+
+			w = doRequest(router, http.MethodPatch, "/api/medication-log/"+logID, `{"hasBleeding":false}`, testUserID)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"bleedingSeverity":"none"`)
+		}
+	})
+
+	t.Run("不正な程度や出血の有無と矛盾する程度は400を返す", func(t *testing.T) {
+		w := doRequest(router, http.MethodPost, "/api/medication-log", `{"bleedingSeverity":"severe"}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doRequest(router, http.MethodPost, "/api/medication-log", `{"hasBleeding":true,"bleedingSeverity":"none"}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		logID := register(t, `{"hasBleeding":false,"date":"2025-09-02T09:00:00+09:00"}`).ID
+		w = doRequest(router, http.MethodPatch, "/api/medication-log/"+logID, `{"hasBleeding":false,"bleedingSeverity":"heavy"}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetLogsPagination(t *testing.T) {
+	router := newTestRouter(newTestDeps(clock.System()), medicationRoutes)
+
+	for _, date := range []string{"2025-08-01", "2025-08-02", "2025-08-03", "2025-09-01"} {
+		registerTestLog(t, router, `{"hasBleeding":false,"date":"`+date+`T09:00:00Z"}`)
+	}
+
+	w := doRequest(router, http.MethodGet, "/api/medication-log?from=2025-08-01&to=2025-08-31&limit=2", "", testUserID)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var first dto.MedicationLogListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	require.Len(t, first.Logs, 2)
+	assert.Equal(t, "2025-08-01", first.Logs[0].Date)
+	assert.Equal(t, "2025-08-02", first.Logs[1].Date)
+	require.NotEmpty(t, first.NextCursor)
+
+	w = doRequest(router, http.MethodGet,
+		"/api/medication-log?from=2025-08-01&to=2025-08-31&limit=2&cursor="+first.NextCursor, "", testUserID)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var second dto.MedicationLogListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	require.Len(t, second.Logs, 1)
+	assert.Equal(t, "2025-08-03", second.Logs[0].Date)
+	assert.Empty(t, second.NextCursor)
+
+	t.Run("不正なパラメータは400になる", func(t *testing.T) {
+		for _, query := range []string{
+			"from=2025/08/01",
+			"to=2025-08-32",
+			"from=2025-09-01&to=2025-08-01",
+			"limit=-1",
+			"limit=1000",
+			"limit=abc",
+			"cursor=invalid",
+		} {
+			w := doRequest(router, http.MethodGet, "/api/medication-log?"+query, "", testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -1,23 +1,44 @@
 package handler
 
 import (
+	stderrors "errors"
+	"fmt"
 	"net/http"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/errors"
 	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"okusuri-shared/reminder"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// defaultHistoryLimit はlimit省略時の送信履歴の取得件数
+	defaultHistoryLimit = 50
+	// maxHistoryLimit は送信履歴で指定できるlimitの上限
+	maxHistoryLimit = 200
+)
+
 type NotificationHandler struct {
-	notificationRepo *repository.NotificationRepository
+	notificationRepo    repository.NotificationRepository
+	notificationService *service.NotificationService
+	clock               clock.Clock
 }
 
-func NewNotificationHandler(notificationRepo *repository.NotificationRepository) *NotificationHandler {
+func NewNotificationHandler(
+	notificationRepo repository.NotificationRepository,
+	notificationService *service.NotificationService,
+	clk clock.Clock,
+) *NotificationHandler {
 	return &NotificationHandler{
-		notificationRepo: notificationRepo,
+		notificationRepo:    notificationRepo,
+		notificationService: notificationService,
+		clock:               clk,
 	}
 }
 
@@ -34,13 +55,17 @@ func (h *NotificationHandler) GetSetting(c *gin.Context) {
 	platform := c.DefaultQuery("platform", "web")
 
 	// 通知設定を取得
-	setting, err := h.notificationRepo.GetSetting(userID, platform)
+	setting, err := h.notificationRepo.GetSetting(c.Request.Context(), userID, platform)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "notification setting not found"})
+		if stderrors.Is(err, repository.ErrSettingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification setting not found"})
+			return
+		}
+		errors.HandleDatabaseError(c, "通知設定取得", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, setting)
+	c.JSON(http.StatusOK, toNotificationSettingResponse(*setting))
 }
 
 // RegisterSetting はユーザーの通知設定を登録/更新するハンドラー
@@ -60,17 +85,25 @@ func (h *NotificationHandler) RegisterSetting(c *gin.Context) {
 	}
 
 	// NotificationSetting構造体を作成
+	now := h.clock.Now()
 	setting := model.NotificationSetting{
-		Platform:     req.Platform,
-		IsEnabled:    req.IsEnabled,
-		Subscription: req.Subscription,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Platform:      req.Platform,
+		IsEnabled:     req.IsEnabled,
+		Subscription:  req.Subscription,
+		ReminderTimes: req.ReminderTimes,
+		Weekdays:      req.Weekdays,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 
-	// リポジトリを使って保存
-	err = h.notificationRepo.RegisterSetting(userID, setting)
+	// 通知設定とリマインダーのスケジュールを保存
+	err = h.notificationService.SaveSetting(c.Request.Context(), userID, setting, req.DeviceLabel)
 	if err != nil {
+		if stderrors.Is(err, reminder.ErrInvalidClock) || stderrors.Is(err, reminder.ErrInvalidWeekday) ||
+			stderrors.Is(err, service.ErrInvalidSubscription) {
+			errors.HandleValidationError(c, err.Error(), nil)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register notification setting"})
 		return
 	}
@@ -80,3 +113,156 @@ func (h *NotificationHandler) RegisterSetting(c *gin.Context) {
 		Message: "notification setting registered successfully",
 	})
 }
+
+// ListDevices はユーザーの通知先のデバイス一覧を取得するハンドラー
+func (h *NotificationHandler) ListDevices(c *gin.Context) {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	devices, err := h.notificationService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "通知先デバイス取得", err)
+		return
+	}
+
+	res := dto.NotificationDeviceListResponse{Devices: make([]dto.NotificationDeviceResponse, 0, len(devices))}
+	for _, device := range devices {
+		res.Devices = append(res.Devices, toNotificationDeviceResponse(device))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// DeleteDevice は通知先のデバイスを削除するハンドラー
+func (h *NotificationHandler) DeleteDevice(c *gin.Context) {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	deviceID := c.Param("id")
+	if !service.IsValidDeviceID(deviceID) {
+		errors.HandleBadRequest(c, "無効なデバイスIDです", nil)
+		return
+	}
+
+	err = h.notificationService.DeleteDevice(c.Request.Context(), userID, deviceID)
+	if err != nil {
+		if stderrors.Is(err, repository.ErrDeviceNotFound) {
+			errors.HandleNotFound(c, "通知先のデバイスが見つかりません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "通知先デバイス削除", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "notification device deleted successfully",
+	})
+}
+
+// GetHistory は通知の送信履歴を新しい順に取得するハンドラー
+func (h *NotificationHandler) GetHistory(c *gin.Context) {
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	var query dto.NotificationHistoryQuery
+	if bindErr := c.ShouldBindQuery(&query); bindErr != nil {
+		errors.HandleValidationError(c, "クエリパラメータが無効です", bindErr)
+		return
+	}
+	if !isValidDateParam(query.From) || !isValidDateParam(query.To) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		errors.HandleValidationError(c, "fromはto以前の日付を指定してください", nil)
+		return
+	}
+	if query.Limit < 0 || query.Limit > maxHistoryLimit {
+		errors.HandleValidationError(c, fmt.Sprintf("limitは1から%dの範囲で指定してください", maxHistoryLimit), nil)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultHistoryLimit
+	}
+
+	logs, nextCursor, err := h.notificationService.ListHistory(
+		c.Request.Context(), userID, query.From, query.To, query.Limit, query.Cursor)
+	if err != nil {
+		if stderrors.Is(err, repository.ErrInvalidCursor) {
+			errors.HandleBadRequest(c, "カーソルが無効です", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "送信履歴取得", err)
+		return
+	}
+
+	res := dto.NotificationHistoryResponse{
+		History:    make([]dto.NotificationLogResponse, 0, len(logs)),
+		NextCursor: nextCursor,
+	}
+	for _, notificationLog := range logs {
+		res.History = append(res.History, toNotificationLogResponse(notificationLog))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func toNotificationSettingResponse(setting model.NotificationSetting) dto.NotificationSettingResponse {
+	reminderTimes := setting.ReminderTimes
+	if len(reminderTimes) == 0 {
+		// リマインダー時刻の導入前に保存された通知設定はデフォルトの時刻で通知される
+		reminderTimes = []string{model.DefaultReminderTime}
+	}
+	weekdays := setting.Weekdays
+	if weekdays == nil {
+		weekdays = []int{}
+	}
+
+	return dto.NotificationSettingResponse{
+		Platform:       setting.Platform,
+		IsEnabled:      setting.IsEnabled,
+		Subscription:   setting.Subscription,
+		ReminderTimes:  reminderTimes,
+		Weekdays:       weekdays,
+		DisabledReason: setting.DisabledReason,
+		CreatedAt:      setting.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      setting.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toNotificationDeviceResponse(device model.NotificationDevice) dto.NotificationDeviceResponse {
+	return dto.NotificationDeviceResponse{
+		ID:             device.ID,
+		Platform:       device.Platform,
+		Label:          device.Label,
+		IsEnabled:      device.IsEnabled,
+		DisabledReason: device.DisabledReason,
+		LastSeenAt:     device.LastSeenAt.Format(time.RFC3339),
+		CreatedAt:      device.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toNotificationLogResponse(notificationLog model.NotificationLog) dto.NotificationLogResponse {
+	return dto.NotificationLogResponse{
+		ID:          notificationLog.ID,
+		Kind:        notificationLog.Kind,
+		Outcome:     notificationLog.Outcome,
+		Platform:    notificationLog.Platform,
+		DeviceID:    notificationLog.DeviceID,
+		DeviceLabel: notificationLog.DeviceLabel,
+		Message:     notificationLog.Message,
+		StatusCode:  notificationLog.StatusCode,
+		Attempts:    notificationLog.Attempts,
+		Reason:      notificationLog.Reason,
+		LatencyMs:   notificationLog.LatencyMs,
+		SentAt:      notificationLog.SentAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notificationRoutes は通知設定・通知先デバイス・送信履歴のルートを登録する
+func notificationRoutes(router *gin.Engine, d *testDeps) {
+	h := NewNotificationHandler(d.notificationRepo, d.notificationService, d.clock)
+	router.GET("/api/notification/setting", h.GetSetting)
+	router.POST("/api/notification/setting", h.RegisterSetting)
+	router.GET("/api/notification/devices", h.ListDevices)
+	router.DELETE("/api/notification/devices/:id", h.DeleteDevice)
+	router.GET("/api/notification/history", h.GetHistory)
+}
+
+func TestNotificationReminderSchedule(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	deps := newTestDeps(clk)
+	router := newTestRouter(deps, notificationRoutes, profileRoutes)
+	notificationRepo := deps.notificationRepo
+
+	listSchedules := func(t *testing.T) []model.ReminderSchedule {
+		t.Helper()
+		schedules, err := notificationRepo.ListSchedules(context.Background(), testUserID)
+		require.NoError(t, err)
+		return schedules
+	}
+
+	t.Run("リマインダー時刻と曜日を保存するとUTCのバケットでスケジュールが作成される", func(t *testing.T) {
+		body := `{"platform":"web","isEnabled":true,"reminderTimes":["21:30","08:00","08:00"],"weekdays":[5,1,3]}`
+		w := doRequest(router, http.MethodPost, "/api/notification/setting", body, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = doRequest(router, http.MethodGet, "/api/notification/setting?platform=web", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res dto.NotificationSettingResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, []string{"08:00", "21:30"}, res.ReminderTimes)
+		assert.Equal(t, []int{1, 3, 5}, res.Weekdays)
+
+		schedules := listSchedules(t)
+		require.Len(t, schedules, 2)
+		assert.Equal(t, model.ReminderSchedule{
+			Platform: "web", Time: "08:00", Weekdays: []int{1, 3, 5}, Timezone: "Asia/Tokyo", Bucket: "23:00",
+		}, schedules[0])
+		assert.Equal(t, "12:30", schedules[1].Bucket)
+	})
+
+	t.Run("タイムゾーンを変更するとスケジュールのバケットが作り直される", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/profile", `{"timezone":"America/New_York"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		schedules := listSchedules(t)
+		require.Len(t, schedules, 2)
+		// 9月のニューヨークは夏時間（UTC-4）
+		assert.Equal(t, "America/New_York", schedules[0].Timezone)
+		assert.Equal(t, "12:00", schedules[0].Bucket)
+		assert.Equal(t, "01:30", schedules[1].Bucket)
+	})
+
+	t.Run("時刻を省略するとデフォルトの時刻で毎日通知される", func(t *testing.T) {
+		w := doRequest(router, http.MethodPost, "/api/notification/setting", `{"platform":"web","isEnabled":true}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		schedules := listSchedules(t)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, model.DefaultReminderTime, schedules[0].Time)
+		assert.Empty(t, schedules[0].Weekdays)
+	})
+
+	t.Run("通知を無効にするとスケジュールが削除される", func(t *testing.T) {
+		w := doRequest(router, http.MethodPost, "/api/notification/setting", `{"platform":"web","isEnabled":false}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, listSchedules(t))
+	})
+
+	t.Run("不正な時刻や曜日は400になる", func(t *testing.T) {
+		for _, body := range []string{
+			`{"platform":"web","isEnabled":true,"reminderTimes":["08:03"]}`,
+			`{"platform":"web","isEnabled":true,"reminderTimes":["8:00"]}`,
+			`{"platform":"web","isEnabled":true,"weekdays":[7]}`,
+		} {
+			w := doRequest(router, http.MethodPost, "/api/notification/setting", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
+
+func TestNotificationDevices(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	router := newTestRouter(newTestDeps(clk), notificationRoutes)
+
+	register := func(t *testing.T, endpoint, label string) {
+		t.Helper()
+		subscription, err := json.Marshal(map[string]interface{}{
+			"endpoint": endpoint,
+			"keys":     map[string]string{"p256dh": "key", "auth": "auth"},
+		})
+		require.NoError(t, err)
+		body, err := json.Marshal(map[string]interface{}{
+			"platform":     "web",
+			"isEnabled":    true,
+			"subscription": string(subscription),
+			"deviceLabel":  label,
+		})
+		require.NoError(t, err)
+
+		w := doRequest(router, http.MethodPost, "/api/notification/setting", string(body), testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	listDevices := func(t *testing.T, userID string) []dto.NotificationDeviceResponse {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/notification/devices", "", userID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "subscription", "暗号鍵を含むサブスクリプションは返さない")
+
+		var res dto.NotificationDeviceListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res.Devices
+	}
+
+	register(t, "https://push.example.com/laptop", "会社のPC")
+	clk.Advance(time.Hour)
+	register(t, "https://push.example.com/phone", "iPhone")
+
+	t.Run("同じプラットフォームの購読がデバイスごとに登録される", func(t *testing.T) {
+		devices := listDevices(t, testUserID)
+		require.Len(t, devices, 2)
+		assert.Equal(t, "iPhone", devices[0].Label, "最後に購読を登録したデバイスが先頭になる")
+		assert.Equal(t, "会社のPC", devices[1].Label)
+		assert.Equal(t, "web", devices[1].Platform)
+		assert.True(t, devices[1].IsEnabled)
+		assert.Len(t, devices[0].ID, 32)
+		assert.NotEqual(t, devices[0].ID, devices[1].ID)
+	})
+
+	t.Run("同じエンドポイントの再登録は表示名と作成日時を保ったまま最終利用日時を更新する", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		register(t, "https://push.example.com/laptop", "")
+
+		devices := listDevices(t, testUserID)
+		require.Len(t, devices, 2)
+		assert.Equal(t, "会社のPC", devices[0].Label)
+		assert.Equal(t, "2025-09-01T02:00:00Z", devices[0].LastSeenAt)
+		assert.Equal(t, "2025-09-01T00:00:00Z", devices[0].CreatedAt)
+	})
+
+	t.Run("エンドポイントのないサブスクリプションは400になる", func(t *testing.T) {
+		body := `{"platform":"web","isEnabled":true,"subscription":"{}"}`
+		w := doRequest(router, http.MethodPost, "/api/notification/setting", body, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("デバイスを削除すると一覧から除外される", func(t *testing.T) {
+		devices := listDevices(t, testUserID)
+		require.Len(t, devices, 2)
+		path := "/api/notification/devices/" + devices[0].ID
+
+		w := doRequest(router, http.MethodDelete, path, "", "other-user-0001")
+		assert.Equal(t, http.StatusNotFound, w.Code, "他のユーザーのデバイスは削除できない")
+
+		w = doRequest(router, http.MethodDelete, path, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		remaining := listDevices(t, testUserID)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "iPhone", remaining[0].Label)
+
+		w = doRequest(router, http.MethodDelete, path, "", testUserID)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("不正なデバイスIDは400になる", func(t *testing.T) {
+		w := doRequest(router, http.MethodDelete, "/api/notification/devices/not-a-device", "", testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestNotificationHistory(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC))
+	deps := newTestDeps(clk)
+	router := newTestRouter(deps, notificationRoutes, profileRoutes)
+	notificationRepo := deps.notificationRepo
+
+	// 日本時間の 9/1 08:00・9/1 23:30・9/2 08:00 に送信した履歴（9/1 23:30 JSTはUTCでは9/1 14:30）
+	for i, sentAt := range []time.Time{
+		time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(2025, 9, 1, 14, 30, 0, 0, time.UTC),
+		time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC),
+	} {
+		notificationRepo.AddNotificationLog(testUserID, model.NotificationLog{
+			ID:       fmt.Sprintf("log%d", i),
+			Kind:     "reminder",
+			Outcome:  "sent",
+			Platform: "web",
+			Message:  "お薬の時間です。",
+			Attempts: 1,
+			SentAt:   sentAt,
+		})
+	}
+	notificationRepo.AddNotificationLog("other-user-0001", model.NotificationLog{
+		ID: "other", Outcome: "sent", SentAt: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	getHistory := func(t *testing.T, query string) dto.NotificationHistoryResponse {
+		t.Helper()
+		w := doRequest(router, http.MethodGet, "/api/notification/history?"+query, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res dto.NotificationHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res
+	}
+	ids := func(res dto.NotificationHistoryResponse) []string {
+		result := make([]string, 0, len(res.History))
+		for _, entry := range res.History {
+			result = append(result, entry.ID)
+		}
+		return result
+	}
+
+	t.Run("自分の送信履歴を新しい順に返す", func(t *testing.T) {
+		res := getHistory(t, "")
+		assert.Equal(t, []string{"log2", "log1", "log0"}, ids(res))
+		assert.Empty(t, res.NextCursor)
+		assert.Equal(t, "2025-09-01T23:00:00Z", res.History[0].SentAt)
+		assert.Equal(t, "sent", res.History[0].Outcome)
+	})
+
+	t.Run("日付はユーザーのタイムゾーンで絞り込む", func(t *testing.T) {
+		assert.Equal(t, []string{"log1", "log0"}, ids(getHistory(t, "from=2025-09-01&to=2025-09-01")))
+		assert.Equal(t, []string{"log2"}, ids(getHistory(t, "from=2025-09-02")))
+
+		w := doRequest(router, http.MethodPut, "/api/profile", `{"timezone":"UTC"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"log2", "log1"}, ids(getHistory(t, "from=2025-09-01&to=2025-09-01")))
+	})
+
+	t.Run("カーソルで続きを取得できる", func(t *testing.T) {
+		first := getHistory(t, "limit=2")
+		assert.Equal(t, []string{"log2", "log1"}, ids(first))
+		require.NotEmpty(t, first.NextCursor)
+
+		second := getHistory(t, "limit=2&cursor="+first.NextCursor)
+		assert.Equal(t, []string{"log0"}, ids(second))
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("不正なパラメータは400になる", func(t *testing.T) {
+		for _, query := range []string{"from=2025/09/01", "from=2025-09-02&to=2025-09-01", "limit=1000", "cursor=invalid"} {
+			w := doRequest(router, http.MethodGet, "/api/notification/history?"+query, "", testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/errors"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ProfileHandler struct {
+	profileRepo         repository.ProfileRepository
+	profileService      *service.ProfileService
+	notificationService *service.NotificationService
+	clock               clock.Clock
+}
+
+func NewProfileHandler(
+	profileRepo repository.ProfileRepository,
+	profileService *service.ProfileService,
+	notificationService *service.NotificationService,
+	clk clock.Clock,
+) *ProfileHandler {
+	return &ProfileHandler{
+		profileRepo:         profileRepo,
+		profileService:      profileService,
+		notificationService: notificationService,
+		clock:               clk,
+	}
+}
+
+// GetProfile はユーザーのプロフィールを取得するハンドラー（未登録の場合はデフォルトを返す）
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	profile, isDefault, err := h.profileService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "プロフィール取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toProfileResponse(profile, isDefault))
+}
+
+// SaveProfile はユーザーのプロフィールを登録/更新するハンドラー
+func (h *ProfileHandler) SaveProfile(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.ProfileRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+	if err := service.ValidateTimezone(req.Timezone); err != nil {
+		errors.HandleValidationError(c, err.Error(), nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := h.clock.Now()
+	profile := model.UserProfile{
+		Timezone:  req.Timezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// 既存のプロフィールがある場合は作成日時を引き継ぐ
+	existing, err := h.profileRepo.GetProfile(ctx, userID)
+	if err != nil && !stderrors.Is(err, repository.ErrProfileNotFound) {
+		errors.HandleDatabaseError(c, "プロフィール取得", err)
+		return
+	}
+	if existing != nil {
+		profile.CreatedAt = existing.CreatedAt
+	}
+
+	if err := h.profileRepo.SaveProfile(ctx, userID, profile); err != nil {
+		errors.HandleDatabaseError(c, "プロフィール保存", err)
+		return
+	}
+
+	// タイムゾーンが変わるとリマインダー時刻のUTCのバケットも変わるため、スケジュールを作り直す
+	if existing == nil || existing.Timezone != profile.Timezone {
+		if err := h.notificationService.RescheduleReminders(ctx, userID); err != nil {
+			errors.HandleDatabaseError(c, "リマインダーのスケジュール更新", err)
+			return
+		}
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("timezone", profile.Timezone).
+		Msg("プロフィールを保存しました")
+
+	c.JSON(http.StatusOK, toProfileResponse(profile, false))
+}
+
+func toProfileResponse(profile model.UserProfile, isDefault bool) dto.ProfileResponse {
+	res := dto.ProfileResponse{
+		Timezone:  profile.Timezone,
+		IsDefault: isDefault,
+	}
+	if !isDefault {
+		res.CreatedAt = profile.CreatedAt.Format(time.RFC3339)
+		res.UpdatedAt = profile.UpdatedAt.Format(time.RFC3339)
+	}
+	return res
+}
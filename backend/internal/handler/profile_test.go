@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-shared/clock"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// profileRoutes はプロフィールのルートを登録する
+func profileRoutes(router *gin.Engine, d *testDeps) {
+	h := NewProfileHandler(d.profileRepo, d.profileService, d.notificationService, d.clock)
+	router.GET("/api/profile", h.GetProfile)
+	router.PUT("/api/profile", h.SaveProfile)
+}
+
+func TestProfileTimezone(t *testing.T) {
+	router := newTestRouter(newTestDeps(clock.System()), profileRoutes, medicationRoutes)
+
+	t.Run("未登録の場合はデフォルトのタイムゾーンを返す", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/profile", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res dto.ProfileResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, "Asia/Tokyo", res.Timezone)
+		assert.True(t, res.IsDefault)
+	})
+
+	t.Run("服用日はユーザーのタイムゾーンで決まる", func(t *testing.T) {
+		// UTCでは8/30だが日本時間では8/31の朝8時半
+		registerTestLog(t, router, `{"hasBleeding":false,"date":"2025-08-30T23:30:00Z"}`)
+
+		w := doRequest(router, http.MethodGet, "/api/medication-log?from=2025-08-31&to=2025-08-31", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res dto.MedicationLogListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		require.Len(t, res.Logs, 1)
+		assert.Equal(t, "2025-08-31", res.Logs[0].Date)
+	})
+
+	t.Run("タイムゾーンを変更すると以降の記録に反映される", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/profile", `{"timezone":"America/Los_Angeles"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"isDefault":false`)
+
+		// UTCでは9/2だがロサンゼルスでは9/1の夜
+		logID := registerTestLog(t, router, `{"hasBleeding":false,"date":"2025-09-02T03:00:00Z"}`)
+		w = doRequest(router, http.MethodGet, "/api/medication-log/"+logID, "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"date":"2025-09-01"`)
+	})
+
+	t.Run("不正なタイムゾーンは400になる", func(t *testing.T) {
+		for _, body := range []string{`{"timezone":"Mars/Olympus"}`, `{"timezone":"Local"}`, `{}`} {
+			w := doRequest(router, http.MethodPut, "/api/profile", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/errors"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type RegimenHandler struct {
+	regimenRepo       repository.RegimenRepository
+	medicationService *service.MedicationService
+	clock             clock.Clock
+}
+
+func NewRegimenHandler(regimenRepo repository.RegimenRepository, medicationService *service.MedicationService, clk clock.Clock) *RegimenHandler {
+	return &RegimenHandler{
+		regimenRepo:       regimenRepo,
+		medicationService: medicationService,
+		clock:             clk,
+	}
+}
+
+// GetRegimen はユーザーのレジメンを取得するハンドラー（未設定の場合はデフォルトを返す）
+func (h *RegimenHandler) GetRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	regimen, isDefault, err := h.medicationService.GetRegimen(c.Request.Context(), userID)
+	if err != nil {
+		errors.HandleDatabaseError(c, "レジメン取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRegimenResponse(regimen, isDefault))
+}
+
+// SaveRegimen はユーザーのレジメンを登録/更新するハンドラー
+func (h *RegimenHandler) SaveRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.RegimenRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+
+	regimen := model.Regimen{
+		Type:                    req.Type,
+		RestPeriodDays:          req.RestPeriodDays,
+		BleedingTriggerDays:     req.BleedingTriggerDays,
+		MinIntakeDaysBeforeRest: req.MinIntakeDaysBeforeRest,
+		MaxContinuousDays:       req.MaxContinuousDays,
+		ActiveDays:              req.ActiveDays,
+	}
+	if req.Template != "" {
+		template, ok := findRegimenTemplate(req.Template)
+		if !ok {
+			errors.HandleValidationError(c, "不明なテンプレートです", nil)
+			return
+		}
+		regimen = template.Regimen
+	}
+	regimen.CycleStartDate = req.CycleStartDate
+	regimen.BleedingThreshold = req.BleedingThreshold
+	if regimen.Type == "" {
+		// 種類を指定しない従来のリクエストはフレキシブル投与として扱う
+		regimen.Type = model.RegimenTypeFlexibleExtended
+	}
+
+	if err := h.medicationService.ValidateRegimen(regimen); err != nil {
+		errors.HandleValidationError(c, err.Error(), nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := h.clock.Now()
+	regimen.CreatedAt = now
+	regimen.UpdatedAt = now
+
+	// 既存のレジメンがある場合は作成日時を引き継ぐ
+	existing, err := h.regimenRepo.GetRegimen(ctx, userID)
+	if err != nil && !stderrors.Is(err, repository.ErrRegimenNotFound) {
+		errors.HandleDatabaseError(c, "レジメン取得", err)
+		return
+	}
+	if existing != nil {
+		regimen.CreatedAt = existing.CreatedAt
+	}
+
+	if err := h.regimenRepo.SaveRegimen(ctx, userID, regimen); err != nil {
+		errors.HandleDatabaseError(c, "レジメン保存", err)
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Int("rest_period_days", regimen.RestPeriodDays).
+		Int("bleeding_trigger_days", regimen.BleedingTriggerDays).
+		Int("min_intake_days_before_rest", regimen.MinIntakeDaysBeforeRest).
+		Int("max_continuous_days", regimen.MaxContinuousDays).
+		Int("active_days", regimen.ActiveDays).
+		Str("bleeding_threshold", regimen.BleedingThreshold).
+		Msg("レジメンを保存しました")
+
+	c.JSON(http.StatusOK, toRegimenResponse(regimen, false))
+}
+
+// DeleteRegimen はユーザーのレジメンを削除してデフォルトに戻すハンドラー
+func (h *RegimenHandler) DeleteRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	if err := h.regimenRepo.DeleteRegimen(c.Request.Context(), userID); err != nil {
+		errors.HandleDatabaseError(c, "レジメン削除", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "regimen reset to default successfully",
+	})
+}
+
+// GetTemplates は組み込みのレジメンテンプレート一覧を返すハンドラー
+func (h *RegimenHandler) GetTemplates(c *gin.Context) {
+	templates := model.RegimenTemplates()
+	res := make([]dto.RegimenTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		res = append(res, dto.RegimenTemplateResponse{
+			Name:        template.Name,
+			Description: template.Description,
+			Regimen:     toRegimenResponse(template.Regimen, false),
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func findRegimenTemplate(name string) (model.RegimenTemplate, bool) {
+	for _, template := range model.RegimenTemplates() {
+		if template.Name == name {
+			return template, true
+		}
+	}
+	return model.RegimenTemplate{}, false
+}
+
+func toRegimenResponse(regimen model.Regimen, isDefault bool) dto.RegimenResponse {
+	res := dto.RegimenResponse{
+		Type:                    regimen.Type,
+		RestPeriodDays:          regimen.RestPeriodDays,
+		BleedingTriggerDays:     regimen.BleedingTriggerDays,
+		MinIntakeDaysBeforeRest: regimen.MinIntakeDaysBeforeRest,
+		MaxContinuousDays:       regimen.MaxContinuousDays,
+		ActiveDays:              regimen.ActiveDays,
+		CycleStartDate:          regimen.CycleStartDate,
+		BleedingThreshold:       regimen.BleedingThreshold,
+		IsDefault:               isDefault,
+	}
+	if !isDefault && !regimen.CreatedAt.IsZero() {
+		res.CreatedAt = regimen.CreatedAt.Format(time.RFC3339)
+		res.UpdatedAt = regimen.UpdatedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// regimenRoutes はレジメンのルートを登録する
+func regimenRoutes(router *gin.Engine, d *testDeps) {
+	h := NewRegimenHandler(d.regimenRepo, d.medicationService, d.clock)
+	router.GET("/api/regimen", h.GetRegimen)
+	router.GET("/api/regimen/templates", h.GetTemplates)
+	router.PUT("/api/regimen", h.SaveRegimen)
+	router.DELETE("/api/regimen", h.DeleteRegimen)
+}
+
+func getTestRegimen(t *testing.T, router *gin.Engine) dto.RegimenResponse {
+	t.Helper()
+	return requestJSON[dto.RegimenResponse](t, router, http.MethodGet, "/api/regimen", "")
+}
+
+func TestRegimenCRUD(t *testing.T) {
+	// 2025-09-01 8:00（日本時間）から毎朝服用する
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), regimenRoutes, medicationRoutes)
+
+	t.Run("未設定の場合はデフォルトのルールを返す", func(t *testing.T) {
+		res := getTestRegimen(t, router)
+		assert.True(t, res.IsDefault)
+		assert.Equal(t, model.RegimenTypeFlexibleExtended, res.Type)
+		assert.Equal(t, 4, res.RestPeriodDays)
+		assert.Equal(t, 3, res.BleedingTriggerDays)
+		assert.Empty(t, res.CreatedAt)
+	})
+
+	t.Run("不正なルールは400を返す", func(t *testing.T) {
+		for _, body := range []string{
+			`{"restPeriodDays":15,"bleedingTriggerDays":3}`,
+			`{"restPeriodDays":0,"bleedingTriggerDays":3}`,
+			`{"restPeriodDays":4,"bleedingTriggerDays":0}`,
+		} {
+			w := doRequest(router, http.MethodPut, "/api/regimen", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("登録したルールを取得できる", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/regimen", `{"restPeriodDays":2,"bleedingTriggerDays":2}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		res := getTestRegimen(t, router)
+		assert.False(t, res.IsDefault)
+		assert.Equal(t, 2, res.RestPeriodDays)
+		assert.Equal(t, 2, res.BleedingTriggerDays)
+		assert.NotEmpty(t, res.CreatedAt)
+	})
+
+	t.Run("登録したルールで休薬を判定する", func(t *testing.T) {
+		// 9/1〜9/10に服用し、9/11・9/12に2日連続で出血すると、2日間の休薬に入る
+		takeDays(t, router, clk, 10, false)
+		takeDays(t, router, clk, 1, true)
+		registerTestLog(t, router, `{"hasBleeding":true}`)
+
+		status := getTestStatus(t, router)
+		assert.True(t, status.IsRestPeriod)
+		assert.Equal(t, 2, status.ConsecutiveBleedingDays)
+		assert.Equal(t, 2, status.RestDaysLeft, "休薬は明日まで")
+	})
+
+	t.Run("削除するとデフォルトのルールに戻る", func(t *testing.T) {
+		w := doRequest(router, http.MethodDelete, "/api/regimen", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, getTestRegimen(t, router).IsDefault)
+
+		// デフォルトでは3日連続の出血が必要なため、2日連続の出血では休薬に入らない
+		assert.False(t, getTestStatus(t, router).IsRestPeriod)
+	})
+}
+
+func TestRegimenTemplatesAndTypes(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), regimenRoutes, medicationRoutes)
+
+	t.Run("組み込みテンプレートの一覧を返す", func(t *testing.T) {
+		w := doRequest(router, http.MethodGet, "/api/regimen/templates", "", testUserID)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res []dto.RegimenTemplateResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		names := make([]string, 0, len(res))
+		for _, template := range res {
+			names = append(names, template.Name)
+			assert.NotEmpty(t, template.Description)
+		}
+		assert.Equal(t, []string{"flexible_extended", "flexible_extended_120", "fixed_21_7", "fixed_24_4", "continuous"}, names)
+	})
+
+	t.Run("不明なテンプレートや種類は400を返す", func(t *testing.T) {
+		for _, body := range []string{
+			`{"template":"unknown"}`,
+			`{"type":"unknown","restPeriodDays":4,"bleedingTriggerDays":3}`,
+			`{"type":"fixed_cycle","restPeriodDays":7}`,
+			`{"template":"fixed_21_7","cycleStartDate":"2025/09/01"}`,
+		} {
+			w := doRequest(router, http.MethodPut, "/api/regimen", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("テンプレートの設定値で登録する", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/regimen", `{"template":"flexible_extended_120","restPeriodDays":1}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		res := getTestRegimen(t, router)
+		assert.Equal(t, model.RegimenTypeFlexibleExtended, res.Type)
+		assert.Equal(t, 120, res.MaxContinuousDays)
+		assert.Equal(t, 4, res.RestPeriodDays, "テンプレート指定時は個別の項目を無視する")
+	})
+
+	t.Run("周期投与は周期の起点日から休薬日を決める", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/regimen", `{"template":"fixed_21_7","cycleStartDate":"2025-08-15"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		// 8/15から21日間服用し、9/5〜9/11に休薬する
+		status := getTestStatus(t, router)
+		assert.Equal(t, model.RegimenTypeFixedCycle, status.RegimenType)
+		assert.Equal(t, dto.PhaseIntake, status.Phase)
+		assert.Equal(t, "2025-09-05", status.NextRestDate)
+	})
+
+	t.Run("連続投与は出血が続いても休薬に入らない", func(t *testing.T) {
+		w := doRequest(router, http.MethodPut, "/api/regimen", `{"template":"continuous"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		takeDays(t, router, clk, 4, true)
+		registerTestLog(t, router, `{"hasBleeding":true}`)
+
+		status := getTestStatus(t, router)
+		assert.Equal(t, model.RegimenTypeContinuous, status.RegimenType)
+		assert.False(t, status.IsRestPeriod)
+		assert.Equal(t, dto.PhaseIntake, status.Phase)
+		assert.Equal(t, 5, status.ConsecutiveBleedingDays)
+		assert.Empty(t, status.NextRestDate)
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-shared/clock"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserID = "test-user-0001"
+
+// testDeps はハンドラーのテストで共有するインメモリのリポジトリとサービス
+// 各ハンドラーの_test.goのルート登録関数は、必要なものだけをここから取り出して使う
+type testDeps struct {
+	clock clock.Clock
+
+	medicationRepo   *repository.MemoryMedicationRepository
+	regimenRepo      *repository.MemoryRegimenRepository
+	profileRepo      *repository.MemoryProfileRepository
+	notificationRepo *repository.MemoryNotificationRepository
+	symptomRepo      *repository.MemorySymptomRepository
+	inventoryRepo    *repository.MemoryInventoryRepository
+
+	profileService      *service.ProfileService
+	medicationService   *service.MedicationService
+	notificationService *service.NotificationService
+	symptomService      *service.SymptomService
+	inventoryService    *service.InventoryService
+}
+
+// newTestDeps はinternal.SetupRoutesと同じ組み合わせでインメモリの依存関係を生成する
+func newTestDeps(clk clock.Clock) *testDeps {
+	d := &testDeps{
+		clock:            clk,
+		medicationRepo:   repository.NewMemoryMedicationRepository(clk),
+		regimenRepo:      repository.NewMemoryRegimenRepository(),
+		profileRepo:      repository.NewMemoryProfileRepository(),
+		notificationRepo: repository.NewMemoryNotificationRepository(),
+		symptomRepo:      repository.NewMemorySymptomRepository(clk),
+		inventoryRepo:    repository.NewMemoryInventoryRepository(clk),
+	}
+	d.profileService = service.NewProfileService(d.profileRepo)
+	d.medicationService = service.NewMedicationService(d.medicationRepo, d.regimenRepo, d.profileService, clk)
+	d.notificationService = service.NewNotificationService(d.notificationRepo, d.profileService, clk)
+	d.symptomService = service.NewSymptomService(d.symptomRepo, d.medicationService, clk)
+	d.inventoryService = service.NewInventoryService(d.inventoryRepo, d.medicationService, clk)
+	return d
+}
+
+// testRoutes はハンドラーのルートをテスト用ルーターに登録する関数
+type testRoutes func(router *gin.Engine, d *testDeps)
+
+// newTestRouter は指定したハンドラーのルートだけを登録したテスト用ルーターを作成する
+func newTestRouter(d *testDeps, routes ...testRoutes) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// CognitoAuthミドルウェアの代わりにユーザーIDを設定
+		if userID := c.GetHeader("X-Cognito-User-Id"); userID != "" {
+			c.Set("cognitoUserID", userID)
+		}
+		c.Next()
+	})
+
+	for _, register := range routes {
+		register(router, d)
+	}
+	return router
+}
+
+func doRequest(router *gin.Engine, method, path, body, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set("X-Cognito-User-Id", userID)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// requestJSON はテストユーザーとしてリクエストし、200を確認してレスポンスをデコードする
+func requestJSON[T any](t *testing.T, router *gin.Engine, method, path, body string) T {
+	t.Helper()
+
+	w := doRequest(router, method, path, body, testUserID)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	return res
+}
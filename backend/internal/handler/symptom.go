@@ -0,0 +1,243 @@
+package handler
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/errors"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// invalidSymptomTypeMessage は不明な症状の種類を指定した場合のエラーメッセージ
+var invalidSymptomTypeMessage = fmt.Sprintf("typeは%sのいずれかを指定してください", strings.Join(model.SymptomTypes(), "・"))
+
+type SymptomHandler struct {
+	symptomRepo    repository.SymptomRepository
+	symptomService *service.SymptomService
+	clock          clock.Clock
+}
+
+func NewSymptomHandler(symptomRepo repository.SymptomRepository, symptomService *service.SymptomService, clk clock.Clock) *SymptomHandler {
+	return &SymptomHandler{
+		symptomRepo:    symptomRepo,
+		symptomService: symptomService,
+		clock:          clk,
+	}
+}
+
+// AddSymptom は症状を記録するハンドラー
+func (h *SymptomHandler) AddSymptom(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.SymptomRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+	if !model.IsValidSymptomType(req.Type) {
+		errors.HandleValidationError(c, invalidSymptomTypeMessage, nil)
+		return
+	}
+	if !isValidDateParam(req.Date) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	date := req.Date
+	if date == "" {
+		// 日付を省略した場合はユーザーのタイムゾーンの当日とする
+		if date, err = h.symptomService.Today(ctx, userID); err != nil {
+			errors.HandleDatabaseError(c, "プロフィール取得", err)
+			return
+		}
+	}
+
+	now := h.clock.Now()
+	symptom, err := h.symptomRepo.AddSymptom(ctx, userID, model.Symptom{
+		Date:      date,
+		Type:      req.Type,
+		Intensity: *req.Intensity,
+		Note:      req.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		errors.HandleDatabaseError(c, "症状登録", err)
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("symptom_id", symptom.ID).
+		Str("type", symptom.Type).
+		Msg("症状の登録が完了しました")
+
+	c.JSON(http.StatusOK, dto.SymptomResponse{
+		BaseResponse: dto.BaseResponse{
+			Success: true,
+			Message: "symptom registered successfully",
+		},
+		Symptom: symptom,
+	})
+}
+
+// GetSymptoms は日付範囲を指定して症状の一覧を取得するハンドラー
+func (h *SymptomHandler) GetSymptoms(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// クエリパラメータをバインド
+	var query dto.SymptomListQuery
+	if bindErr := c.ShouldBindQuery(&query); bindErr != nil {
+		errors.HandleValidationError(c, "クエリパラメータが無効です", bindErr)
+		return
+	}
+	if !isValidDateParam(query.From) || !isValidDateParam(query.To) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		errors.HandleValidationError(c, "fromはto以前の日付を指定してください", nil)
+		return
+	}
+	if query.Limit < 0 || query.Limit > maxLogListLimit {
+		errors.HandleValidationError(c, fmt.Sprintf("limitは1から%dの範囲で指定してください", maxLogListLimit), nil)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultLogListLimit
+	}
+
+	symptoms, nextCursor, err := h.symptomRepo.ListSymptoms(c.Request.Context(), userID, repository.SymptomQuery{
+		From:   query.From,
+		To:     query.To,
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
+	if err != nil {
+		if stderrors.Is(err, repository.ErrInvalidCursor) {
+			errors.HandleBadRequest(c, "カーソルが無効です", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "症状取得", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SymptomListResponse{
+		Symptoms:   symptoms,
+		NextCursor: nextCursor,
+	})
+}
+
+// UpdateSymptom は指定されたIDの症状を更新するハンドラー
+func (h *SymptomHandler) UpdateSymptom(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// URLからIDパラメータを取得
+	symptomID := c.Param("id")
+	if !helper.IsValidID(symptomID) {
+		errors.HandleBadRequest(c, "無効な症状IDです", nil)
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.SymptomUpdateRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		errors.HandleValidationError(c, "リクエストボディが無効です", bindErr)
+		return
+	}
+	if req.Type != nil && !model.IsValidSymptomType(*req.Type) {
+		errors.HandleValidationError(c, invalidSymptomTypeMessage, nil)
+		return
+	}
+	if req.Date != nil && (*req.Date == "" || !isValidDateParam(*req.Date)) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+
+	symptom, err := h.symptomRepo.UpdateSymptom(c.Request.Context(), userID, symptomID, model.SymptomUpdate{
+		Date:      req.Date,
+		Type:      req.Type,
+		Intensity: req.Intensity,
+		Note:      req.Note,
+	})
+	if err != nil {
+		if stderrors.Is(err, repository.ErrSymptomNotFound) {
+			errors.HandleNotFound(c, "症状が見つかりません", err)
+			return
+		}
+		errors.HandleDatabaseError(c, "症状更新", err)
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("symptom_id", symptomID).
+		Msg("症状の更新が完了しました")
+
+	c.JSON(http.StatusOK, dto.SymptomResponse{
+		BaseResponse: dto.BaseResponse{
+			Success: true,
+			Message: "symptom updated successfully",
+		},
+		Symptom: symptom,
+	})
+}
+
+// GetSymptomSummary は期間内の症状の頻度をフェーズごとに集計するハンドラー
+func (h *SymptomHandler) GetSymptomSummary(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		errors.HandleBadRequest(c, "無効なユーザーIDです", err)
+		return
+	}
+
+	// クエリパラメータをバインド
+	var query dto.SymptomSummaryQuery
+	if bindErr := c.ShouldBindQuery(&query); bindErr != nil {
+		errors.HandleValidationError(c, "クエリパラメータが無効です", bindErr)
+		return
+	}
+	if !isValidDateParam(query.From) || !isValidDateParam(query.To) {
+		errors.HandleValidationError(c, "日付はYYYY-MM-DD形式で指定してください", nil)
+		return
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		errors.HandleValidationError(c, "fromはto以前の日付を指定してください", nil)
+		return
+	}
+
+	summary, err := h.symptomService.GetSymptomSummary(c.Request.Context(), userID, query.From, query.To)
+	if err != nil {
+		errors.HandleDatabaseError(c, "症状集計", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// symptomRoutes は症状のルートを登録する
+func symptomRoutes(router *gin.Engine, d *testDeps) {
+	h := NewSymptomHandler(d.symptomRepo, d.symptomService, d.clock)
+	router.POST("/api/symptoms", h.AddSymptom)
+	router.GET("/api/symptoms", h.GetSymptoms)
+	router.GET("/api/symptoms/summary", h.GetSymptomSummary)
+	router.PATCH("/api/symptoms/:id", h.UpdateSymptom)
+}
+
+// addTestSymptom は症状を記録し、登録された症状を返す
+func addTestSymptom(t *testing.T, router *gin.Engine, body string) *model.Symptom {
+	t.Helper()
+	res := requestJSON[dto.SymptomResponse](t, router, http.MethodPost, "/api/symptoms", body)
+	require.NotNil(t, res.Symptom)
+	return res.Symptom
+}
+
+func TestSymptoms(t *testing.T) {
+	// 2025-09-10 23:30（日本時間）= 2025-09-10 14:30 UTC
+	clk := clock.NewFixed(time.Date(2025, 9, 10, 23, 30, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), symptomRoutes, medicationRoutes)
+
+	listSymptoms := func(t *testing.T, query string) dto.SymptomListResponse {
+		t.Helper()
+		return requestJSON[dto.SymptomListResponse](t, router, http.MethodGet, "/api/symptoms"+query, "")
+	}
+
+	t.Run("日付を省略した場合はユーザーのタイムゾーンの当日に記録する", func(t *testing.T) {
+		symptom := addTestSymptom(t, router, `{"type":"headache","intensity":0,"note":"朝だけ"}`)
+		assert.Equal(t, "2025-09-10", symptom.Date)
+		assert.Equal(t, model.SymptomTypeHeadache, symptom.Type)
+		assert.Equal(t, 0, symptom.Intensity)
+		assert.Equal(t, "朝だけ", symptom.Note)
+		assert.NotEmpty(t, symptom.ID)
+	})
+
+	t.Run("不正なリクエストは400を返す", func(t *testing.T) {
+		for _, body := range []string{
+			`{"type":"backache","intensity":3}`,
+			`{"type":"headache"}`,
+			`{"type":"headache","intensity":11}`,
+			`{"type":"headache","intensity":-1}`,
+			`{"type":"headache","intensity":3,"date":"2025/09/10"}`,
+		} {
+			w := doRequest(router, http.MethodPost, "/api/symptoms", body, testUserID)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	addTestSymptom(t, router, `{"date":"2025-09-12","type":"cramps","intensity":6}`)
+	nausea := addTestSymptom(t, router, `{"date":"2025-09-11","type":"nausea","intensity":2}`)
+
+	t.Run("日付範囲を指定して日付順に取得する", func(t *testing.T) {
+		res := listSymptoms(t, "?from=2025-09-11&to=2025-09-12")
+		require.Len(t, res.Symptoms, 2)
+		assert.Equal(t, "2025-09-11", res.Symptoms[0].Date)
+		assert.Equal(t, "2025-09-12", res.Symptoms[1].Date)
+		assert.Empty(t, res.NextCursor)
+
+		w := doRequest(router, http.MethodGet, "/api/symptoms?from=2025-09-12&to=2025-09-11", "", testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ページングで続きを取得する", func(t *testing.T) {
+		first := listSymptoms(t, "?limit=2")
+		require.Len(t, first.Symptoms, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		second := listSymptoms(t, "?limit=2&cursor="+first.NextCursor)
+		require.Len(t, second.Symptoms, 1)
+		assert.Equal(t, "2025-09-12", second.Symptoms[0].Date)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("症状を編集して別の日付へ移動する", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		w := doRequest(router, http.MethodPatch, "/api/symptoms/"+nausea.ID,
+			`{"date":"2025-09-13","intensity":7,"note":"夕方から"}`, testUserID)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res dto.SymptomResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, nausea.ID, res.Symptom.ID)
+		assert.Equal(t, "2025-09-13", res.Symptom.Date)
+		assert.Equal(t, model.SymptomTypeNausea, res.Symptom.Type, "省略した項目は変更しない")
+		assert.Equal(t, 7, res.Symptom.Intensity)
+		assert.Equal(t, "夕方から", res.Symptom.Note)
+		assert.True(t, res.Symptom.UpdatedAt.After(nausea.UpdatedAt))
+
+		assert.Empty(t, listSymptoms(t, "?from=2025-09-11&to=2025-09-11").Symptoms)
+	})
+
+	t.Run("不正な更新は400、存在しない症状は404を返す", func(t *testing.T) {
+		w := doRequest(router, http.MethodPatch, "/api/symptoms/"+nausea.ID, `{"type":"backache"}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doRequest(router, http.MethodPatch, "/api/symptoms/"+nausea.ID, `{"intensity":11}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doRequest(router, http.MethodPatch, "/api/symptoms/"+nausea.ID, `{"date":""}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doRequest(router, http.MethodPatch, "/api/symptoms/invalid", `{"intensity":1}`, testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = doRequest(router, http.MethodPatch, "/api/symptoms/"+nausea.ID, `{"intensity":1}`, "other-user")
+		assert.Equal(t, http.StatusNotFound, w.Code, "他のユーザーの症状は編集できない")
+	})
+}
+
+func TestSymptomSummary(t *testing.T) {
+	// 2025-09-01 8:00（日本時間）から毎朝服用し、9/21〜9/23の出血で9/23〜9/25に休薬する
+	clk := clock.NewFixed(time.Date(2025, 9, 1, 8, 0, 0, 0, jst))
+	router := newTestRouter(newTestDeps(clk), symptomRoutes, medicationRoutes)
+
+	getSummary := func(t *testing.T, query string) dto.SymptomSummaryResponse {
+		t.Helper()
+		return requestJSON[dto.SymptomSummaryResponse](t, router, http.MethodGet, "/api/symptoms/summary"+query, "")
+	}
+
+	t.Run("記録がない場合は当日だけを集計する", func(t *testing.T) {
+		summary := getSummary(t, "")
+		assert.Equal(t, "2025-09-01", summary.From)
+		assert.Equal(t, "2025-09-01", summary.To)
+		require.Len(t, summary.Phases, 2)
+		assert.Equal(t, dto.SymptomPhaseSummaryResponse{
+			Phase:    dto.PhaseIntake,
+			Days:     1,
+			Symptoms: []dto.SymptomFrequencyResponse{},
+		}, summary.Phases[0])
+		assert.Equal(t, dto.SymptomPhaseSummaryResponse{
+			Phase:    dto.PhaseRest,
+			Symptoms: []dto.SymptomFrequencyResponse{},
+		}, summary.Phases[1])
+	})
+
+	takeDays(t, router, clk, 20, false)
+	takeDays(t, router, clk, 3, true)
+	clk.AdvanceDays(2) // 9/26
+	takeDays(t, router, clk, 5, false)
+
+	for _, body := range []string{
+		`{"date":"2025-09-10","type":"headache","intensity":4}`,
+		`{"date":"2025-09-10","type":"cramps","intensity":2}`,
+		`{"date":"2025-09-24","type":"cramps","intensity":8}`,
+		`{"date":"2025-09-24","type":"headache","intensity":3}`,
+		`{"date":"2025-09-25","type":"cramps","intensity":5}`,
+		`{"date":"2025-09-25","type":"cramps","intensity":7}`,
+		`{"date":"2025-09-28","type":"nausea","intensity":5}`,
+		`{"date":"2025-10-05","type":"nausea","intensity":9}`,
+	} {
+		addTestSymptom(t, router, body)
+	}
+
+	summary := getSummary(t, "")
+	assert.Equal(t, "2025-09-10", summary.From, "最初に症状を記録した日から集計する")
+	assert.Equal(t, "2025-10-01", summary.To)
+	require.Len(t, summary.Phases, 2)
+	assert.Equal(t, dto.SymptomPhaseSummaryResponse{
+		Phase: dto.PhaseIntake,
+		Days:  19,
+		Symptoms: []dto.SymptomFrequencyResponse{
+			{Type: model.SymptomTypeHeadache, Count: 1, Days: 1, AverageIntensity: 4},
+			{Type: model.SymptomTypeCramps, Count: 1, Days: 1, AverageIntensity: 2},
+			{Type: model.SymptomTypeNausea, Count: 1, Days: 1, AverageIntensity: 5},
+		},
+	}, summary.Phases[0], "当日より後の症状は集計しない")
+	assert.Equal(t, dto.SymptomPhaseSummaryResponse{
+		Phase: dto.PhaseRest,
+		Days:  3,
+		Symptoms: []dto.SymptomFrequencyResponse{
+			{Type: model.SymptomTypeHeadache, Count: 1, Days: 1, AverageIntensity: 3},
+			{Type: model.SymptomTypeCramps, Count: 3, Days: 2, AverageIntensity: 6.7},
+		},
+	}, summary.Phases[1])
+
+	t.Run("期間を指定して集計する", func(t *testing.T) {
+		summary := getSummary(t, "?from=2025-09-20&to=2025-09-24")
+		require.Len(t, summary.Phases, 2)
+		assert.Equal(t, 3, summary.Phases[0].Days)
+		assert.Empty(t, summary.Phases[0].Symptoms)
+		assert.Equal(t, 2, summary.Phases[1].Days)
+		assert.Len(t, summary.Phases[1].Symptoms, 2)
+
+		w := doRequest(router, http.MethodGet, "/api/symptoms/summary?from=2025-09-24&to=2025-09-20", "", testUserID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
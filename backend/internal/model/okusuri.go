@@ -1,37 +1,300 @@
 package model
 
 import (
+	"okusuri-shared/status"
 	"time"
 )
 
 // MedicationLog は服用履歴の構造体（DynamoDB対応）
 type MedicationLog struct {
-	HasBleeding bool      `json:"hasBleeding"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID               string    `json:"id"`               // ULID（SKの末尾に埋め込まれる）
+	Date             string    `json:"date"`             // 服用日（YYYY-MM-DD形式）
+	HasBleeding      bool      `json:"hasBleeding"`      // 出血の有無（BleedingSeverityがnone以外の場合はtrue）
+	BleedingSeverity string    `json:"bleedingSeverity"` // 出血の程度（none / spotting / light / moderate / heavy）
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
+// MedicationLogUpdate は服用履歴の部分更新内容（nilの項目は変更しない）
+// 出血を更新する場合はHasBleedingとBleedingSeverityの両方を指定する
+type MedicationLogUpdate struct {
+	HasBleeding      *bool
+	BleedingSeverity *string
+	Date             *time.Time
+}
+
+// 出血の程度
+const (
+	BleedingNone     = string(status.BleedingNone)     // 出血なし
+	BleedingSpotting = string(status.BleedingSpotting) // 点状出血
+	BleedingLight    = string(status.BleedingLight)    // 少量
+	BleedingModerate = string(status.BleedingModerate) // 中等量
+	BleedingHeavy    = string(status.BleedingHeavy)    // 多量
+)
+
+// Symptom は日ごとに記録する症状の構造体（DynamoDB対応）
+type Symptom struct {
+	ID        string    `json:"id"`             // ULID（SKの末尾に埋め込まれる）
+	Date      string    `json:"date"`           // 症状があった日（YYYY-MM-DD形式、ユーザーのタイムゾーン）
+	Type      string    `json:"type"`           // 症状の種類
+	Intensity int       `json:"intensity"`      // 症状の強さ（0〜10）
+	Note      string    `json:"note,omitempty"` // 自由記述のメモ
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SymptomUpdate は症状の部分更新内容（nilの項目は変更しない）
+type SymptomUpdate struct {
+	Date      *string
+	Type      *string
+	Intensity *int
+	Note      *string
+}
+
+// 症状の種類
+const (
+	SymptomTypeHeadache   = "headache"    // 頭痛
+	SymptomTypeCramps     = "cramps"      // 腹痛（生理痛）
+	SymptomTypeMoodSwings = "mood_swings" // 気分の変動
+	SymptomTypeNausea     = "nausea"      // 吐き気
+	SymptomTypeOther      = "other"       // その他（内容はメモに記録する）
+)
+
+// SymptomTypes は記録できる症状の種類の一覧を返す
+func SymptomTypes() []string {
+	return []string{
+		SymptomTypeHeadache,
+		SymptomTypeCramps,
+		SymptomTypeMoodSwings,
+		SymptomTypeNausea,
+		SymptomTypeOther,
+	}
+}
+
+// IsValidSymptomType は記録できる症状の種類かどうかを判定する
+func IsValidSymptomType(symptomType string) bool {
+	for _, t := range SymptomTypes() {
+		if t == symptomType {
+			return true
+		}
+	}
+	return false
+}
+
+// 症状の強さの範囲
+const (
+	MinSymptomIntensity = 0
+	MaxSymptomIntensity = 10
+)
+
+// Inventory は薬ごとの在庫の構造体（DynamoDB対応）
+// 服用記録はレジメン単位のため、服用記録の登録で登録済みの全ての薬から1回分を減らし、服用記録の削除で1回分を戻す
+type Inventory struct {
+	MedicationID       string `json:"medicationId"`       // 薬のID（ULID）
+	Name               string `json:"name"`               // 薬の名前
+	PackSize           int    `json:"packSize"`           // 1箱（1シート）あたりの錠数
+	PacksOnHand        int    `json:"packsOnHand"`        // 手持ちの錠数を1箱ずつに分けた場合の箱数
+	LoosePills         int    `json:"loosePills"`         // 箱に満たない端数の錠数
+	PillsPerDose       int    `json:"pillsPerDose"`       // 1回に服用する錠数
+	RemainingPills     int    `json:"remainingPills"`     // 手持ちの錠数
+	ShortPills         int    `json:"shortPills"`         // 手持ちが足りずに減らせなかった錠数（服用記録の削除で戻すときに差し引く）
+	RefillReminderDays int    `json:"refillReminderDays"` // 手持ちがなくなる何日前に補充のリマインダーを送るか（0の場合は送らない）
+	// RefillReminderSent は通知Lambdaが補充のリマインダーを送ったかどうか（在庫を登録し直すと消える）
+	RefillReminderSent bool      `json:"refillReminderSent"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// AdjustPills は手持ちの錠数にdeltaを加え、箱数と端数を計算し直す
+// 手持ちが足りずに減らせなかった錠数はShortPillsに記録し、戻すときは先にその分を差し引く
+func (i *Inventory) AdjustPills(delta int) {
+	pills := i.RemainingPills - i.ShortPills + delta
+	i.ShortPills = max(-pills, 0)
+	i.SetRemainingPills(max(pills, 0))
+}
+
+// SetRemainingPills は手持ちの錠数を更新し、箱数と端数を計算し直す
+// 1箱の錠数が分からない場合は、全てを端数の錠数とする
+func (i *Inventory) SetRemainingPills(pills int) {
+	i.RemainingPills = pills
+	if i.PackSize <= 0 {
+		i.PacksOnHand = 0
+		i.LoosePills = pills
+		return
+	}
+	i.PacksOnHand = pills / i.PackSize
+	i.LoosePills = pills % i.PackSize
+}
+
+// DefaultRefillReminderDays は補充のリマインダーの日数を省略した場合の日数
+const DefaultRefillReminderDays = 7
+
 // NotificationSetting は通知設定の構造体（DynamoDB対応）
 type NotificationSetting struct {
-	Platform     string    `json:"platform"`
-	IsEnabled    bool      `json:"isEnabled"`
-	Subscription string    `json:"subscription"` // Web Push用のサブスクリプション
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	Platform      string   `json:"platform"`
+	IsEnabled     bool     `json:"isEnabled"`
+	Subscription  string   `json:"subscription"`  // デバイスの導入前に保存されたWeb Push用のサブスクリプション（新しい購読はNotificationDeviceに保存する）
+	ReminderTimes []string `json:"reminderTimes"` // リマインダー時刻（HH:MM形式、ユーザーのタイムゾーン）
+	Weekdays      []int    `json:"weekdays"`      // リマインダーを送る曜日（0=日曜日〜6=土曜日、空の場合は毎日）
+	// DisabledReason は通知Lambdaが通知を無効化した理由（サブスクリプションの失効など。設定を保存し直すと消える）
+	DisabledReason string    `json:"disabledReason,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// NotificationDevice は通知を受け取るデバイス（Web Pushのサブスクリプション）の構造体（DynamoDB対応）
+// 同じプラットフォームでも購読ごとに1件保存し、通知Lambdaは通知が有効なプラットフォームの全デバイスに送信する
+type NotificationDevice struct {
+	ID             string    `json:"id"` // サブスクリプションのエンドポイントのハッシュ
+	Platform       string    `json:"platform"`
+	Subscription   string    `json:"subscription"`
+	Label          string    `json:"label"` // デバイスの表示名（例: 「iPhone」「会社のPC」）
+	IsEnabled      bool      `json:"isEnabled"`
+	DisabledReason string    `json:"disabledReason,omitempty"` // 通知Lambdaが無効化した理由（サブスクリプションの失効など）
+	LastSeenAt     time.Time `json:"lastSeenAt"`               // デバイスから最後に購読を登録した日時
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// NotificationLog は通知Lambdaが記録した通知1件の送信履歴の構造体（DynamoDB対応）
+// 保持期間（通知LambdaのNOTIFICATION_LOG_RETENTION_DAYS）の経過後はTTLで削除される
+type NotificationLog struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`    // reminder / follow_up / refill
+	Outcome     string    `json:"outcome"` // sent / expired / failed / skipped
+	Platform    string    `json:"platform"`
+	DeviceID    string    `json:"deviceId,omitempty"` // 空の場合はデバイスの導入前に通知設定に保存されたサブスクリプション
+	DeviceLabel string    `json:"deviceLabel,omitempty"`
+	Message     string    `json:"message"`
+	StatusCode  int       `json:"statusCode,omitempty"` // Pushサービスの応答ステータス
+	Attempts    int       `json:"attempts"`
+	Reason      string    `json:"reason,omitempty"` // 送信できなかった理由
+	LatencyMs   int64     `json:"latencyMs"`
+	SentAt      time.Time `json:"sentAt"`
+}
+
+// DefaultReminderTime はリマインダー時刻が未指定の通知設定に適用する時刻
+const DefaultReminderTime = "09:00"
+
+// ReminderSchedule は通知Lambdaが送信対象を検索するためのリマインダーのスケジュール（DynamoDB対応）
+// 通知設定のリマインダー時刻ごとに1件作成し、UTCのバケットでGSI1に登録する
+type ReminderSchedule struct {
+	Platform string `json:"platform"`
+	Time     string `json:"time"`     // リマインダー時刻（HH:MM形式、ユーザーのタイムゾーン）
+	Weekdays []int  `json:"weekdays"` // リマインダーを送る曜日（空の場合は毎日）
+	Timezone string `json:"timezone"` // スケジュール作成時のユーザーのタイムゾーン
+	Bucket   string `json:"bucket"`   // リマインダー時刻が属するUTCのバケット（HH:MM形式）
+}
+
+// UserProfile はユーザーのプロフィールの構造体（DynamoDB対応）
+type UserProfile struct {
+	Timezone  string    `json:"timezone"` // IANAタイムゾーン名（例: Asia/Tokyo）。日付の境界の判定に使用する
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// レジメンの種類
+const (
+	RegimenTypeFlexibleExtended = status.RegimenTypeFlexibleExtended // 連続出血で休薬に入るフレキシブル長期投与
+	RegimenTypeFixedCycle       = status.RegimenTypeFixedCycle       // 決まった日数ごとに休薬する周期投与（21/7、24/4など）
+	RegimenTypeContinuous       = status.RegimenTypeContinuous       // 休薬を設けない連続投与
+)
+
+// Regimen はユーザーごとの服薬ルール（レジメン）の構造体（DynamoDB対応）
+type Regimen struct {
+	Type                    string `json:"type"`                     // レジメンの種類
+	RestPeriodDays          int    `json:"restPeriodDays"`           // 休薬期間の日数
+	BleedingTriggerDays     int    `json:"bleedingTriggerDays"`      // 休薬に入る連続出血日数（フレキシブル投与）
+	MinIntakeDaysBeforeRest int    `json:"minIntakeDaysBeforeRest"`  // 休薬を開始できる最低連続服用日数（0の場合は制限なし）
+	MaxContinuousDays       int    `json:"maxContinuousDays"`        // 強制的に休薬に入る連続服用日数（フレキシブル投与、0の場合は上限なし）
+	ActiveDays              int    `json:"activeDays"`               // 1周期あたりの服用日数（周期投与）
+	CycleStartDate          string `json:"cycleStartDate,omitempty"` // 周期の起点日（YYYY-MM-DD形式、周期投与で空の場合は最初の服用日）
+	// BleedingThreshold は休薬に入る連続出血として数える出血の最低の程度（空の場合は点状出血を含む全ての出血）
+	BleedingThreshold string    `json:"bleedingThreshold,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// DefaultRegimen はレジメン未設定のユーザーに適用する服薬ルールを返す
+// 連続3日間の出血で4日間の休薬に入る従来のルールと同じ
+func DefaultRegimen() Regimen {
+	defaults := status.DefaultRegimen()
+	return Regimen{
+		Type:                    defaults.Type,
+		RestPeriodDays:          defaults.RestPeriodDays,
+		BleedingTriggerDays:     defaults.BleedingTriggerDays,
+		MinIntakeDaysBeforeRest: defaults.MinIntakeDaysBeforeRest,
+	}
+}
+
+// Rules はステータス計算に使用する服薬ルールを返す
+func (r Regimen) Rules() status.Regimen {
+	return status.Regimen{
+		Type:                    r.Type,
+		RestPeriodDays:          r.RestPeriodDays,
+		BleedingTriggerDays:     r.BleedingTriggerDays,
+		MinIntakeDaysBeforeRest: r.MinIntakeDaysBeforeRest,
+		MaxContinuousDays:       r.MaxContinuousDays,
+		ActiveDays:              r.ActiveDays,
+		CycleStartDate:          r.CycleStartDate,
+		BleedingThreshold:       status.BleedingSeverity(r.BleedingThreshold),
+	}
+}
+
+// RegimenTemplate は組み込みのレジメンのテンプレート
+type RegimenTemplate struct {
+	Name        string
+	Description string
+	Regimen     Regimen
+}
+
+// RegimenTemplates は組み込みのレジメンのテンプレート一覧を返す
+func RegimenTemplates() []RegimenTemplate {
+	flexible120 := DefaultRegimen()
+	flexible120.MaxContinuousDays = 120
+
+	return []RegimenTemplate{
+		{
+			Name:        "flexible_extended",
+			Description: "連続3日間の出血で4日間休薬するフレキシブル長期投与",
+			Regimen:     DefaultRegimen(),
+		},
+		{
+			Name:        "flexible_extended_120",
+			Description: "連続3日間の出血、または120日間の連続服用で4日間休薬するフレキシブル長期投与",
+			Regimen:     flexible120,
+		},
+		{
+			Name:        "fixed_21_7",
+			Description: "21日間服用して7日間休薬する周期投与",
+			Regimen:     Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7},
+		},
+		{
+			Name:        "fixed_24_4",
+			Description: "24日間服用して4日間休薬する周期投与",
+			Regimen:     Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 24, RestPeriodDays: 4},
+		},
+		{
+			Name:        "continuous",
+			Description: "休薬を設けない連続投与",
+			Regimen:     Regimen{Type: RegimenTypeContinuous},
+		},
+	}
 }
 
 // OkusuriTable はDynamoDB単一テーブル設計のメイン構造体
 type OkusuriTable struct {
-	PK        string                 `dynamo:"PK"`               // Partition Key
-	SK        string                 `dynamo:"SK"`               // Sort Key
-	GSI1PK    string                 `dynamo:"GSI1PK,omitempty"` // GSI1 Partition Key
-	GSI1SK    string                 `dynamo:"GSI1SK,omitempty"` // GSI1 Sort Key
-	Type      string                 `dynamo:"Type,omitempty"`   // レコードタイプ
-	Date      string                 `dynamo:"Date,omitempty"`   // 日付（YYYY-MM-DD形式）
-	Data      map[string]interface{} `dynamo:"Data,omitempty"`   // データペイロード
-	CreatedAt string                 `dynamo:"CreatedAt"`        // 作成日時（ISO8601）
-	UpdatedAt string                 `dynamo:"UpdatedAt"`        // 更新日時（ISO8601）
-	TTL       int64                  `dynamo:"TTL,omitempty"`    // TTL（必要に応じて）
+	PK        string                 `dynamo:"PK"`                  // Partition Key
+	SK        string                 `dynamo:"SK"`                  // Sort Key
+	GSI1PK    string                 `dynamo:"GSI1PK,omitempty"`    // GSI1 Partition Key
+	GSI1SK    string                 `dynamo:"GSI1SK,omitempty"`    // GSI1 Sort Key
+	Type      string                 `dynamo:"Type,omitempty"`      // レコードタイプ
+	Date      string                 `dynamo:"Date,omitempty"`      // 日付（YYYY-MM-DD形式）
+	Data      map[string]interface{} `dynamo:"Data,omitempty"`      // データペイロード
+	CreatedAt string                 `dynamo:"CreatedAt"`           // 作成日時（ISO8601）
+	UpdatedAt string                 `dynamo:"UpdatedAt"`           // 更新日時（ISO8601）
+	DeletedAt string                 `dynamo:"DeletedAt,omitempty"` // 論理削除日時（ISO8601）
+	TTL       int64                  `dynamo:"TTL,omitempty"`       // TTL（必要に応じて）
 }
 
 // TableName はDynamoDBのテーブル名を返す
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/guregu/dynamo/v2"
+)
+
+// ErrInvalidCursor はページングカーソルが不正な場合のエラー
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorPayload はカーソルに埋め込むLastEvaluatedKeyの内容
+// PKはリクエストしたユーザーから復元するため、SKのみを保持する
+type cursorPayload struct {
+	SK string `json:"sk"`
+}
+
+// encodeCursor はLastEvaluatedKeyからクライアント向けの不透明なカーソルを生成する
+func encodeCursor(lek dynamo.PagingKey) string {
+	if lek == nil {
+		return ""
+	}
+	sk, ok := lek["SK"].(*types.AttributeValueMemberS)
+	if !ok {
+		return ""
+	}
+	return encodeCursorSK(sk.Value)
+}
+
+// decodeCursor はカーソルを検証し、指定ユーザーのパーティションのページングキーに変換する
+func decodeCursor(cursor string, pk string, skPrefix string) (dynamo.PagingKey, error) {
+	sk, err := decodeCursorSK(cursor, skPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamo.PagingKey{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: sk},
+	}, nil
+}
+
+// encodeCursorSK は最後に返したアイテムのSKからカーソルを生成する
+func encodeCursorSK(sk string) string {
+	payload, err := json.Marshal(cursorPayload{SK: sk})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeCursorSK はカーソルからSKを取り出し、接頭辞が一致するか検証する
+func decodeCursorSK(cursor string, skPrefix string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return "", ErrInvalidCursor
+	}
+	if !strings.HasPrefix(payload.SK, skPrefix) {
+		return "", ErrInvalidCursor
+	}
+	return payload.SK, nil
+}
@@ -0,0 +1,714 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/guregu/dynamo/v2"
+)
+
+// attributeValue はDynamoDBのAPIが送受信するJSON形式の属性値（{"S": "…"}など）
+type attributeValue map[string]interface{}
+
+// keyCondition はQueryのKeyConditionsで指定されるキー条件
+type keyCondition struct {
+	AttributeValueList []attributeValue
+	ComparisonOperator string
+}
+
+// expressionInput は式を使うリクエストに共通する項目
+type expressionInput struct {
+	ConditionExpression       string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]attributeValue
+}
+
+type putInput struct {
+	expressionInput
+	Item map[string]attributeValue
+}
+
+type updateInput struct {
+	expressionInput
+	Key              map[string]attributeValue
+	UpdateExpression string
+	ReturnValues     string
+}
+
+type deleteInput struct {
+	expressionInput
+	Key map[string]attributeValue
+}
+
+type queryInput struct {
+	expressionInput
+	IndexName         string
+	KeyConditions     map[string]keyCondition
+	FilterExpression  string
+	Limit             int
+	ExclusiveStartKey map[string]attributeValue
+}
+
+type transactWriteInput struct {
+	TransactItems []struct {
+		Put    *putInput
+		Update *updateInput
+		Delete *deleteInput
+	}
+}
+
+// errConditionFailed は条件式を満たさなかったことを表す
+var errConditionFailed = fmt.Errorf("the conditional request failed")
+
+// fakeDynamoDB はテスト用のインメモリのDynamoDB
+// GetItemと、PutItem・UpdateItem・TransactWriteItemsの条件式と、Query（テーブルとGSI1のキー条件・フィルター式・ページング）に応答する
+type fakeDynamoDB struct {
+	mu    sync.Mutex
+	items []map[string]attributeValue
+
+	// pageSize は1回のQueryで評価するアイテム数の上限（1MBの上限の代わり、0の場合は無制限）
+	pageSize int
+	// denyDescribeTable はIAMロールに権限がない場合と同じくDescribeTableにAccessDeniedExceptionを返す
+	denyDescribeTable bool
+	// afterQuery はQueryに応答した後に呼ばれる（取得と書き込みの間の競合を再現する）
+	afterQuery func(f *fakeDynamoDB)
+}
+
+func (f *fakeDynamoDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "GetItem":
+		var input struct {
+			Key map[string]attributeValue
+		}
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		output := map[string]interface{}{}
+		if item := f.find(input.Key); item != nil {
+			output["Item"] = item
+		}
+		writeFakeResponse(w, output)
+	case "PutItem":
+		var input putInput
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		if err := f.putItem(input); err != nil {
+			writeFakeError(w, "ConditionalCheckFailedException", err.Error())
+			return
+		}
+		writeFakeResponse(w, map[string]interface{}{})
+	case "UpdateItem":
+		var input updateInput
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		item, err := f.updateItem(input)
+		if err != nil {
+			writeFakeError(w, "ConditionalCheckFailedException", err.Error())
+			return
+		}
+		output := map[string]interface{}{}
+		if input.ReturnValues == "ALL_NEW" {
+			output["Attributes"] = item
+		}
+		writeFakeResponse(w, output)
+	case "TransactWriteItems":
+		var input transactWriteInput
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		f.transactWrite(w, input)
+	case "Query":
+		var input queryInput
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		writeFakeResponse(w, f.queryPage(input))
+		if f.afterQuery != nil {
+			f.afterQuery(f)
+		}
+	case "DescribeTable":
+		if f.denyDescribeTable {
+			writeFakeError(w, "AccessDeniedException", "not authorized to perform: "+r.Header.Get("X-Amz-Target"))
+			return
+		}
+		var input struct {
+			TableName string
+		}
+		if !decodeFakeRequest(w, r, &input) {
+			return
+		}
+		writeFakeResponse(w, map[string]interface{}{"Table": describeFakeTable(input.TableName)})
+	default:
+		writeFakeError(w, "UnknownOperationException", r.Header.Get("X-Amz-Target"))
+	}
+}
+
+func (f *fakeDynamoDB) putItem(input putInput) error {
+	if !f.check(f.find(input.Item), input.expressionInput) {
+		return errConditionFailed
+	}
+	f.put(input.Item)
+	return nil
+}
+
+func (f *fakeDynamoDB) updateItem(input updateInput) (map[string]attributeValue, error) {
+	existing := f.find(input.Key)
+	if !f.check(existing, input.expressionInput) {
+		return nil, errConditionFailed
+	}
+
+	item := copyItem(existing)
+	if item == nil {
+		item = copyItem(input.Key)
+	}
+	applyUpdate(item, input.UpdateExpression, input.expressionInput)
+	f.put(item)
+	return item, nil
+}
+
+func (f *fakeDynamoDB) deleteItem(input deleteInput) error {
+	if !f.check(f.find(input.Key), input.expressionInput) {
+		return errConditionFailed
+	}
+	f.remove(input.Key)
+	return nil
+}
+
+// transactWrite は全ての条件式を確認してから書き込み、1つでも満たさなければ何も書き込まない
+func (f *fakeDynamoDB) transactWrite(w http.ResponseWriter, input transactWriteInput) {
+	reasons := make([]map[string]string, len(input.TransactItems))
+	canceled := false
+	for i, op := range input.TransactItems {
+		var ok bool
+		switch {
+		case op.Put != nil:
+			ok = f.check(f.find(op.Put.Item), op.Put.expressionInput)
+		case op.Update != nil:
+			ok = f.check(f.find(op.Update.Key), op.Update.expressionInput)
+		case op.Delete != nil:
+			ok = f.check(f.find(op.Delete.Key), op.Delete.expressionInput)
+		}
+		reasons[i] = map[string]string{"Code": "None"}
+		if !ok {
+			reasons[i] = map[string]string{"Code": "ConditionalCheckFailed", "Message": errConditionFailed.Error()}
+			canceled = true
+		}
+	}
+	if canceled {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"__type":              "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+			"message":             "Transaction cancelled",
+			"CancellationReasons": reasons,
+		})
+		return
+	}
+
+	for _, op := range input.TransactItems {
+		switch {
+		case op.Put != nil:
+			_ = f.putItem(*op.Put)
+		case op.Update != nil:
+			_, _ = f.updateItem(*op.Update)
+		case op.Delete != nil:
+			_ = f.deleteItem(*op.Delete)
+		}
+	}
+	writeFakeResponse(w, map[string]interface{}{})
+}
+
+// find はキー（PKとSK）が一致するアイテムを返す
+func (f *fakeDynamoDB) find(key map[string]attributeValue) map[string]attributeValue {
+	for _, item := range f.items {
+		if stringAttr(item, "PK") == stringAttr(key, "PK") && stringAttr(item, "SK") == stringAttr(key, "SK") {
+			return item
+		}
+	}
+	return nil
+}
+
+// put はPKとSKが同じアイテムを置き換え、なければ追加する
+func (f *fakeDynamoDB) put(item map[string]attributeValue) {
+	f.remove(item)
+	f.items = append(f.items, item)
+}
+
+func (f *fakeDynamoDB) remove(key map[string]attributeValue) {
+	for i, item := range f.items {
+		if stringAttr(item, "PK") == stringAttr(key, "PK") && stringAttr(item, "SK") == stringAttr(key, "SK") {
+			f.items = append(f.items[:i], f.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// check はアイテムが条件式を満たすかどうかを判定する（条件式がなければ常に満たす）
+func (f *fakeDynamoDB) check(item map[string]attributeValue, input expressionInput) bool {
+	if input.ConditionExpression == "" {
+		return true
+	}
+	p := &conditionParser{
+		tokens: tokenizeExpression(input.ConditionExpression),
+		item:   item,
+		input:  input,
+	}
+	return p.parseOr()
+}
+
+// queryPage はキー条件に一致するアイテムをLimit件（pageSize件）まで評価し、フィルター式を満たすものを返す
+// DynamoDBと同じく、Limitに達した場合や評価を打ち切った場合は最後に評価したアイテムのキーをLastEvaluatedKeyとして返す
+func (f *fakeDynamoDB) queryPage(input queryInput) map[string]interface{} {
+	keys := []string{"PK", "SK"}
+	rangeKey := "SK"
+	if input.IndexName == "GSI1" {
+		keys = append(keys, "GSI1PK", "GSI1SK")
+		rangeKey = "GSI1SK"
+	}
+
+	matched := f.query(input.IndexName, input.KeyConditions)
+	if input.ExclusiveStartKey != nil {
+		start := stringAttr(input.ExclusiveStartKey, rangeKey)
+		for len(matched) > 0 && stringAttr(matched[0], rangeKey) <= start {
+			matched = matched[1:]
+		}
+	}
+
+	filter := input.expressionInput
+	filter.ConditionExpression = input.FilterExpression
+	items := []map[string]attributeValue{}
+	scanned := 0
+	var lastEvaluatedKey map[string]attributeValue
+	for i, item := range matched {
+		scanned++
+		if f.check(item, filter) {
+			items = append(items, item)
+		}
+		limitReached := input.Limit > 0 && scanned == input.Limit
+		pageFull := f.pageSize > 0 && scanned == f.pageSize && i < len(matched)-1
+		if limitReached || pageFull {
+			lastEvaluatedKey = map[string]attributeValue{}
+			for _, key := range keys {
+				lastEvaluatedKey[key] = item[key]
+			}
+			break
+		}
+	}
+
+	output := map[string]interface{}{
+		"Items":        items,
+		"Count":        len(items),
+		"ScannedCount": scanned,
+	}
+	if lastEvaluatedKey != nil {
+		output["LastEvaluatedKey"] = lastEvaluatedKey
+	}
+	return output
+}
+
+// describeFakeTable はPK/SKのテーブルとGSI1のキー定義を返す
+func describeFakeTable(name string) map[string]interface{} {
+	keySchema := func(hash, rng string) []map[string]string {
+		return []map[string]string{
+			{"AttributeName": hash, "KeyType": "HASH"},
+			{"AttributeName": rng, "KeyType": "RANGE"},
+		}
+	}
+	return map[string]interface{}{
+		"TableName":   name,
+		"TableStatus": "ACTIVE",
+		"KeySchema":   keySchema("PK", "SK"),
+		"AttributeDefinitions": []map[string]string{
+			{"AttributeName": "PK", "AttributeType": "S"},
+			{"AttributeName": "SK", "AttributeType": "S"},
+			{"AttributeName": "GSI1PK", "AttributeType": "S"},
+			{"AttributeName": "GSI1SK", "AttributeType": "S"},
+		},
+		"GlobalSecondaryIndexes": []map[string]interface{}{
+			{
+				"IndexName":   "GSI1",
+				"IndexArn":    "arn:aws:dynamodb:ap-northeast-1:000000000000:table/" + name + "/index/GSI1",
+				"IndexStatus": "ACTIVE",
+				"KeySchema":   keySchema("GSI1PK", "GSI1SK"),
+				"Projection":  map[string]string{"ProjectionType": "ALL"},
+			},
+		},
+	}
+}
+
+// query はキー条件に一致するアイテムをソートキー順に返す
+func (f *fakeDynamoDB) query(indexName string, conditions map[string]keyCondition) []map[string]attributeValue {
+	rangeKey := "SK"
+	if indexName == "GSI1" {
+		rangeKey = "GSI1SK"
+	}
+
+	var results []map[string]attributeValue
+	for _, item := range f.items {
+		if matchesKeyConditions(item, conditions) {
+			results = append(results, item)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return stringAttr(results[i], rangeKey) < stringAttr(results[j], rangeKey)
+	})
+	return results
+}
+
+// matchesKeyConditions はアイテムが全てのキー条件を満たすかどうかを判定する
+func matchesKeyConditions(item map[string]attributeValue, conditions map[string]keyCondition) bool {
+	for name, condition := range conditions {
+		value, ok := item[name]["S"].(string)
+		if !ok {
+			return false
+		}
+		operands := make([]string, 0, len(condition.AttributeValueList))
+		for _, operand := range condition.AttributeValueList {
+			s, _ := operand["S"].(string)
+			operands = append(operands, s)
+		}
+		switch condition.ComparisonOperator {
+		case "EQ":
+			if value != operands[0] {
+				return false
+			}
+		case "BEGINS_WITH":
+			if !strings.HasPrefix(value, operands[0]) {
+				return false
+			}
+		case "BETWEEN":
+			if value < operands[0] || value > operands[1] {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// applyUpdate はSET・REMOVE句からなる更新式をアイテムに適用する
+func applyUpdate(item map[string]attributeValue, expression string, input expressionInput) {
+	for _, clause := range splitUpdateClauses(expression) {
+		action, body, _ := strings.Cut(clause, " ")
+		for _, part := range strings.Split(body, ",") {
+			part = strings.TrimSpace(part)
+			switch action {
+			case "SET":
+				path, value, _ := strings.Cut(part, "=")
+				setPath(item, resolvePath(strings.TrimSpace(path), input), input.ExpressionAttributeValues[strings.TrimSpace(value)])
+			case "REMOVE":
+				removePath(item, resolvePath(part, input))
+			}
+		}
+	}
+}
+
+// splitUpdateClauses は更新式をSET・REMOVEなどの句に分割する
+func splitUpdateClauses(expression string) []string {
+	var clauses []string
+	var current []string
+	for _, word := range strings.Fields(expression) {
+		if (word == "SET" || word == "REMOVE") && len(current) > 0 {
+			clauses = append(clauses, strings.Join(current, " "))
+			current = nil
+		}
+		current = append(current, word)
+	}
+	if len(current) > 0 {
+		clauses = append(clauses, strings.Join(current, " "))
+	}
+	return clauses
+}
+
+// resolvePath は#nameのプレースホルダーを置き換えて属性のパスを返す
+func resolvePath(path string, input expressionInput) []string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if name, ok := input.ExpressionAttributeNames[part]; ok {
+			parts[i] = name
+		}
+	}
+	return parts
+}
+
+func getPath(item map[string]attributeValue, path []string) (attributeValue, bool) {
+	value, ok := item[path[0]]
+	for _, name := range path[1:] {
+		if !ok {
+			return nil, false
+		}
+		m, isMap := value["M"].(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		var child interface{}
+		child, ok = m[name]
+		value = toAttributeValue(child)
+	}
+	return value, ok
+}
+
+func setPath(item map[string]attributeValue, path []string, value attributeValue) {
+	if len(path) == 1 {
+		item[path[0]] = value
+		return
+	}
+	parent, ok := item[path[0]]["M"].(map[string]interface{})
+	if !ok {
+		parent = map[string]interface{}{}
+		item[path[0]] = attributeValue{"M": parent}
+	}
+	for _, name := range path[1 : len(path)-1] {
+		child, ok := toAttributeValue(parent[name])["M"].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			parent[name] = map[string]interface{}{"M": child}
+		}
+		parent = child
+	}
+	parent[path[len(path)-1]] = map[string]interface{}(value)
+}
+
+func removePath(item map[string]attributeValue, path []string) {
+	if len(path) == 1 {
+		delete(item, path[0])
+		return
+	}
+	parent, ok := getPath(item, path[:len(path)-1])
+	if !ok {
+		return
+	}
+	if m, isMap := parent["M"].(map[string]interface{}); isMap {
+		delete(m, path[len(path)-1])
+	}
+}
+
+func toAttributeValue(v interface{}) attributeValue {
+	switch v := v.(type) {
+	case attributeValue:
+		return v
+	case map[string]interface{}:
+		return v
+	}
+	return nil
+}
+
+// copyItem はアイテムを複製する（ネストしたマップはJSONを経由して複製する）
+func copyItem(item map[string]attributeValue) map[string]attributeValue {
+	if item == nil {
+		return nil
+	}
+	b, _ := json.Marshal(item)
+	var copied map[string]attributeValue
+	_ = json.Unmarshal(b, &copied)
+	return copied
+}
+
+// tokenizeExpression は条件式を括弧・カンマ・演算子・識別子のトークンに分割する
+func tokenizeExpression(expression string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch {
+		case c == ' ':
+			flush()
+		case c == '(' || c == ')' || c == ',':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == '<' || c == '>' || c == '=':
+			flush()
+			if i+1 < len(expression) && (expression[i+1] == '=' || expression[i+1] == '>') {
+				tokens = append(tokens, expression[i:i+2])
+				i++
+			} else {
+				tokens = append(tokens, string(c))
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// conditionParser は条件式（AND・OR・NOT・括弧・比較・attribute_exists/attribute_not_exists）を評価する
+type conditionParser struct {
+	tokens []string
+	pos    int
+	item   map[string]attributeValue
+	input  expressionInput
+}
+
+func (p *conditionParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) parseOr() bool {
+	result := p.parseAnd()
+	for p.peek() == "OR" {
+		p.next()
+		right := p.parseAnd()
+		result = result || right
+	}
+	return result
+}
+
+func (p *conditionParser) parseAnd() bool {
+	result := p.parseUnary()
+	for p.peek() == "AND" {
+		p.next()
+		right := p.parseUnary()
+		result = result && right
+	}
+	return result
+}
+
+func (p *conditionParser) parseUnary() bool {
+	switch token := p.next(); token {
+	case "NOT":
+		return !p.parseUnary()
+	case "(":
+		result := p.parseOr()
+		p.next() // ")"
+		return result
+	case "attribute_exists", "attribute_not_exists":
+		p.next() // "("
+		_, exists := getPath(p.item, resolvePath(p.next(), p.input))
+		p.next() // ")"
+		return exists == (token == "attribute_exists")
+	default:
+		left, leftOK := p.operand(token)
+		op := p.next()
+		right, rightOK := p.operand(p.next())
+		if !leftOK || !rightOK {
+			return op == "<>"
+		}
+		return compareAttributeValues(left, op, right)
+	}
+}
+
+// operand は:valueのプレースホルダーまたは属性のパスの値を返す
+func (p *conditionParser) operand(token string) (attributeValue, bool) {
+	if strings.HasPrefix(token, ":") {
+		value, ok := p.input.ExpressionAttributeValues[token]
+		return value, ok
+	}
+	return getPath(p.item, resolvePath(token, p.input))
+}
+
+// compareAttributeValues は文字列・数値・真偽値の属性値を比較する
+func compareAttributeValues(left attributeValue, op string, right attributeValue) bool {
+	var cmp int
+	switch {
+	case left["N"] != nil && right["N"] != nil:
+		l, _ := strconv.ParseFloat(left["N"].(string), 64)
+		r, _ := strconv.ParseFloat(right["N"].(string), 64)
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case left["S"] != nil && right["S"] != nil:
+		cmp = strings.Compare(left["S"].(string), right["S"].(string))
+	default:
+		equal := fmt.Sprint(left) == fmt.Sprint(right)
+		switch op {
+		case "=":
+			return equal
+		case "<>":
+			return !equal
+		}
+		return false
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// stringAttr はアイテムの文字列属性を返す（存在しない場合は空文字）
+func stringAttr(item map[string]attributeValue, name string) string {
+	s, _ := item[name]["S"].(string)
+	return s
+}
+
+func decodeFakeRequest(w http.ResponseWriter, r *http.Request, input interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		writeFakeError(w, "ValidationException", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeFakeResponse(w http.ResponseWriter, output interface{}) {
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+// writeFakeError はDynamoDBのエラーレスポンスを返す
+func writeFakeError(w http.ResponseWriter, errorType, message string) {
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + errorType,
+		"message": message,
+	})
+}
+
+// newFakeDB はfakeDynamoDBに接続するDBを作成する
+func newFakeDB(t *testing.T, fake *fakeDynamoDB) *dynamo.DB {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return dynamo.New(aws.Config{
+		Region:           "ap-northeast-1",
+		Credentials:      aws.AnonymousCredentials{},
+		BaseEndpoint:     aws.String(server.URL),
+		RetryMaxAttempts: 1,
+	})
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"okusuri-shared/clock"
+	"time"
+
+	"github.com/guregu/dynamo/v2"
+)
+
+// inventorySKPrefix は薬の在庫のソートキーの接頭辞（INVENTORY#{medicationId}、薬ごとに1件）
+const inventorySKPrefix = "INVENTORY#"
+
+// inventoryGSI1PK は在庫を登録したユーザーをGSI1で検索するためのパーティションキー
+// GSI1SKにはユーザーのPKを設定し、通知Lambdaが補充のリマインダーを判定する対象を取得する
+const inventoryGSI1PK = "INVENTORY"
+
+// maxAdjustAttempts は手持ちの錠数の更新が他の更新と競合した場合に読み直す回数の上限
+const maxAdjustAttempts = 3
+
+// ErrInventoryNotFound は在庫が登録されていない場合のエラー
+var ErrInventoryNotFound = errors.New("inventory not found")
+
+// ErrInventoryConflict は手持ちの錠数の更新が他の更新と競合し続けた場合のエラー
+var ErrInventoryConflict = errors.New("inventory update conflict")
+
+// DynamoInventoryRepository はDynamoDBを使用するInventoryRepositoryの実装
+type DynamoInventoryRepository struct {
+	table dynamo.Table
+	clock clock.Clock
+}
+
+func NewDynamoInventoryRepository(db *dynamo.DB, clk clock.Clock) *DynamoInventoryRepository {
+	return &DynamoInventoryRepository{
+		table: db.Table(config.GetDynamoDBTableName()),
+		clock: clk,
+	}
+}
+
+// ListInventories はユーザーの全ての薬の在庫をDynamoDBから取得する（登録順）
+func (r *DynamoInventoryRepository) ListInventories(ctx context.Context, userID string) ([]model.Inventory, error) {
+	var results []model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).
+		Range("SK", dynamo.BeginsWith, inventorySKPrefix).
+		All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	inventories := make([]model.Inventory, 0, len(results))
+	for _, result := range results {
+		inventory, err := toInventory(result)
+		if err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, *inventory)
+	}
+	return inventories, nil
+}
+
+// GetInventory は薬の在庫をDynamoDBから取得する
+func (r *DynamoInventoryRepository) GetInventory(ctx context.Context, userID string, medicationID string) (*model.Inventory, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).Range("SK", dynamo.Equal, inventorySK(medicationID)).One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrInventoryNotFound
+		}
+		return nil, err
+	}
+
+	return toInventory(result)
+}
+
+// SaveInventory は薬の在庫をDynamoDBに登録/更新する
+func (r *DynamoInventoryRepository) SaveInventory(ctx context.Context, userID string, inventory model.Inventory) error {
+	item := model.OkusuriTable{
+		PK:     userPK(userID),
+		SK:     inventorySK(inventory.MedicationID),
+		GSI1PK: inventoryGSI1PK,
+		GSI1SK: userPK(userID),
+		Type:   "INVENTORY",
+		Data: map[string]interface{}{
+			"name":               inventory.Name,
+			"packSize":           inventory.PackSize,
+			"packsOnHand":        inventory.PacksOnHand,
+			"loosePills":         inventory.LoosePills,
+			"pillsPerDose":       inventory.PillsPerDose,
+			"remainingPills":     inventory.RemainingPills,
+			"shortPills":         inventory.ShortPills,
+			"refillReminderDays": inventory.RefillReminderDays,
+			"refillReminderSent": inventory.RefillReminderSent,
+			"createdAt":          inventory.CreatedAt.Format(time.RFC3339),
+			"updatedAt":          inventory.UpdatedAt.Format(time.RFC3339),
+		},
+		CreatedAt: inventory.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: inventory.UpdatedAt.Format(time.RFC3339),
+	}
+
+	return r.table.Put(item).Run(ctx)
+}
+
+// DeleteInventory は薬の在庫をDynamoDBから削除する
+func (r *DynamoInventoryRepository) DeleteInventory(ctx context.Context, userID string, medicationID string) error {
+	err := r.table.Delete("PK", userPK(userID)).
+		Range("SK", inventorySK(medicationID)).
+		If("attribute_exists($)", "PK").
+		Run(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return ErrInventoryNotFound
+	}
+	return err
+}
+
+// AdjustPills は薬の手持ちの錠数にdeltaを加え、箱数と端数を計算し直した在庫を返す
+// 減らした結果が0未満になる場合は0にし、減らせなかった錠数を記録して戻すときに差し引く
+// 同時に登録された服用記録で更新を取りこぼさないよう、読み込んだ時点から錠数が変わっていない場合だけ更新し、変わっていた場合は読み直す
+func (r *DynamoInventoryRepository) AdjustPills(ctx context.Context, userID string, medicationID string, delta int) (*model.Inventory, error) {
+	for range maxAdjustAttempts {
+		inventory, err := r.GetInventory(ctx, userID, medicationID)
+		if err != nil {
+			return nil, err
+		}
+
+		expected, expectedShort := inventory.RemainingPills, inventory.ShortPills
+		inventory.AdjustPills(delta)
+		inventory.UpdatedAt = r.clock.Now()
+		now := inventory.UpdatedAt.Format(time.RFC3339)
+
+		err = r.table.Update("PK", userPK(userID)).
+			Range("SK", inventorySK(medicationID)).
+			Set("'Data'.'remainingPills'", inventory.RemainingPills).
+			Set("'Data'.'packsOnHand'", inventory.PacksOnHand).
+			Set("'Data'.'loosePills'", inventory.LoosePills).
+			Set("'Data'.'shortPills'", inventory.ShortPills).
+			Set("UpdatedAt", now).
+			Set("'Data'.'updatedAt'", now).
+			If("'Data'.'remainingPills' = ?", expected).
+			If("('Data'.'shortPills' = ? OR attribute_not_exists('Data'.'shortPills'))", expectedShort).
+			Run(ctx)
+		if dynamo.IsCondCheckFailed(err) {
+			// 他の更新が先に反映された（削除された場合は読み直しでErrInventoryNotFoundになる）
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return inventory, nil
+	}
+	return nil, ErrInventoryConflict
+}
+
+func inventorySK(medicationID string) string {
+	return inventorySKPrefix + medicationID
+}
+
+func toInventory(item model.OkusuriTable) (*model.Inventory, error) {
+	createdAt, updatedAt, err := parseTimestamps(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Inventory{
+		MedicationID:       item.SK[len(inventorySKPrefix):],
+		Name:               getStringValue(item.Data, "name", ""),
+		PackSize:           getIntValue(item.Data, "packSize", 1), // 箱の錠数を保存する前の在庫は1錠ずつ数える
+		PacksOnHand:        getIntValue(item.Data, "packsOnHand", 0),
+		LoosePills:         getIntValue(item.Data, "loosePills", 0),
+		PillsPerDose:       getIntValue(item.Data, "pillsPerDose", 1),
+		RemainingPills:     getIntValue(item.Data, "remainingPills", 0),
+		ShortPills:         getIntValue(item.Data, "shortPills", 0),
+		RefillReminderDays: getIntValue(item.Data, "refillReminderDays", model.DefaultRefillReminderDays),
+		RefillReminderSent: getBoolValue(item.Data, "refillReminderSent", false),
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
+	}, nil
+}
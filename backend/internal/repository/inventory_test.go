@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestInventory はfakeDynamoDBに手持ち30錠の在庫を登録し、リポジトリと薬のIDを返す
+func setupTestInventory(t *testing.T, packSize int) (*fakeDynamoDB, *DynamoInventoryRepository, string) {
+	t.Helper()
+
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	fake := &fakeDynamoDB{}
+	repo := NewDynamoInventoryRepository(newFakeDB(t, fake), clock.NewFixed(now))
+	medicationID := helper.NewID(now)
+	inventory := model.Inventory{
+		MedicationID: medicationID,
+		Name:         "ヤーズフレックス",
+		PackSize:     packSize,
+		PillsPerDose: 1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	inventory.SetRemainingPills(30)
+	require.NoError(t, repo.SaveInventory(context.Background(), "user-1", inventory))
+	return fake, repo, medicationID
+}
+
+func TestInventoryRepositoryAdjustPillsWithoutPackSize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("箱の錠数を保存する前の在庫は1錠ずつ数える", func(t *testing.T) {
+		fake, repo, medicationID := setupTestInventory(t, 28)
+		removePath(fake.items[0], []string{"Data", "packSize"})
+
+		inventory, err := repo.AdjustPills(ctx, "user-1", medicationID, -1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, inventory.PackSize)
+		assert.Equal(t, 29, inventory.RemainingPills)
+		assert.Equal(t, 29, inventory.PacksOnHand)
+		assert.Zero(t, inventory.LoosePills)
+	})
+
+	t.Run("1箱の錠数が0の場合は全て端数にする", func(t *testing.T) {
+		_, repo, medicationID := setupTestInventory(t, 0)
+
+		inventory, err := repo.AdjustPills(ctx, "user-1", medicationID, -1)
+		require.NoError(t, err)
+		assert.Equal(t, 29, inventory.RemainingPills)
+		assert.Zero(t, inventory.PacksOnHand)
+		assert.Equal(t, 29, inventory.LoosePills)
+	})
+}
+
+func TestInventoryRepositoryAdjustPillsShortPills(t *testing.T) {
+	ctx := context.Background()
+	_, repo, medicationID := setupTestInventory(t, 28)
+
+	inventory, err := repo.AdjustPills(ctx, "user-1", medicationID, -31)
+	require.NoError(t, err)
+	assert.Zero(t, inventory.RemainingPills)
+	assert.Equal(t, 1, inventory.ShortPills, "0未満にはせず減らせなかった錠数を記録する")
+
+	inventory, err = repo.AdjustPills(ctx, "user-1", medicationID, 31)
+	require.NoError(t, err)
+	assert.Equal(t, 30, inventory.RemainingPills, "減らせなかった錠数を差し引いて戻す")
+	assert.Equal(t, 1, inventory.PacksOnHand)
+	assert.Equal(t, 2, inventory.LoosePills)
+	assert.Zero(t, inventory.ShortPills)
+}
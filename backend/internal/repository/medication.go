@@ -2,69 +2,105 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/pkg/config"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"okusuri-shared/status"
+	"strings"
 	"time"
 
 	"github.com/guregu/dynamo/v2"
+	"github.com/rs/zerolog/log"
 )
 
-type MedicationRepository struct {
+// gsi1IndexName はGSI1PK/GSI1SKをキーに持つグローバルセカンダリインデックス名
+const gsi1IndexName = "GSI1"
+
+// medicationSKPrefix は服用記録のソートキーの接頭辞
+const medicationSKPrefix = "MEDICATION#"
+
+// deletedLogRetention は論理削除した服用記録を復元可能な期間
+const deletedLogRetention = 30 * 24 * time.Hour
+
+var (
+	// ErrLogNotFound は指定された服用記録が存在しない場合のエラー
+	ErrLogNotFound = errors.New("medication log not found")
+	// ErrLogNotDeleted は削除されていない服用記録を復元しようとした場合のエラー
+	ErrLogNotDeleted = errors.New("medication log is not deleted")
+)
+
+// DynamoMedicationRepository はDynamoDBを使用するMedicationRepositoryの実装
+type DynamoMedicationRepository struct {
+	db    *dynamo.DB
 	table dynamo.Table
+	clock clock.Clock
 }
 
-func NewMedicationRepository() *MedicationRepository {
-	db := config.GetDB()
+func NewDynamoMedicationRepository(db *dynamo.DB, clk clock.Clock) *DynamoMedicationRepository {
 	table := db.Table(config.GetDynamoDBTableName())
 
-	return &MedicationRepository{
+	return &DynamoMedicationRepository{
+		db:    db,
 		table: table,
+		clock: clk,
 	}
 }
 
 // RegisterLog はユーザーの服用記録をDynamoDBに登録する（後方互換性）
-func (r *MedicationRepository) RegisterLog(userID string, log model.MedicationLog) error {
+func (r *DynamoMedicationRepository) RegisterLog(userID string, log model.MedicationLog) (*model.MedicationLog, error) {
 	return r.RegisterLogWithContext(context.Background(), userID, log)
 }
 
-// RegisterLogWithContext はユーザーの服用記録をDynamoDBに登録する
-func (r *MedicationRepository) RegisterLogWithContext(ctx context.Context, userID string, log model.MedicationLog) error {
+// RegisterLogWithContext はユーザーの服用記録をDynamoDBに登録し、採番したIDを含む記録を返す
+func (r *DynamoMedicationRepository) RegisterLogWithContext(ctx context.Context, userID string, log model.MedicationLog) (*model.MedicationLog, error) {
+	log.ID = helper.NewID(r.clock.Now())
+	log.Date = log.CreatedAt.Format("2006-01-02")
+
 	// DynamoDBの単一テーブル設計に基づくキー生成
-	pk := fmt.Sprintf("USER#%s", userID)
-	sk := fmt.Sprintf("MEDICATION#%s#%d", log.CreatedAt.Format("2006-01-02"), time.Now().UnixNano())
+	date := log.Date
+	pk := userPK(userID)
+	sk := medicationSK(date, log.ID)
 
 	// OkusuriTable形式でデータを保存
+	// GSI1にはログIDをキーとして登録し、IDからの直接検索に使用する
 	item := model.OkusuriTable{
-		PK:   pk,
-		SK:   sk,
-		Date: log.CreatedAt.Format("2006-01-02"),
+		PK:     pk,
+		SK:     sk,
+		GSI1PK: medicationGSI1PK(log.ID),
+		GSI1SK: pk,
+		Type:   "MEDICATION",
+		Date:   date,
 		Data: map[string]interface{}{
-			"hasBleeding": log.HasBleeding,
-			"createdAt":   log.CreatedAt.Format(time.RFC3339),
-			"updatedAt":   log.UpdatedAt.Format(time.RFC3339),
+			"hasBleeding":      log.HasBleeding,
+			"bleedingSeverity": log.BleedingSeverity,
+			"createdAt":        log.CreatedAt.Format(time.RFC3339),
+			"updatedAt":        log.UpdatedAt.Format(time.RFC3339),
 		},
 		CreatedAt: log.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: log.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// DynamoDBに保存
-	err := r.table.Put(item).Run(ctx)
-	return err
+	if err := r.table.Put(item).Run(ctx); err != nil {
+		return nil, err
+	}
+	return &log, nil
 }
 
 // GetLogsByUserID はユーザーIDに基づいて服用履歴をDynamoDBから取得する（後方互換性）
-func (r *MedicationRepository) GetLogsByUserID(userID string) ([]model.MedicationLog, error) {
+func (r *DynamoMedicationRepository) GetLogsByUserID(userID string) ([]model.MedicationLog, error) {
 	return r.GetLogsByUserIDWithContext(context.Background(), userID)
 }
 
 // GetLogsByUserIDWithContext はユーザーIDに基づいて服用履歴をDynamoDBから取得する
-func (r *MedicationRepository) GetLogsByUserIDWithContext(ctx context.Context, userID string) ([]model.MedicationLog, error) {
-	pk := fmt.Sprintf("USER#%s", userID)
-
+func (r *DynamoMedicationRepository) GetLogsByUserIDWithContext(ctx context.Context, userID string) ([]model.MedicationLog, error) {
 	var results []model.OkusuriTable
-	err := r.table.Get("PK", pk).
-		Filter("begins_with(SK, ?)", "MEDICATION#").
+	err := r.table.Get("PK", userPK(userID)).
+		Range("SK", dynamo.BeginsWith, medicationSKPrefix).
+		Filter("attribute_not_exists($)", "DeletedAt").
 		All(ctx, &results)
 
 	if err != nil {
@@ -72,105 +108,374 @@ func (r *MedicationRepository) GetLogsByUserIDWithContext(ctx context.Context, u
 	}
 
 	// OkusuriTableからMedicationLogに変換
-	var logs []model.MedicationLog
-	for _, result := range results {
-		log := model.MedicationLog{
-			HasBleeding: getBoolValue(result.Data, "hasBleeding", false),
-			CreatedAt:   parseTime(getStringValue(result.Data, "createdAt", "")),
-			UpdatedAt:   parseTime(getStringValue(result.Data, "updatedAt", "")),
+	return toMedicationLogs(results), nil
+}
+
+// LogQuery は服用記録一覧の検索条件
+type LogQuery struct {
+	From   string // 開始日（YYYY-MM-DD形式、空の場合は制限なし）
+	To     string // 終了日（YYYY-MM-DD形式、空の場合は制限なし）
+	Limit  int    // 最大取得件数（0以下の場合は制限なし）
+	Cursor string // 前回のレスポンスで返したカーソル
+}
+
+// ListLogs は日付範囲とページングを指定して服用記録を取得する
+// 日付範囲は MEDICATION#YYYY-MM-DD のソートキーに対するキー条件として評価される
+func (r *DynamoMedicationRepository) ListLogs(ctx context.Context, userID string, query LogQuery) ([]model.MedicationLog, string, error) {
+	pk := userPK(userID)
+
+	// ソートキーは MEDICATION#<date>#<id> のため、終了日の全IDを含むよう "~" を上限に使う
+	lower := medicationSKPrefix + query.From
+	upper := medicationSKPrefix + "~"
+	if query.To != "" {
+		upper = medicationSKPrefix + query.To + "#~"
+	}
+
+	// 論理削除された記録を除外するフィルターとLimitを併用するとLastEvaluatedKeyの算出にDescribeTableが必要になるため、
+	// 1件多く取得して続きがあるかを判定し、最後に返したアイテムのSKからカーソルを生成する
+	q := r.table.Get("PK", pk).
+		Range("SK", dynamo.Between, lower, upper).
+		Filter("attribute_not_exists($)", "DeletedAt")
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit + 1)
+	}
+	if query.Cursor != "" {
+		startKey, err := decodeCursor(query.Cursor, pk, medicationSKPrefix)
+		if err != nil {
+			return nil, "", err
 		}
-		logs = append(logs, log)
+		q = q.StartFrom(startKey)
 	}
 
-	return logs, nil
+	var results []model.OkusuriTable
+	if err := q.All(ctx, &results); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+		nextCursor = encodeCursorSK(results[len(results)-1].SK)
+	}
+
+	return toMedicationLogs(results), nextCursor, nil
 }
 
 // GetLogByID はIDに基づいて単一の服薬ログを取得する
-func (r *MedicationRepository) GetLogByID(userID string, logID uint) (*model.MedicationLog, error) {
-	// DynamoDBでは直接的なID検索は困難なため、ユーザーの全ログから検索
-	logs, err := r.GetLogsByUserID(userID)
+func (r *DynamoMedicationRepository) GetLogByID(ctx context.Context, userID string, logID string) (*model.MedicationLog, error) {
+	item, err := r.findActiveLogItem(ctx, userID, logID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 簡易的なID検索（実際の運用では別の方法を検討）
-	for _, log := range logs {
-		// TODO: より効率的なID検索の実装
-		if log.CreatedAt.Unix() == int64(logID) {
-			return &log, nil
+	return toMedicationLog(*item)
+}
+
+// UpdateLog は指定されたIDの服薬ログを条件付きで更新し、更新後の記録を返す
+// 日付が変わる場合はソートキーが変わるため、旧アイテムの削除と新アイテムの作成をトランザクションで行う
+func (r *DynamoMedicationRepository) UpdateLog(ctx context.Context, userID string, logID string, update model.MedicationLogUpdate) (*model.MedicationLog, error) {
+	item, err := r.findActiveLogItem(ctx, userID, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.clock.Now().Format(time.RFC3339)
+	pk := userPK(userID)
+
+	if update.Date != nil {
+		newDate := update.Date.Format("2006-01-02")
+		if newDate != item.Date {
+			return r.moveLog(ctx, item, update, now)
+		}
+	}
+
+	u := r.table.Update("PK", item.PK).
+		Range("SK", item.SK).
+		Set("UpdatedAt", now).
+		Set("'Data'.'updatedAt'", now).
+		If("attribute_exists($) AND $ = ? AND attribute_not_exists($)", "PK", "GSI1SK", pk, "DeletedAt")
+	if update.HasBleeding != nil {
+		u = u.Set("'Data'.'hasBleeding'", *update.HasBleeding)
+	}
+	if update.BleedingSeverity != nil {
+		u = u.Set("'Data'.'bleedingSeverity'", *update.BleedingSeverity)
+	}
+	if update.Date != nil {
+		// 同じ日付内での時刻変更
+		createdAt := update.Date.Format(time.RFC3339)
+		u = u.Set("CreatedAt", createdAt).Set("'Data'.'createdAt'", createdAt)
+	}
+
+	var updated model.OkusuriTable
+	if err := u.Value(ctx, &updated); err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, ErrLogNotFound
 		}
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("log not found")
+	return toMedicationLog(updated)
 }
 
-// UpdateLog は指定されたIDの服薬ログを更新する
-func (r *MedicationRepository) UpdateLog(userID string, logID uint, hasBleeding bool) error {
-	// DynamoDBでは直接的な更新が困難なため、削除して再作成
-	// 実際の運用では条件付き更新を使用することを推奨
-	logs, err := r.GetLogsByUserID(userID)
+// moveLog は服用記録を別の日付へ移動する
+// IDは維持したまま、旧アイテムの削除と新アイテムの作成を1つのトランザクションで実行する
+func (r *DynamoMedicationRepository) moveLog(ctx context.Context, item *model.OkusuriTable, update model.MedicationLogUpdate, now string) (*model.MedicationLog, error) {
+	date := *update.Date
+	logID := logIDFromSK(item.SK)
+	newDate := date.Format("2006-01-02")
+	createdAt := date.Format(time.RFC3339)
+
+	data := make(map[string]interface{}, len(item.Data))
+	for k, v := range item.Data {
+		data[k] = v
+	}
+	if update.HasBleeding != nil {
+		data["hasBleeding"] = *update.HasBleeding
+	}
+	if update.BleedingSeverity != nil {
+		data["bleedingSeverity"] = *update.BleedingSeverity
+	}
+	data["createdAt"] = createdAt
+	data["updatedAt"] = now
+
+	moved := *item
+	moved.SK = medicationSK(newDate, logID)
+	moved.Date = newDate
+	moved.Data = data
+	moved.CreatedAt = createdAt
+	moved.UpdatedAt = now
+
+	err := r.db.WriteTx().
+		Delete(r.table.Delete("PK", item.PK).Range("SK", item.SK).
+			If("attribute_exists($) AND $ = ? AND attribute_not_exists($)", "PK", "GSI1SK", item.PK, "DeletedAt")).
+		Put(r.table.Put(moved).If("attribute_not_exists($)", "PK")).
+		Run(ctx)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, ErrLogNotFound
+		}
+		return nil, err
+	}
+
+	return toMedicationLog(moved)
+}
+
+// DeleteLog は服用記録を論理削除する
+// 削除日時とTTLを設定し、保持期間を過ぎるとDynamoDBのTTLにより物理削除される
+func (r *DynamoMedicationRepository) DeleteLog(ctx context.Context, userID string, logID string) error {
+	item, err := r.findActiveLogItem(ctx, userID, logID)
 	if err != nil {
 		return err
 	}
 
-	for _, log := range logs {
-		if log.CreatedAt.Unix() == int64(logID) {
-			// 更新されたログを作成
-			updatedLog := log
-			updatedLog.HasBleeding = hasBleeding
-			updatedLog.UpdatedAt = time.Now()
-
-			// 古いログを削除して新しいログを作成
-			// TODO: より効率的な更新処理の実装
-			return r.RegisterLog(userID, updatedLog)
+	now := r.clock.Now()
+	err = r.table.Update("PK", item.PK).
+		Range("SK", item.SK).
+		Set("DeletedAt", now.Format(time.RFC3339)).
+		Set("TTL", now.Add(deletedLogRetention).Unix()).
+		If("attribute_exists($) AND $ = ? AND attribute_not_exists($)", "PK", "GSI1SK", item.PK, "DeletedAt").
+		Run(ctx)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return ErrLogNotFound
 		}
+		return err
 	}
 
-	return fmt.Errorf("log not found")
+	return nil
 }
 
-// GetConsecutiveDays はユーザーの連続服薬日数を計算する
-func (r *MedicationRepository) GetConsecutiveDays(userID string) (int, error) {
-	logs, err := r.GetLogsByUserID(userID)
+// RestoreLog は論理削除された服用記録を保持期間内であれば復元する
+func (r *DynamoMedicationRepository) RestoreLog(ctx context.Context, userID string, logID string) (*model.MedicationLog, error) {
+	item, err := r.findLogItem(ctx, userID, logID)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if item.DeletedAt == "" {
+		return nil, ErrLogNotDeleted
 	}
 
-	if len(logs) == 0 {
-		return 0, nil
+	now := r.clock.Now()
+	if item.TTL != 0 && item.TTL <= now.Unix() {
+		// TTLによる物理削除待ちのアイテムは復元できない
+		return nil, ErrLogNotFound
 	}
 
-	// 連続日数をカウント
-	consecutiveDays := 1
-	today := time.Now().Truncate(24 * time.Hour)
+	nowStr := now.Format(time.RFC3339)
+	var restored model.OkusuriTable
+	err = r.table.Update("PK", item.PK).
+		Range("SK", item.SK).
+		Remove("DeletedAt", "TTL").
+		Set("UpdatedAt", nowStr).
+		Set("'Data'.'updatedAt'", nowStr).
+		If("attribute_exists($) AND $ = ? AND $ > ?", "DeletedAt", "GSI1SK", item.PK, "TTL", now.Unix()).
+		Value(ctx, &restored)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, ErrLogNotFound
+		}
+		return nil, err
+	}
+
+	return toMedicationLog(restored)
+}
 
-	// 最新の記録が今日かどうかチェック
-	latestLog := logs[0]
-	latestDate := latestLog.CreatedAt.Truncate(24 * time.Hour)
+// findActiveLogItem は論理削除されていない服用記録のアイテムを取得する
+func (r *DynamoMedicationRepository) findActiveLogItem(ctx context.Context, userID string, logID string) (*model.OkusuriTable, error) {
+	item, err := r.findLogItem(ctx, userID, logID)
+	if err != nil {
+		return nil, err
+	}
+	if item.DeletedAt != "" {
+		return nil, ErrLogNotFound
+	}
+	return item, nil
+}
 
-	// 最新の記録が今日でない場合は連続日数は0
-	if !latestDate.Equal(today) {
-		return 0, nil
+// findLogItem はGSI1のキー検索でログIDに対応するアイテムを取得する
+// GSI1SKにユーザーのPKを持たせているため、他ユーザーのログは取得できない
+// ULID導入前のIDでGSI1に見つからない場合は、ユーザーのパーティションから探してGSI1のキーを補完する
+func (r *DynamoMedicationRepository) findLogItem(ctx context.Context, userID string, logID string) (*model.OkusuriTable, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("GSI1PK", medicationGSI1PK(logID)).
+		Range("GSI1SK", dynamo.Equal, userPK(userID)).
+		Index(gsi1IndexName).
+		One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			if helper.IsLegacyID(logID) {
+				return r.findLegacyLogItem(ctx, userID, logID)
+			}
+			return nil, ErrLogNotFound
+		}
+		return nil, err
 	}
 
-	// 前日から遡って連続日数をカウント
-	expectedDate := today.AddDate(0, 0, -1)
+	return &result, nil
+}
 
-	for i := 1; i < len(logs); i++ {
-		logDate := logs[i].CreatedAt.Truncate(24 * time.Hour)
+// findLegacyLogItem はGSI1のキーを持たないULID導入前の服用記録をユーザーのパーティションから探す
+// 見つかった記録にはGSI1のキーを書き込み、以降はGSI1のキー検索と条件付きの更新・削除の対象にする
+func (r *DynamoMedicationRepository) findLegacyLogItem(ctx context.Context, userID string, logID string) (*model.OkusuriTable, error) {
+	pk := userPK(userID)
 
-		if logDate.Equal(expectedDate) {
-			consecutiveDays++
-			expectedDate = expectedDate.AddDate(0, 0, -1)
-		} else {
-			break
+	var results []model.OkusuriTable
+	err := r.table.Get("PK", pk).
+		Range("SK", dynamo.BeginsWith, medicationSKPrefix).
+		Filter("contains($, ?)", "SK", "#"+logID).
+		All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range results {
+		if logIDFromSK(item.SK) != logID {
+			continue
 		}
+		if item.GSI1PK == "" {
+			err := r.table.Update("PK", item.PK).
+				Range("SK", item.SK).
+				Set("GSI1PK", medicationGSI1PK(logID)).
+				Set("GSI1SK", pk).
+				If("attribute_exists($) AND attribute_not_exists($)", "PK", "GSI1PK").
+				Run(ctx)
+			// 同時に補完された場合は条件を満たさないが、書き込まれるキーは同じため続行する
+			if err != nil && !dynamo.IsCondCheckFailed(err) {
+				return nil, err
+			}
+			item.GSI1PK = medicationGSI1PK(logID)
+			item.GSI1SK = pk
+		}
+		return &item, nil
+	}
+
+	return nil, ErrLogNotFound
+}
+
+// GetConsecutiveDays はユーザーの連続服薬日数を計算する
+// 日付の境界は指定されたタイムゾーン（ユーザーのタイムゾーン）で判定する
+func (r *DynamoMedicationRepository) GetConsecutiveDays(userID string, loc *time.Location) (int, error) {
+	logs, err := r.GetLogsByUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 服用日をユーザーのタイムゾーンの日付文字列で集計
+	takenDates := make(map[string]bool, len(logs))
+	for _, log := range logs {
+		takenDates[log.CreatedAt.In(loc).Format("2006-01-02")] = true
+	}
+
+	// 今日から遡って連続日数をカウント（今日の記録がない場合は0）
+	consecutiveDays := 0
+	now := r.clock.Now().In(loc)
+	for day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc); takenDates[day.Format("2006-01-02")]; day = day.AddDate(0, 0, -1) {
+		consecutiveDays++
 	}
 
 	return consecutiveDays, nil
 }
 
 // ヘルパー関数
+func userPK(userID string) string {
+	return fmt.Sprintf("USER#%s", userID)
+}
+
+func medicationSK(date string, logID string) string {
+	return fmt.Sprintf("%s%s#%s", medicationSKPrefix, date, logID)
+}
+
+func medicationGSI1PK(logID string) string {
+	return fmt.Sprintf("%s%s", medicationSKPrefix, logID)
+}
+
+// logIDFromSK は MEDICATION#<date>#<id> 形式のソートキーからIDを取り出す
+func logIDFromSK(sk string) string {
+	parts := strings.SplitN(sk, "#", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+func toMedicationLog(item model.OkusuriTable) (*model.MedicationLog, error) {
+	createdAt, updatedAt, err := parseTimestamps(item)
+	if err != nil {
+		return nil, err
+	}
+
+	hasBleeding := getBoolValue(item.Data, "hasBleeding", false)
+	severity := getStringValue(item.Data, "bleedingSeverity", "")
+	if severity == "" {
+		// 出血の程度を記録する前の記録は出血の有無から変換する
+		severity = string(status.SeverityFromHasBleeding(hasBleeding))
+	}
+
+	return &model.MedicationLog{
+		ID:               logIDFromSK(item.SK),
+		Date:             item.Date,
+		HasBleeding:      hasBleeding,
+		BleedingSeverity: severity,
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+	}, nil
+}
+
+// toMedicationLogs はOkusuriTableのリストをMedicationLogのリストに変換する
+// 日時が壊れた記録が1件あるだけで一覧・ステータス・統計の全てが取得できなくならないよう、変換できない記録はログに残して除外する
+func toMedicationLogs(items []model.OkusuriTable) []model.MedicationLog {
+	logs := make([]model.MedicationLog, 0, len(items))
+	for _, item := range items {
+		medicationLog, err := toMedicationLog(item)
+		if err != nil {
+			log.Error().Err(err).Str("pk", item.PK).Str("sk", item.SK).Msg("服用記録を変換できないため除外します")
+			continue
+		}
+		logs = append(logs, *medicationLog)
+	}
+	return logs
+}
+
 func getBoolValue(data map[string]interface{}, key string, defaultValue bool) bool {
 	if value, ok := data[key].(bool); ok {
 		return value
@@ -178,6 +483,19 @@ func getBoolValue(data map[string]interface{}, key string, defaultValue bool) bo
 	return defaultValue
 }
 
+func getIntValue(data map[string]interface{}, key string, defaultValue int) int {
+	// DynamoDBの数値はmap[string]interface{}へのデコード時にfloat64になる
+	switch value := data[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case int64:
+		return int(value)
+	}
+	return defaultValue
+}
+
 func getStringValue(data map[string]interface{}, key string, defaultValue string) string {
 	if value, ok := data[key].(string); ok {
 		return value
@@ -185,13 +503,70 @@ func getStringValue(data map[string]interface{}, key string, defaultValue string
 	return defaultValue
 }
 
-func parseTime(timeStr string) time.Time {
+// getStringSliceValue はDataに保存されている文字列のリストを取得する
+func getStringSliceValue(data map[string]interface{}, key string) []string {
+	switch values := data[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// getIntSliceValue はDataに保存されている数値のリストを取得する
+func getIntSliceValue(data map[string]interface{}, key string) []int {
+	switch values := data[key].(type) {
+	case []int:
+		return values
+	case []interface{}:
+		result := make([]int, 0, len(values))
+		for _, value := range values {
+			switch n := value.(type) {
+			case float64:
+				result = append(result, int(n))
+			case int:
+				result = append(result, n)
+			case int64:
+				result = append(result, int(n))
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// parseTimestamps はアイテムのDataに保存されている作成日時と更新日時を解析する
+func parseTimestamps(item model.OkusuriTable) (time.Time, time.Time, error) {
+	createdAt, err := parseTime(getStringValue(item.Data, "createdAt", ""))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s のcreatedAtが不正です: %w", item.SK, err)
+	}
+	updatedAt, err := parseTime(getStringValue(item.Data, "updatedAt", ""))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s のupdatedAtが不正です: %w", item.SK, err)
+	}
+	return createdAt, updatedAt, nil
+}
+
+// ErrInvalidTime は保存されている日時がRFC3339形式でない場合のエラー
+var ErrInvalidTime = errors.New("invalid time format")
+
+// parseTime はRFC3339形式の日時を解析する
+// 空文字や不正な形式の場合は現在時刻で補わずにエラーを返す
+func parseTime(timeStr string) (time.Time, error) {
 	if timeStr == "" {
-		return time.Now()
+		return time.Time{}, fmt.Errorf("%w: empty", ErrInvalidTime)
 	}
 	t, err := time.Parse(time.RFC3339, timeStr)
 	if err != nil {
-		return time.Now()
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTime, timeStr)
 	}
-	return t
+	return t, nil
 }
@@ -0,0 +1,472 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMedicationRepository はfakeDynamoDBに接続するリポジトリを作成する
+func newTestMedicationRepository(t *testing.T, fake *fakeDynamoDB, clk clock.Clock) *DynamoMedicationRepository {
+	t.Helper()
+	return NewDynamoMedicationRepository(newFakeDB(t, fake), clk)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestMedicationRepositoryLogByID(t *testing.T) {
+	repo := newTestMedicationRepository(t, &fakeDynamoDB{}, clock.System())
+	ctx := context.Background()
+
+	createdAt := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{
+		HasBleeding: true,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	})
+	require.NoError(t, err)
+	require.True(t, helper.IsValidID(registered.ID), "ULIDを採番する")
+
+	t.Run("採番したIDで取得できる", func(t *testing.T) {
+		log, err := repo.GetLogByID(ctx, "user-1", registered.ID)
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, log.ID)
+		assert.True(t, log.HasBleeding)
+		assert.True(t, createdAt.Equal(log.CreatedAt))
+	})
+
+	t.Run("他のユーザーの記録は取得できない", func(t *testing.T) {
+		_, err := repo.GetLogByID(ctx, "user-2", registered.ID)
+		assert.ErrorIs(t, err, ErrLogNotFound)
+	})
+
+	t.Run("存在しないIDはErrLogNotFound", func(t *testing.T) {
+		_, err := repo.GetLogByID(ctx, "user-1", helper.NewID(createdAt))
+		assert.ErrorIs(t, err, ErrLogNotFound)
+	})
+}
+
+func TestMedicationRepositoryUpdateLog(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*DynamoMedicationRepository, *model.MedicationLog) {
+		repo := newTestMedicationRepository(t, &fakeDynamoDB{}, clock.System())
+		registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{
+			HasBleeding: true,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		})
+		require.NoError(t, err)
+		return repo, registered
+	}
+
+	t.Run("出血の有無だけを更新しても記録は増えない", func(t *testing.T) {
+		repo, registered := setup(t)
+
+		updated, err := repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{HasBleeding: boolPtr(false)})
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, updated.ID)
+		assert.False(t, updated.HasBleeding)
+		assert.True(t, createdAt.Equal(updated.CreatedAt), "日時を指定しなければ服用日時は変わらない")
+
+		logs, err := repo.GetLogsByUserIDWithContext(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.False(t, logs[0].HasBleeding)
+	})
+
+	t.Run("同じ日付内の時刻変更はその場で更新する", func(t *testing.T) {
+		repo, registered := setup(t)
+		date := createdAt.Add(3 * time.Hour)
+
+		updated, err := repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{Date: &date})
+		require.NoError(t, err)
+		assert.True(t, date.Equal(updated.CreatedAt))
+		assert.Equal(t, "2025-09-01", updated.Date)
+		assert.True(t, updated.HasBleeding)
+	})
+
+	t.Run("別の日付へはIDを維持したまま移動する", func(t *testing.T) {
+		repo, registered := setup(t)
+		date := createdAt.AddDate(0, 0, 1)
+
+		updated, err := repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{Date: &date, HasBleeding: boolPtr(false)})
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, updated.ID)
+		assert.Equal(t, "2025-09-02", updated.Date)
+
+		logs, err := repo.GetLogsByUserIDWithContext(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, logs, 1, "旧日付のアイテムは削除される")
+		assert.Equal(t, registered.ID, logs[0].ID)
+		assert.Equal(t, "2025-09-02", logs[0].Date)
+		assert.False(t, logs[0].HasBleeding)
+
+		found, err := repo.GetLogByID(ctx, "user-1", registered.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-09-02", found.Date)
+	})
+
+	t.Run("他のユーザーの記録は更新できない", func(t *testing.T) {
+		repo, registered := setup(t)
+
+		_, err := repo.UpdateLog(ctx, "user-2", registered.ID, model.MedicationLogUpdate{HasBleeding: boolPtr(false)})
+		assert.ErrorIs(t, err, ErrLogNotFound)
+
+		log, err := repo.GetLogByID(ctx, "user-1", registered.ID)
+		require.NoError(t, err)
+		assert.True(t, log.HasBleeding)
+	})
+
+	t.Run("取得後に削除された記録の更新は条件付き書き込みで失敗する", func(t *testing.T) {
+		fake := &fakeDynamoDB{}
+		repo := newTestMedicationRepository(t, fake, clock.System())
+		registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{CreatedAt: createdAt, UpdatedAt: createdAt})
+		require.NoError(t, err)
+
+		// IDの検索と書き込みの間に別のリクエストで削除された状態を再現する
+		fake.afterQuery = func(f *fakeDynamoDB) {
+			f.items = nil
+		}
+
+		_, err = repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{HasBleeding: boolPtr(false)})
+		assert.ErrorIs(t, err, ErrLogNotFound)
+		assert.Empty(t, fake.items, "条件を満たさない更新でアイテムを作成しない")
+	})
+
+	t.Run("取得後に削除された記録の移動はトランザクションごと取り消す", func(t *testing.T) {
+		fake := &fakeDynamoDB{}
+		repo := newTestMedicationRepository(t, fake, clock.System())
+		registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{CreatedAt: createdAt, UpdatedAt: createdAt})
+		require.NoError(t, err)
+
+		fake.afterQuery = func(f *fakeDynamoDB) {
+			f.items = nil
+		}
+
+		date := createdAt.AddDate(0, 0, 1)
+		_, err = repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{Date: &date})
+		assert.ErrorIs(t, err, ErrLogNotFound)
+		assert.Empty(t, fake.items, "移動先のアイテムも作成しない")
+	})
+}
+
+func TestMedicationRepositoryDeleteAndRestoreLog(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*fakeDynamoDB, *clock.Fixed, *DynamoMedicationRepository, *model.MedicationLog) {
+		fake := &fakeDynamoDB{}
+		clk := clock.NewFixed(createdAt.Add(time.Hour))
+		repo := newTestMedicationRepository(t, fake, clk)
+		registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{
+			HasBleeding: true,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		})
+		require.NoError(t, err)
+		return fake, clk, repo, registered
+	}
+
+	t.Run("削除した記録は取得・一覧・更新の対象外になる", func(t *testing.T) {
+		fake, clk, repo, registered := setup(t)
+
+		require.NoError(t, repo.DeleteLog(ctx, "user-1", registered.ID))
+
+		_, err := repo.GetLogByID(ctx, "user-1", registered.ID)
+		assert.ErrorIs(t, err, ErrLogNotFound)
+
+		logs, err := repo.GetLogsByUserIDWithContext(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, logs)
+
+		_, err = repo.UpdateLog(ctx, "user-1", registered.ID, model.MedicationLogUpdate{HasBleeding: boolPtr(false)})
+		assert.ErrorIs(t, err, ErrLogNotFound)
+
+		assert.ErrorIs(t, repo.DeleteLog(ctx, "user-1", registered.ID), ErrLogNotFound, "二重に削除できない")
+
+		// 物理削除のためのTTLを保持期間後に設定する
+		require.Len(t, fake.items, 1)
+		ttl, ok := fake.items[0]["TTL"]["N"].(string)
+		require.True(t, ok)
+		assert.Equal(t, strconv.FormatInt(clk.Now().Add(deletedLogRetention).Unix(), 10), ttl)
+	})
+
+	t.Run("削除した記録を復元できる", func(t *testing.T) {
+		fake, clk, repo, registered := setup(t)
+		require.NoError(t, repo.DeleteLog(ctx, "user-1", registered.ID))
+		clk.AdvanceDays(29)
+
+		restored, err := repo.RestoreLog(ctx, "user-1", registered.ID)
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, restored.ID)
+		assert.True(t, restored.HasBleeding)
+
+		logs, err := repo.GetLogsByUserIDWithContext(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, registered.ID, logs[0].ID)
+
+		_, hasTTL := fake.items[0]["TTL"]
+		assert.False(t, hasTTL, "復元した記録は物理削除されない")
+	})
+
+	t.Run("削除されていない記録は復元できない", func(t *testing.T) {
+		_, _, repo, registered := setup(t)
+
+		_, err := repo.RestoreLog(ctx, "user-1", registered.ID)
+		assert.ErrorIs(t, err, ErrLogNotDeleted)
+	})
+
+	t.Run("他のユーザーの記録は削除・復元できない", func(t *testing.T) {
+		_, _, repo, registered := setup(t)
+
+		assert.ErrorIs(t, repo.DeleteLog(ctx, "user-2", registered.ID), ErrLogNotFound)
+		require.NoError(t, repo.DeleteLog(ctx, "user-1", registered.ID))
+		_, err := repo.RestoreLog(ctx, "user-2", registered.ID)
+		assert.ErrorIs(t, err, ErrLogNotFound)
+	})
+
+	t.Run("保持期間を過ぎた記録は復元できない", func(t *testing.T) {
+		_, clk, repo, registered := setup(t)
+		require.NoError(t, repo.DeleteLog(ctx, "user-1", registered.ID))
+
+		// TTLによる物理削除待ちの状態にする
+		clk.Advance(deletedLogRetention)
+
+		_, err := repo.RestoreLog(ctx, "user-1", registered.ID)
+		assert.ErrorIs(t, err, ErrLogNotFound)
+	})
+}
+
+func TestMedicationRepositoryListLogs(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMedicationRepository(t, &fakeDynamoDB{}, clock.System())
+
+	// 2025-09-01から10日分の記録を登録し、9/05の記録は削除しておく
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	ids := make(map[string]string)
+	for i := 0; i < 10; i++ {
+		createdAt := start.AddDate(0, 0, i)
+		registered, err := repo.RegisterLogWithContext(ctx, "user-1", model.MedicationLog{CreatedAt: createdAt, UpdatedAt: createdAt})
+		require.NoError(t, err)
+		ids[registered.Date] = registered.ID
+	}
+	require.NoError(t, repo.DeleteLog(ctx, "user-1", ids["2025-09-05"]))
+	_, err := repo.RegisterLogWithContext(ctx, "user-2", model.MedicationLog{CreatedAt: start, UpdatedAt: start})
+	require.NoError(t, err)
+
+	dates := func(logs []model.MedicationLog) []string {
+		result := make([]string, 0, len(logs))
+		for _, log := range logs {
+			result = append(result, log.Date)
+		}
+		return result
+	}
+
+	t.Run("日付範囲は開始日と終了日を含む", func(t *testing.T) {
+		logs, next, err := repo.ListLogs(ctx, "user-1", LogQuery{From: "2025-09-03", To: "2025-09-06"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025-09-03", "2025-09-04", "2025-09-06"}, dates(logs))
+		assert.Empty(t, next)
+	})
+
+	t.Run("範囲を省略すると全期間を返す", func(t *testing.T) {
+		logs, _, err := repo.ListLogs(ctx, "user-1", LogQuery{})
+		require.NoError(t, err)
+		assert.Len(t, logs, 9)
+	})
+
+	t.Run("カーソルで続きのページを取得できる", func(t *testing.T) {
+		var all []string
+		cursor := ""
+		for page := 0; page < 10; page++ {
+			logs, next, err := repo.ListLogs(ctx, "user-1", LogQuery{From: "2025-09-02", Limit: 3, Cursor: cursor})
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(logs), 3)
+			all = append(all, dates(logs)...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, []string{
+			"2025-09-02", "2025-09-03", "2025-09-04", "2025-09-06",
+			"2025-09-07", "2025-09-08", "2025-09-09", "2025-09-10",
+		}, all, "削除済みの記録を除き、重複も欠落もなく取得する")
+	})
+
+	t.Run("不正なカーソルはErrInvalidCursor", func(t *testing.T) {
+		for _, cursor := range []string{"not-base64!", "bm90LWpzb24", encodeTestCursor(t, "SYMPTOM#2025-09-01")} {
+			_, _, err := repo.ListLogs(ctx, "user-1", LogQuery{Cursor: cursor})
+			assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+		}
+	})
+}
+
+// encodeTestCursor は指定したSKを持つカーソルを生成する
+func encodeTestCursor(t *testing.T, sk string) string {
+	t.Helper()
+	raw, err := json.Marshal(cursorPayload{SK: sk})
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "RFC3339", input: "2025-08-30T10:00:00Z", want: time.Date(2025, 8, 30, 10, 0, 0, 0, time.UTC)},
+		{name: "オフセット付き", input: "2025-08-30T19:00:00+09:00", want: time.Date(2025, 8, 30, 10, 0, 0, 0, time.UTC)},
+		{name: "空文字", input: "", wantErr: true},
+		{name: "日付のみ", input: "2025-08-30", wantErr: true},
+		{name: "不正な文字列", input: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTime)
+				assert.True(t, got.IsZero(), "現在時刻で補わない")
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got))
+		})
+	}
+}
+
+func TestToMedicationLogsSkipsInvalidTime(t *testing.T) {
+	items := []model.OkusuriTable{
+		{
+			SK:   medicationSK("2025-08-30", "01K3WMQ9X3Z8Q4H6B3F2A1C0DE"),
+			Date: "2025-08-30",
+			Data: map[string]interface{}{"createdAt": "2025-08-30T10:00:00Z", "updatedAt": "2025-08-30T10:00:00Z"},
+		},
+		{
+			SK:   medicationSK("2025-08-31", "01K3WMQ9X3Z8Q4H6B3F2A1C0DF"),
+			Date: "2025-08-31",
+			Data: map[string]interface{}{"createdAt": "broken", "updatedAt": "2025-08-31T10:00:00Z"},
+		},
+	}
+
+	logs := toMedicationLogs(items)
+	require.Len(t, logs, 1, "日時が壊れた記録だけを除外する")
+	assert.Equal(t, "01K3WMQ9X3Z8Q4H6B3F2A1C0DE", logs[0].ID)
+
+	_, err := toMedicationLog(items[1])
+	assert.ErrorIs(t, err, ErrInvalidTime)
+	assert.Contains(t, err.Error(), "MEDICATION#2025-08-31")
+}
+
+func TestToMedicationLogBleedingSeverity(t *testing.T) {
+	item := func(data map[string]interface{}) model.OkusuriTable {
+		data["createdAt"] = "2025-08-30T10:00:00Z"
+		data["updatedAt"] = "2025-08-30T10:00:00Z"
+		return model.OkusuriTable{
+			SK:   medicationSK("2025-08-30", "01K3WMQ9X3Z8Q4H6B3F2A1C0DE"),
+			Date: "2025-08-30",
+			Data: data,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		data            map[string]interface{}
+		wantHasBleeding bool
+		wantSeverity    string
+	}{
+		{name: "程度を記録する前の出血あり", data: map[string]interface{}{"hasBleeding": true}, wantHasBleeding: true, wantSeverity: model.BleedingModerate},
+		{name: "程度を記録する前の出血なし", data: map[string]interface{}{"hasBleeding": false}, wantSeverity: model.BleedingNone},
+		{name: "出血の程度", data: map[string]interface{}{"hasBleeding": true, "bleedingSeverity": "spotting"}, wantHasBleeding: true, wantSeverity: model.BleedingSpotting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := toMedicationLog(item(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHasBleeding, log.HasBleeding)
+			assert.Equal(t, tt.wantSeverity, log.BleedingSeverity)
+		})
+	}
+}
+
+func TestDynamoListLogsPagesWithFilter(t *testing.T) {
+	// medicationItem は指定日に服用した記録（deletedの場合は論理削除済み）
+	medicationItem := func(day int, deleted bool) map[string]attributeValue {
+		date := time.Date(2025, 9, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		item := map[string]attributeValue{
+			"PK":        {"S": userPK("user-1")},
+			"SK":        {"S": medicationSK(date, "01K3WMQ9X3Z8Q4H6B3F2A1C0D"+string(rune('0'+day)))},
+			"Date":      {"S": date},
+			"CreatedAt": {"S": date + "T09:00:00Z"},
+			"UpdatedAt": {"S": date + "T09:00:00Z"},
+			"Data": {"M": map[string]attributeValue{
+				"hasBleeding": {"BOOL": false},
+				"createdAt":   {"S": date + "T09:00:00Z"},
+				"updatedAt":   {"S": date + "T09:00:00Z"},
+			}},
+		}
+		if deleted {
+			item["DeletedAt"] = attributeValue{"S": date + "T10:00:00Z"}
+		}
+		return item
+	}
+	items := []map[string]attributeValue{
+		medicationItem(1, false),
+		medicationItem(2, true),
+		medicationItem(3, false),
+		medicationItem(4, true),
+		medicationItem(5, false),
+		medicationItem(6, false),
+	}
+	dates := func(logs []model.MedicationLog) []string {
+		result := make([]string, 0, len(logs))
+		for _, log := range logs {
+			result = append(result, log.Date)
+		}
+		return result
+	}
+
+	// 1回のQueryで全件を評価する場合（DynamoDBがLastEvaluatedKeyを返さない）と、複数回に分かれる場合
+	for _, pageSize := range []int{10, 3} {
+		t.Run(fmt.Sprintf("1回のQueryで%d件まで評価", pageSize), func(t *testing.T) {
+			fake := &fakeDynamoDB{pageSize: pageSize, items: items, denyDescribeTable: true}
+			repo := NewDynamoMedicationRepository(newFakeDB(t, fake), clock.System())
+			ctx := context.Background()
+
+			// DescribeTableの権限がなくても、論理削除された記録を除外しながらページングできる
+			logs, cursor, err := repo.ListLogs(ctx, "user-1", LogQuery{Limit: 2})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"2025-09-01", "2025-09-03"}, dates(logs))
+			require.NotEmpty(t, cursor)
+
+			logs, cursor, err = repo.ListLogs(ctx, "user-1", LogQuery{Limit: 2, Cursor: cursor})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"2025-09-05", "2025-09-06"}, dates(logs))
+			assert.Empty(t, cursor, "続きがない場合はカーソルを返さない")
+
+			logs, cursor, err = repo.ListLogs(ctx, "user-1", LogQuery{})
+			require.NoError(t, err)
+			assert.Len(t, logs, 4)
+			assert.Empty(t, cursor)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/clock"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryInventoryRepository はメモリ上に在庫を保持するInventoryRepositoryの実装
+type MemoryInventoryRepository struct {
+	mu          sync.RWMutex
+	inventories map[string]map[string]model.Inventory // userID → medicationID → 在庫
+	clock       clock.Clock
+}
+
+func NewMemoryInventoryRepository(clk clock.Clock) *MemoryInventoryRepository {
+	return &MemoryInventoryRepository{
+		inventories: make(map[string]map[string]model.Inventory),
+		clock:       clk,
+	}
+}
+
+// ListInventories はユーザーの全ての薬の在庫を取得する（登録順）
+func (r *MemoryInventoryRepository) ListInventories(_ context.Context, userID string) ([]model.Inventory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	inventories := make([]model.Inventory, 0, len(r.inventories[userID]))
+	for _, inventory := range r.inventories[userID] {
+		inventories = append(inventories, inventory)
+	}
+	// DynamoDBのソートキーと同じく、ULIDのmedicationIDの順（登録順）に並べる
+	slices.SortFunc(inventories, func(a, b model.Inventory) int {
+		return strings.Compare(a.MedicationID, b.MedicationID)
+	})
+	return inventories, nil
+}
+
+// GetInventory は薬の在庫を取得する
+func (r *MemoryInventoryRepository) GetInventory(_ context.Context, userID string, medicationID string) (*model.Inventory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	inventory, ok := r.inventories[userID][medicationID]
+	if !ok {
+		return nil, ErrInventoryNotFound
+	}
+	return &inventory, nil
+}
+
+// SaveInventory は薬の在庫を登録/更新する
+func (r *MemoryInventoryRepository) SaveInventory(_ context.Context, userID string, inventory model.Inventory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.inventories[userID] == nil {
+		r.inventories[userID] = make(map[string]model.Inventory)
+	}
+	r.inventories[userID][inventory.MedicationID] = inventory
+	return nil
+}
+
+// DeleteInventory は薬の在庫を削除する
+func (r *MemoryInventoryRepository) DeleteInventory(_ context.Context, userID string, medicationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.inventories[userID][medicationID]; !ok {
+		return ErrInventoryNotFound
+	}
+	delete(r.inventories[userID], medicationID)
+	return nil
+}
+
+// AdjustPills は薬の手持ちの錠数にdeltaを加え、箱数と端数を計算し直した在庫を返す（0未満になる場合は0にし、減らせなかった錠数を記録する）
+func (r *MemoryInventoryRepository) AdjustPills(_ context.Context, userID string, medicationID string, delta int) (*model.Inventory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inventory, ok := r.inventories[userID][medicationID]
+	if !ok {
+		return nil, ErrInventoryNotFound
+	}
+	inventory.AdjustPills(delta)
+	inventory.UpdatedAt = r.clock.Now()
+	r.inventories[userID][medicationID] = inventory
+
+	return &inventory, nil
+}
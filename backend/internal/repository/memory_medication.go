@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryLogEntry は論理削除の状態を含めて保持する服用記録
+type memoryLogEntry struct {
+	log       model.MedicationLog
+	deletedAt time.Time
+	expiresAt time.Time
+}
+
+// sk はDynamoDB実装と同じ並び順・カーソルを再現するためのソートキーを返す
+func (e *memoryLogEntry) sk() string {
+	return medicationSK(e.log.Date, e.log.ID)
+}
+
+// MemoryMedicationRepository はメモリ上に服用記録を保持するMedicationRepositoryの実装
+// テストやローカル開発での利用を想定しており、複数のゴルーチンから安全に利用できる
+type MemoryMedicationRepository struct {
+	mu    sync.RWMutex
+	logs  map[string]map[string]*memoryLogEntry // userID → logID → 服用記録
+	clock clock.Clock
+}
+
+func NewMemoryMedicationRepository(clk clock.Clock) *MemoryMedicationRepository {
+	return &MemoryMedicationRepository{
+		logs:  make(map[string]map[string]*memoryLogEntry),
+		clock: clk,
+	}
+}
+
+// RegisterLogWithContext はユーザーの服用記録をメモリに登録し、採番したIDを含む記録を返す
+func (r *MemoryMedicationRepository) RegisterLogWithContext(_ context.Context, userID string, log model.MedicationLog) (*model.MedicationLog, error) {
+	log.ID = helper.NewID(r.clock.Now())
+	log.Date = log.CreatedAt.Format("2006-01-02")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.logs[userID] == nil {
+		r.logs[userID] = make(map[string]*memoryLogEntry)
+	}
+	r.logs[userID][log.ID] = &memoryLogEntry{log: log}
+
+	return &log, nil
+}
+
+// GetLogsByUserIDWithContext はユーザーの削除されていない服用記録をすべて取得する
+func (r *MemoryMedicationRepository) GetLogsByUserIDWithContext(_ context.Context, userID string) ([]model.MedicationLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var logs []model.MedicationLog
+	for _, entry := range r.activeEntries(userID) {
+		logs = append(logs, entry.log)
+	}
+	return logs, nil
+}
+
+// ListLogs は日付範囲とページングを指定して服用記録を取得する
+func (r *MemoryMedicationRepository) ListLogs(_ context.Context, userID string, query LogQuery) ([]model.MedicationLog, string, error) {
+	startAfter := ""
+	if query.Cursor != "" {
+		sk, err := decodeCursorSK(query.Cursor, medicationSKPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		startAfter = sk
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := make([]model.MedicationLog, 0)
+	nextCursor := ""
+	for _, entry := range r.activeEntries(userID) {
+		if query.From != "" && entry.log.Date < query.From {
+			continue
+		}
+		if query.To != "" && entry.log.Date > query.To {
+			continue
+		}
+		if startAfter != "" && entry.sk() <= startAfter {
+			continue
+		}
+		if query.Limit > 0 && len(logs) == query.Limit {
+			nextCursor = encodeCursorSK(medicationSK(logs[len(logs)-1].Date, logs[len(logs)-1].ID))
+			break
+		}
+		logs = append(logs, entry.log)
+	}
+
+	return logs, nextCursor, nil
+}
+
+// GetLogByID はIDに基づいて単一の服薬ログを取得する
+func (r *MemoryMedicationRepository) GetLogByID(_ context.Context, userID string, logID string) (*model.MedicationLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.logs[userID][logID]
+	if !ok || !entry.deletedAt.IsZero() {
+		return nil, ErrLogNotFound
+	}
+
+	log := entry.log
+	return &log, nil
+}
+
+// UpdateLog は指定されたIDの服薬ログを更新し、更新後の記録を返す
+func (r *MemoryMedicationRepository) UpdateLog(_ context.Context, userID string, logID string, update model.MedicationLogUpdate) (*model.MedicationLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.logs[userID][logID]
+	if !ok || !entry.deletedAt.IsZero() {
+		return nil, ErrLogNotFound
+	}
+
+	if update.HasBleeding != nil {
+		entry.log.HasBleeding = *update.HasBleeding
+	}
+	if update.BleedingSeverity != nil {
+		entry.log.BleedingSeverity = *update.BleedingSeverity
+	}
+	if update.Date != nil {
+		entry.log.CreatedAt = *update.Date
+		entry.log.Date = update.Date.Format("2006-01-02")
+	}
+	entry.log.UpdatedAt = r.clock.Now()
+
+	log := entry.log
+	return &log, nil
+}
+
+// DeleteLog は服用記録を論理削除する
+func (r *MemoryMedicationRepository) DeleteLog(_ context.Context, userID string, logID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.logs[userID][logID]
+	if !ok || !entry.deletedAt.IsZero() {
+		return ErrLogNotFound
+	}
+
+	now := r.clock.Now()
+	entry.deletedAt = now
+	entry.expiresAt = now.Add(deletedLogRetention)
+	return nil
+}
+
+// RestoreLog は論理削除された服用記録を保持期間内であれば復元する
+func (r *MemoryMedicationRepository) RestoreLog(_ context.Context, userID string, logID string) (*model.MedicationLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.logs[userID][logID]
+	if !ok {
+		return nil, ErrLogNotFound
+	}
+	if entry.deletedAt.IsZero() {
+		return nil, ErrLogNotDeleted
+	}
+
+	now := r.clock.Now()
+	if !now.Before(entry.expiresAt) {
+		return nil, ErrLogNotFound
+	}
+
+	entry.deletedAt = time.Time{}
+	entry.expiresAt = time.Time{}
+	entry.log.UpdatedAt = now
+
+	log := entry.log
+	return &log, nil
+}
+
+// activeEntries は削除されていない服用記録をソートキー順に返す（呼び出し側でロックを取得すること）
+func (r *MemoryMedicationRepository) activeEntries(userID string) []*memoryLogEntry {
+	var entries []*memoryLogEntry
+	for _, entry := range r.logs[userID] {
+		if entry.deletedAt.IsZero() {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].sk() < entries[j].sk()
+	})
+	return entries
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"okusuri-shared/notifylog"
+	"sort"
+	"sync"
+)
+
+// MemoryNotificationRepository はメモリ上に通知設定を保持するNotificationRepositoryの実装
+type MemoryNotificationRepository struct {
+	mu        sync.RWMutex
+	settings  map[string]map[string]model.NotificationSetting // userID → platform → 通知設定
+	schedules map[string]map[string][]model.ReminderSchedule  // userID → platform → スケジュール
+	devices   map[string]map[string]model.NotificationDevice  // userID → deviceID → デバイス
+	logs      map[string][]model.NotificationLog              // userID → 送信履歴（通知Lambdaが記録する）
+}
+
+func NewMemoryNotificationRepository() *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{
+		settings:  make(map[string]map[string]model.NotificationSetting),
+		schedules: make(map[string]map[string][]model.ReminderSchedule),
+		devices:   make(map[string]map[string]model.NotificationDevice),
+		logs:      make(map[string][]model.NotificationLog),
+	}
+}
+
+// GetSetting はユーザーの通知設定を取得する
+func (r *MemoryNotificationRepository) GetSetting(_ context.Context, userID, platform string) (*model.NotificationSetting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	setting, ok := r.settings[userID][platform]
+	if !ok {
+		return nil, ErrSettingNotFound
+	}
+	return &setting, nil
+}
+
+// RegisterSetting はユーザーの通知設定を登録/更新する
+func (r *MemoryNotificationRepository) RegisterSetting(_ context.Context, userID string, setting model.NotificationSetting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.settings[userID] == nil {
+		r.settings[userID] = make(map[string]model.NotificationSetting)
+	}
+	r.settings[userID][setting.Platform] = setting
+	return nil
+}
+
+// ListSettings はユーザーの全プラットフォームの通知設定をプラットフォーム順に返す
+func (r *MemoryNotificationRepository) ListSettings(_ context.Context, userID string) ([]model.NotificationSetting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := make([]model.NotificationSetting, 0, len(r.settings[userID]))
+	for _, setting := range r.settings[userID] {
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Platform < settings[j].Platform })
+	return settings, nil
+}
+
+// ListSchedules はユーザーのリマインダーのスケジュールをプラットフォーム・時刻順に返す
+func (r *MemoryNotificationRepository) ListSchedules(_ context.Context, userID string) ([]model.ReminderSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var schedules []model.ReminderSchedule
+	for _, platformSchedules := range r.schedules[userID] {
+		schedules = append(schedules, platformSchedules...)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Platform != schedules[j].Platform {
+			return schedules[i].Platform < schedules[j].Platform
+		}
+		return schedules[i].Time < schedules[j].Time
+	})
+	return schedules, nil
+}
+
+// ReplaceSchedules はプラットフォームのリマインダーのスケジュールを置き換える
+func (r *MemoryNotificationRepository) ReplaceSchedules(_ context.Context, userID, platform string, schedules []model.ReminderSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schedules[userID] == nil {
+		r.schedules[userID] = make(map[string][]model.ReminderSchedule)
+	}
+	r.schedules[userID][platform] = append([]model.ReminderSchedule(nil), schedules...)
+	return nil
+}
+
+// GetDevice はユーザーの通知先のデバイスを取得する
+func (r *MemoryNotificationRepository) GetDevice(_ context.Context, userID, deviceID string) (*model.NotificationDevice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	device, ok := r.devices[userID][deviceID]
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return &device, nil
+}
+
+// SaveDevice はユーザーの通知先のデバイスを登録/更新する
+func (r *MemoryNotificationRepository) SaveDevice(_ context.Context, userID string, device model.NotificationDevice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.devices[userID] == nil {
+		r.devices[userID] = make(map[string]model.NotificationDevice)
+	}
+	r.devices[userID][device.ID] = device
+	return nil
+}
+
+// ListDevices はユーザーの通知先のデバイスをID順に返す
+func (r *MemoryNotificationRepository) ListDevices(_ context.Context, userID string) ([]model.NotificationDevice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	devices := make([]model.NotificationDevice, 0, len(r.devices[userID]))
+	for _, device := range r.devices[userID] {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices, nil
+}
+
+// DeleteDevice はユーザーの通知先のデバイスを削除する
+func (r *MemoryNotificationRepository) DeleteDevice(_ context.Context, userID, deviceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.devices[userID][deviceID]; !ok {
+		return ErrDeviceNotFound
+	}
+	delete(r.devices[userID], deviceID)
+	return nil
+}
+
+// AddNotificationLog は通知Lambdaの代わりに送信履歴を記録する
+func (r *MemoryNotificationRepository) AddNotificationLog(userID string, notificationLog model.NotificationLog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs[userID] = append(r.logs[userID], notificationLog)
+}
+
+// ListNotificationLogs は期間とページングを指定して送信履歴を新しい順に取得する
+func (r *MemoryNotificationRepository) ListNotificationLogs(
+	_ context.Context, userID string, query NotificationLogQuery,
+) ([]model.NotificationLog, string, error) {
+	startAfter := ""
+	if query.Cursor != "" {
+		sk, err := decodeCursorSK(query.Cursor, notifylog.SKPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		startAfter = sk
+	}
+	lower, upper := notificationLogRange(query)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := make([]model.NotificationLog, len(r.logs[userID]))
+	copy(sorted, r.logs[userID])
+	sort.Slice(sorted, func(i, j int) bool {
+		return notificationLogSK(sorted[i]) > notificationLogSK(sorted[j])
+	})
+
+	logs := make([]model.NotificationLog, 0)
+	nextCursor := ""
+	for _, notificationLog := range sorted {
+		sk := notificationLogSK(notificationLog)
+		if sk < lower || sk > upper {
+			continue
+		}
+		if startAfter != "" && sk >= startAfter {
+			continue
+		}
+		if query.Limit > 0 && len(logs) == query.Limit {
+			nextCursor = encodeCursorSK(notificationLogSK(logs[len(logs)-1]))
+			break
+		}
+		logs = append(logs, notificationLog)
+	}
+	return logs, nextCursor, nil
+}
+
+func notificationLogSK(notificationLog model.NotificationLog) string {
+	return notifylog.SK(notificationLog.SentAt, notificationLog.ID)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"sync"
+)
+
+// MemoryProfileRepository はメモリ上にプロフィールを保持するProfileRepositoryの実装
+type MemoryProfileRepository struct {
+	mu       sync.RWMutex
+	profiles map[string]model.UserProfile // userID → プロフィール
+}
+
+func NewMemoryProfileRepository() *MemoryProfileRepository {
+	return &MemoryProfileRepository{
+		profiles: make(map[string]model.UserProfile),
+	}
+}
+
+// GetProfile はユーザーのプロフィールを取得する
+func (r *MemoryProfileRepository) GetProfile(_ context.Context, userID string) (*model.UserProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	return &profile, nil
+}
+
+// SaveProfile はユーザーのプロフィールを登録/更新する
+func (r *MemoryProfileRepository) SaveProfile(_ context.Context, userID string, profile model.UserProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.profiles[userID] = profile
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"sync"
+)
+
+// MemoryRegimenRepository はメモリ上にレジメンを保持するRegimenRepositoryの実装
+type MemoryRegimenRepository struct {
+	mu       sync.RWMutex
+	regimens map[string]model.Regimen // userID → レジメン
+}
+
+func NewMemoryRegimenRepository() *MemoryRegimenRepository {
+	return &MemoryRegimenRepository{
+		regimens: make(map[string]model.Regimen),
+	}
+}
+
+// GetRegimen はユーザーのレジメンを取得する
+func (r *MemoryRegimenRepository) GetRegimen(_ context.Context, userID string) (*model.Regimen, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	regimen, ok := r.regimens[userID]
+	if !ok {
+		return nil, ErrRegimenNotFound
+	}
+	return &regimen, nil
+}
+
+// SaveRegimen はユーザーのレジメンを登録/更新する
+func (r *MemoryRegimenRepository) SaveRegimen(_ context.Context, userID string, regimen model.Regimen) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.regimens[userID] = regimen
+	return nil
+}
+
+// DeleteRegimen はユーザーのレジメンを削除する
+func (r *MemoryRegimenRepository) DeleteRegimen(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.regimens, userID)
+	return nil
+}
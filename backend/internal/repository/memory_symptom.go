@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"sort"
+	"sync"
+)
+
+// MemorySymptomRepository はメモリ上に症状を保持するSymptomRepositoryの実装
+// テストやローカル開発での利用を想定しており、複数のゴルーチンから安全に利用できる
+type MemorySymptomRepository struct {
+	mu       sync.RWMutex
+	symptoms map[string]map[string]model.Symptom // userID → symptomID → 症状
+	clock    clock.Clock
+}
+
+func NewMemorySymptomRepository(clk clock.Clock) *MemorySymptomRepository {
+	return &MemorySymptomRepository{
+		symptoms: make(map[string]map[string]model.Symptom),
+		clock:    clk,
+	}
+}
+
+// AddSymptom はユーザーの症状をメモリに登録し、採番したIDを含む症状を返す
+func (r *MemorySymptomRepository) AddSymptom(_ context.Context, userID string, symptom model.Symptom) (*model.Symptom, error) {
+	symptom.ID = helper.NewID(r.clock.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.symptoms[userID] == nil {
+		r.symptoms[userID] = make(map[string]model.Symptom)
+	}
+	r.symptoms[userID][symptom.ID] = symptom
+
+	return &symptom, nil
+}
+
+// GetSymptom はIDに基づいて単一の症状を取得する
+func (r *MemorySymptomRepository) GetSymptom(_ context.Context, userID string, symptomID string) (*model.Symptom, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	symptom, ok := r.symptoms[userID][symptomID]
+	if !ok {
+		return nil, ErrSymptomNotFound
+	}
+	return &symptom, nil
+}
+
+// UpdateSymptom は指定されたIDの症状を更新し、更新後の症状を返す
+func (r *MemorySymptomRepository) UpdateSymptom(_ context.Context, userID string, symptomID string, update model.SymptomUpdate) (*model.Symptom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symptom, ok := r.symptoms[userID][symptomID]
+	if !ok {
+		return nil, ErrSymptomNotFound
+	}
+
+	if update.Date != nil {
+		symptom.Date = *update.Date
+	}
+	if update.Type != nil {
+		symptom.Type = *update.Type
+	}
+	if update.Intensity != nil {
+		symptom.Intensity = *update.Intensity
+	}
+	if update.Note != nil {
+		symptom.Note = *update.Note
+	}
+	symptom.UpdatedAt = r.clock.Now()
+	r.symptoms[userID][symptomID] = symptom
+
+	return &symptom, nil
+}
+
+// ListSymptoms は日付範囲とページングを指定して症状を日付順に取得する
+func (r *MemorySymptomRepository) ListSymptoms(_ context.Context, userID string, query SymptomQuery) ([]model.Symptom, string, error) {
+	startAfter := ""
+	if query.Cursor != "" {
+		sk, err := decodeCursorSK(query.Cursor, symptomSKPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		startAfter = sk
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// DynamoDB実装と同じソートキー順に並べる
+	all := make([]model.Symptom, 0, len(r.symptoms[userID]))
+	for _, symptom := range r.symptoms[userID] {
+		all = append(all, symptom)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return symptomSK(all[i].Date, all[i].ID) < symptomSK(all[j].Date, all[j].ID)
+	})
+
+	symptoms := make([]model.Symptom, 0)
+	nextCursor := ""
+	for _, symptom := range all {
+		if query.From != "" && symptom.Date < query.From {
+			continue
+		}
+		if query.To != "" && symptom.Date > query.To {
+			continue
+		}
+		if startAfter != "" && symptomSK(symptom.Date, symptom.ID) <= startAfter {
+			continue
+		}
+		if query.Limit > 0 && len(symptoms) == query.Limit {
+			last := symptoms[len(symptoms)-1]
+			nextCursor = encodeCursorSK(symptomSK(last.Date, last.ID))
+			break
+		}
+		symptoms = append(symptoms, symptom)
+	}
+
+	return symptoms, nextCursor, nil
+}
@@ -2,54 +2,73 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/pkg/config"
+	"okusuri-shared/clock"
+	"okusuri-shared/notifylog"
+	"okusuri-shared/reminder"
+	"strings"
 	"time"
 
 	"github.com/guregu/dynamo/v2"
 )
 
-type NotificationRepository struct {
+var (
+	// ErrSettingNotFound は通知設定が存在しない場合のエラー
+	ErrSettingNotFound = errors.New("notification setting not found")
+	// ErrDeviceNotFound は通知先のデバイスが存在しない場合のエラー
+	ErrDeviceNotFound = errors.New("notification device not found")
+)
+
+// notificationSKPrefix は通知設定のソートキーの接頭辞（NOTIFICATION#{platform}）
+const notificationSKPrefix = "NOTIFICATION#"
+
+// deviceSKPrefix は通知先のデバイスのソートキーの接頭辞（DEVICE#{deviceId}）
+const deviceSKPrefix = "DEVICE#"
+
+// scheduleSKPrefix はリマインダーのスケジュールのソートキーの接頭辞（SCHEDULE#{platform}#{HH:MM}）
+const scheduleSKPrefix = "SCHEDULE#"
+
+// DynamoNotificationRepository はDynamoDBを使用するNotificationRepositoryの実装
+type DynamoNotificationRepository struct {
+	db    *dynamo.DB
 	table dynamo.Table
+	clock clock.Clock
 }
 
-func NewNotificationRepository() *NotificationRepository {
-	db := config.GetDB()
+func NewDynamoNotificationRepository(db *dynamo.DB, clk clock.Clock) *DynamoNotificationRepository {
 	table := db.Table(config.GetDynamoDBTableName())
 
-	return &NotificationRepository{
+	return &DynamoNotificationRepository{
+		db:    db,
 		table: table,
+		clock: clk,
 	}
 }
 
 // GetSetting はユーザーの通知設定をDynamoDBから取得する
-func (r *NotificationRepository) GetSetting(userID, platform string) (*model.NotificationSetting, error) {
-	pk := fmt.Sprintf("USER#%s", userID)
-	sk := fmt.Sprintf("NOTIFICATION#%s", platform)
+func (r *DynamoNotificationRepository) GetSetting(ctx context.Context, userID, platform string) (*model.NotificationSetting, error) {
+	pk := userPK(userID)
+	sk := notificationSK(platform)
 
 	var result model.OkusuriTable
-	err := r.table.Get("PK", pk).Range("SK", dynamo.Equal, sk).One(context.Background(), &result)
+	err := r.table.Get("PK", pk).Range("SK", dynamo.Equal, sk).One(ctx, &result)
 	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrSettingNotFound
+		}
 		return nil, err
 	}
 
-	// OkusuriTableからNotificationSettingに変換
-	setting := &model.NotificationSetting{
-		Platform:     getStringValue(result.Data, "platform", platform),
-		IsEnabled:    getBoolValue(result.Data, "isEnabled", true),
-		Subscription: getStringValue(result.Data, "subscription", ""),
-		CreatedAt:    parseTime(getStringValue(result.Data, "createdAt", "")),
-		UpdatedAt:    parseTime(getStringValue(result.Data, "updatedAt", "")),
-	}
-
-	return setting, nil
+	return toNotificationSetting(result, platform)
 }
 
 // RegisterSetting はユーザーの通知設定をDynamoDBに登録/更新する
-func (r *NotificationRepository) RegisterSetting(userID string, setting model.NotificationSetting) error {
-	pk := fmt.Sprintf("USER#%s", userID)
-	sk := fmt.Sprintf("NOTIFICATION#%s", setting.Platform)
+func (r *DynamoNotificationRepository) RegisterSetting(ctx context.Context, userID string, setting model.NotificationSetting) error {
+	pk := userPK(userID)
+	sk := notificationSK(setting.Platform)
 
 	// OkusuriTable形式でデータを保存
 	item := model.OkusuriTable{
@@ -57,19 +76,327 @@ func (r *NotificationRepository) RegisterSetting(userID string, setting model.No
 		SK:   sk,
 		Type: "NOTIFICATION",
 		Data: map[string]interface{}{
-			"platform":     setting.Platform,
-			"isEnabled":    setting.IsEnabled,
-			"subscription": setting.Subscription,
-			"createdAt":    setting.CreatedAt.Format(time.RFC3339),
-			"updatedAt":    setting.UpdatedAt.Format(time.RFC3339),
+			"platform":      setting.Platform,
+			"isEnabled":     setting.IsEnabled,
+			"subscription":  setting.Subscription,
+			"reminderTimes": setting.ReminderTimes,
+			"weekdays":      setting.Weekdays,
+			"createdAt":     setting.CreatedAt.Format(time.RFC3339),
+			"updatedAt":     setting.UpdatedAt.Format(time.RFC3339),
 		},
 		CreatedAt: setting.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: setting.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// DynamoDBに保存
-	err := r.table.Put(item).Run(context.Background())
+	err := r.table.Put(item).Run(ctx)
 	return err
 }
 
+// ListSettings はユーザーの全プラットフォームの通知設定をDynamoDBから取得する
+func (r *DynamoNotificationRepository) ListSettings(ctx context.Context, userID string) ([]model.NotificationSetting, error) {
+	var results []model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).
+		Range("SK", dynamo.BeginsWith, notificationSKPrefix).
+		All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]model.NotificationSetting, 0, len(results))
+	for _, result := range results {
+		setting, err := toNotificationSetting(result, strings.TrimPrefix(result.SK, notificationSKPrefix))
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *setting)
+	}
+	return settings, nil
+}
+
+// ListSchedules はユーザーのリマインダーのスケジュールをDynamoDBから取得する
+func (r *DynamoNotificationRepository) ListSchedules(ctx context.Context, userID string) ([]model.ReminderSchedule, error) {
+	var results []model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).
+		Range("SK", dynamo.BeginsWith, scheduleSKPrefix).
+		All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]model.ReminderSchedule, 0, len(results))
+	for _, result := range results {
+		schedules = append(schedules, toReminderSchedule(result))
+	}
+	return schedules, nil
+}
+
+// ReplaceSchedules はプラットフォームのリマインダーのスケジュールを置き換える
+// 各スケジュールはGSI1PKにUTCのバケット（REMINDER#{HH:MM}）を設定し、通知Lambdaから時刻で検索できるようにする
+func (r *DynamoNotificationRepository) ReplaceSchedules(ctx context.Context, userID, platform string, schedules []model.ReminderSchedule) error {
+	pk := userPK(userID)
+
+	var existing []model.OkusuriTable
+	err := r.table.Get("PK", pk).
+		Range("SK", dynamo.BeginsWith, scheduleSKPrefix+platform+"#").
+		All(ctx, &existing)
+	if err != nil {
+		return err
+	}
+
+	now := r.clock.Now().Format(time.RFC3339)
+	tx := r.db.WriteTx()
+	keep := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		sk := scheduleSK(platform, schedule.Time)
+		keep[sk] = true
+		tx.Put(r.table.Put(model.OkusuriTable{
+			PK:     pk,
+			SK:     sk,
+			GSI1PK: reminder.BucketKey(schedule.Bucket),
+			GSI1SK: pk,
+			Type:   "SCHEDULE",
+			Data: map[string]interface{}{
+				"platform": platform,
+				"time":     schedule.Time,
+				"weekdays": schedule.Weekdays,
+				"timezone": schedule.Timezone,
+				"bucket":   schedule.Bucket,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}))
+	}
+	// 同じアイテムをトランザクション内で削除と登録の両方に含めることはできないため、不要になったものだけ削除する
+	for _, item := range existing {
+		if !keep[item.SK] {
+			tx.Delete(r.table.Delete("PK", pk).Range("SK", item.SK))
+		}
+	}
+	if len(schedules) == 0 && len(existing) == 0 {
+		return nil
+	}
+
+	return tx.Run(ctx)
+}
+
+// GetDevice はユーザーの通知先のデバイスをDynamoDBから取得する
+func (r *DynamoNotificationRepository) GetDevice(ctx context.Context, userID, deviceID string) (*model.NotificationDevice, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).Range("SK", dynamo.Equal, deviceSK(deviceID)).One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	return toNotificationDevice(result)
+}
+
+// SaveDevice はユーザーの通知先のデバイスをDynamoDBに登録/更新する
+func (r *DynamoNotificationRepository) SaveDevice(ctx context.Context, userID string, device model.NotificationDevice) error {
+	item := model.OkusuriTable{
+		PK:   userPK(userID),
+		SK:   deviceSK(device.ID),
+		Type: "DEVICE",
+		Data: map[string]interface{}{
+			"platform":     device.Platform,
+			"subscription": device.Subscription,
+			"label":        device.Label,
+			"isEnabled":    device.IsEnabled,
+			"lastSeenAt":   device.LastSeenAt.Format(time.RFC3339),
+			"createdAt":    device.CreatedAt.Format(time.RFC3339),
+			"updatedAt":    device.UpdatedAt.Format(time.RFC3339),
+		},
+		CreatedAt: device.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: device.UpdatedAt.Format(time.RFC3339),
+	}
+	if device.DisabledReason != "" {
+		item.Data["disabledReason"] = device.DisabledReason
+	}
+	return r.table.Put(item).Run(ctx)
+}
+
+// ListDevices はユーザーの通知先のデバイスをDynamoDBから取得する
+func (r *DynamoNotificationRepository) ListDevices(ctx context.Context, userID string) ([]model.NotificationDevice, error) {
+	var results []model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).
+		Range("SK", dynamo.BeginsWith, deviceSKPrefix).
+		All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]model.NotificationDevice, 0, len(results))
+	for _, result := range results {
+		device, err := toNotificationDevice(result)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+	return devices, nil
+}
+
+// DeleteDevice はユーザーの通知先のデバイスをDynamoDBから削除する
+func (r *DynamoNotificationRepository) DeleteDevice(ctx context.Context, userID, deviceID string) error {
+	err := r.table.Delete("PK", userPK(userID)).
+		Range("SK", deviceSK(deviceID)).
+		If("attribute_exists($)", "PK").
+		Run(ctx)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return ErrDeviceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// NotificationLogQuery は送信履歴の検索条件
+type NotificationLogQuery struct {
+	From   time.Time // この日時以降の履歴（ゼロ値の場合は制限なし）
+	To     time.Time // この日時より前の履歴（ゼロ値の場合は制限なし）
+	Limit  int       // 最大取得件数（0以下の場合は制限なし）
+	Cursor string    // 前回のレスポンスで返したカーソル
+}
+
+// ListNotificationLogs は期間とページングを指定して送信履歴を新しい順に取得する
+// 期間は NOTIFYLOG#{送信日時} のソートキーに対するキー条件として評価される
+func (r *DynamoNotificationRepository) ListNotificationLogs(
+	ctx context.Context, userID string, query NotificationLogQuery,
+) ([]model.NotificationLog, string, error) {
+	pk := userPK(userID)
+	lower, upper := notificationLogRange(query)
+
+	q := r.table.Get("PK", pk).
+		Range("SK", dynamo.Between, lower, upper).
+		Order(dynamo.Descending)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+	if query.Cursor != "" {
+		startKey, err := decodeCursor(query.Cursor, pk, notifylog.SKPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		q = q.StartFrom(startKey)
+	}
+
+	var results []model.OkusuriTable
+	lek, err := q.AllWithLastEvaluatedKey(ctx, &results)
+	if err != nil {
+		return nil, "", err
+	}
+
+	logs := make([]model.NotificationLog, 0, len(results))
+	for _, result := range results {
+		notificationLog, err := toNotificationLog(result)
+		if err != nil {
+			return nil, "", err
+		}
+		logs = append(logs, *notificationLog)
+	}
+	return logs, encodeCursor(lek), nil
+}
+
+// notificationLogRange は検索条件の期間に対応するソートキーの範囲を返す
+func notificationLogRange(query NotificationLogQuery) (string, string) {
+	lower := notifylog.SKPrefix
+	upper := notifylog.SKPrefix + "~"
+	if !query.From.IsZero() {
+		lower = notifylog.Bound(query.From)
+	}
+	if !query.To.IsZero() {
+		upper = notifylog.Bound(query.To)
+	}
+	return lower, upper
+}
+
+func notificationSK(platform string) string {
+	return notificationSKPrefix + platform
+}
+
+func deviceSK(deviceID string) string {
+	return deviceSKPrefix + deviceID
+}
+
+func scheduleSK(platform, reminderTime string) string {
+	return scheduleSKPrefix + platform + "#" + reminderTime
+}
+
+func toNotificationSetting(item model.OkusuriTable, platform string) (*model.NotificationSetting, error) {
+	createdAt, updatedAt, err := parseTimestamps(item)
+	if err != nil {
+		return nil, err
+	}
+
+	// OkusuriTableからNotificationSettingに変換
+	return &model.NotificationSetting{
+		Platform:       getStringValue(item.Data, "platform", platform),
+		IsEnabled:      getBoolValue(item.Data, "isEnabled", true),
+		Subscription:   getStringValue(item.Data, "subscription", ""),
+		ReminderTimes:  getStringSliceValue(item.Data, "reminderTimes"),
+		Weekdays:       getIntSliceValue(item.Data, "weekdays"),
+		DisabledReason: getStringValue(item.Data, "disabledReason", ""),
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+	}, nil
+}
+
+func toNotificationDevice(item model.OkusuriTable) (*model.NotificationDevice, error) {
+	createdAt, updatedAt, err := parseTimestamps(item)
+	if err != nil {
+		return nil, err
+	}
+	lastSeenAt, err := parseTime(getStringValue(item.Data, "lastSeenAt", ""))
+	if err != nil {
+		return nil, fmt.Errorf("%s のlastSeenAtが不正です: %w", item.SK, err)
+	}
+
+	return &model.NotificationDevice{
+		ID:             strings.TrimPrefix(item.SK, deviceSKPrefix),
+		Platform:       getStringValue(item.Data, "platform", ""),
+		Subscription:   getStringValue(item.Data, "subscription", ""),
+		Label:          getStringValue(item.Data, "label", ""),
+		IsEnabled:      getBoolValue(item.Data, "isEnabled", true),
+		DisabledReason: getStringValue(item.Data, "disabledReason", ""),
+		LastSeenAt:     lastSeenAt,
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+	}, nil
+}
+
+func toNotificationLog(item model.OkusuriTable) (*model.NotificationLog, error) {
+	sentAt, id, ok := notifylog.Parse(item.SK)
+	if !ok {
+		return nil, fmt.Errorf("%s は送信履歴のソートキーではありません", item.SK)
+	}
+
+	return &model.NotificationLog{
+		ID:          id,
+		Kind:        getStringValue(item.Data, "kind", ""),
+		Outcome:     getStringValue(item.Data, "outcome", ""),
+		Platform:    getStringValue(item.Data, "platform", ""),
+		DeviceID:    getStringValue(item.Data, "deviceId", ""),
+		DeviceLabel: getStringValue(item.Data, "deviceLabel", ""),
+		Message:     getStringValue(item.Data, "message", ""),
+		StatusCode:  getIntValue(item.Data, "statusCode", 0),
+		Attempts:    getIntValue(item.Data, "attempts", 0),
+		Reason:      getStringValue(item.Data, "reason", ""),
+		LatencyMs:   int64(getIntValue(item.Data, "latencyMs", 0)),
+		SentAt:      sentAt,
+	}, nil
+}
+
+func toReminderSchedule(item model.OkusuriTable) model.ReminderSchedule {
+	return model.ReminderSchedule{
+		Platform: getStringValue(item.Data, "platform", ""),
+		Time:     getStringValue(item.Data, "time", ""),
+		Weekdays: getIntSliceValue(item.Data, "weekdays"),
+		Timezone: getStringValue(item.Data, "timezone", ""),
+		Bucket:   getStringValue(item.Data, "bucket", ""),
+	}
+}
+
 // ヘルパー関数はmedication.goで定義済み
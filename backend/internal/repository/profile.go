@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"time"
+
+	"github.com/guregu/dynamo/v2"
+)
+
+// profileSK はプロフィールのソートキー（ユーザーごとに1件）
+const profileSK = "PROFILE"
+
+// ErrProfileNotFound はプロフィールが登録されていない場合のエラー
+var ErrProfileNotFound = errors.New("profile not found")
+
+// DynamoProfileRepository はDynamoDBを使用するProfileRepositoryの実装
+type DynamoProfileRepository struct {
+	table dynamo.Table
+}
+
+func NewDynamoProfileRepository(db *dynamo.DB) *DynamoProfileRepository {
+	table := db.Table(config.GetDynamoDBTableName())
+
+	return &DynamoProfileRepository{
+		table: table,
+	}
+}
+
+// GetProfile はユーザーのプロフィールをDynamoDBから取得する
+func (r *DynamoProfileRepository) GetProfile(ctx context.Context, userID string) (*model.UserProfile, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).Range("SK", dynamo.Equal, profileSK).One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+
+	createdAt, updatedAt, err := parseTimestamps(result)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &model.UserProfile{
+		Timezone:  getStringValue(result.Data, "timezone", ""),
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+
+	return profile, nil
+}
+
+// SaveProfile はユーザーのプロフィールをDynamoDBに登録/更新する
+func (r *DynamoProfileRepository) SaveProfile(ctx context.Context, userID string, profile model.UserProfile) error {
+	item := model.OkusuriTable{
+		PK:   userPK(userID),
+		SK:   profileSK,
+		Type: "PROFILE",
+		Data: map[string]interface{}{
+			"timezone":  profile.Timezone,
+			"createdAt": profile.CreatedAt.Format(time.RFC3339),
+			"updatedAt": profile.UpdatedAt.Format(time.RFC3339),
+		},
+		CreatedAt: profile.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: profile.UpdatedAt.Format(time.RFC3339),
+	}
+
+	return r.table.Put(item).Run(ctx)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"time"
+
+	"github.com/guregu/dynamo/v2"
+)
+
+// regimenSK はレジメンのソートキー（ユーザーごとに1件）
+const regimenSK = "REGIMEN"
+
+// ErrRegimenNotFound はレジメンが設定されていない場合のエラー
+var ErrRegimenNotFound = errors.New("regimen not found")
+
+// DynamoRegimenRepository はDynamoDBを使用するRegimenRepositoryの実装
+type DynamoRegimenRepository struct {
+	table dynamo.Table
+}
+
+func NewDynamoRegimenRepository(db *dynamo.DB) *DynamoRegimenRepository {
+	table := db.Table(config.GetDynamoDBTableName())
+
+	return &DynamoRegimenRepository{
+		table: table,
+	}
+}
+
+// GetRegimen はユーザーのレジメンをDynamoDBから取得する
+func (r *DynamoRegimenRepository) GetRegimen(ctx context.Context, userID string) (*model.Regimen, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("PK", userPK(userID)).Range("SK", dynamo.Equal, regimenSK).One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrRegimenNotFound
+		}
+		return nil, err
+	}
+
+	createdAt, updatedAt, err := parseTimestamps(result)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := model.DefaultRegimen()
+	regimen := &model.Regimen{
+		Type:                    getStringValue(result.Data, "type", defaults.Type),
+		RestPeriodDays:          getIntValue(result.Data, "restPeriodDays", defaults.RestPeriodDays),
+		BleedingTriggerDays:     getIntValue(result.Data, "bleedingTriggerDays", defaults.BleedingTriggerDays),
+		MinIntakeDaysBeforeRest: getIntValue(result.Data, "minIntakeDaysBeforeRest", defaults.MinIntakeDaysBeforeRest),
+		MaxContinuousDays:       getIntValue(result.Data, "maxContinuousDays", 0),
+		ActiveDays:              getIntValue(result.Data, "activeDays", 0),
+		CycleStartDate:          getStringValue(result.Data, "cycleStartDate", ""),
+		BleedingThreshold:       getStringValue(result.Data, "bleedingThreshold", ""),
+		CreatedAt:               createdAt,
+		UpdatedAt:               updatedAt,
+	}
+
+	return regimen, nil
+}
+
+// SaveRegimen はユーザーのレジメンをDynamoDBに登録/更新する
+func (r *DynamoRegimenRepository) SaveRegimen(ctx context.Context, userID string, regimen model.Regimen) error {
+	item := model.OkusuriTable{
+		PK:   userPK(userID),
+		SK:   regimenSK,
+		Type: "REGIMEN",
+		Data: map[string]interface{}{
+			"type":                    regimen.Type,
+			"restPeriodDays":          regimen.RestPeriodDays,
+			"bleedingTriggerDays":     regimen.BleedingTriggerDays,
+			"minIntakeDaysBeforeRest": regimen.MinIntakeDaysBeforeRest,
+			"maxContinuousDays":       regimen.MaxContinuousDays,
+			"activeDays":              regimen.ActiveDays,
+			"cycleStartDate":          regimen.CycleStartDate,
+			"bleedingThreshold":       regimen.BleedingThreshold,
+			"createdAt":               regimen.CreatedAt.Format(time.RFC3339),
+			"updatedAt":               regimen.UpdatedAt.Format(time.RFC3339),
+		},
+		CreatedAt: regimen.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: regimen.UpdatedAt.Format(time.RFC3339),
+	}
+
+	return r.table.Put(item).Run(ctx)
+}
+
+// DeleteRegimen はユーザーのレジメンを削除する（以降はデフォルトのルールが適用される）
+func (r *DynamoRegimenRepository) DeleteRegimen(ctx context.Context, userID string) error {
+	return r.table.Delete("PK", userPK(userID)).Range("SK", regimenSK).Run(ctx)
+}
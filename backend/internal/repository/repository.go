@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"okusuri-backend/internal/model"
+)
+
+// MedicationRepository は服用記録の永続化を担うリポジトリ
+type MedicationRepository interface {
+	RegisterLogWithContext(ctx context.Context, userID string, log model.MedicationLog) (*model.MedicationLog, error)
+	GetLogsByUserIDWithContext(ctx context.Context, userID string) ([]model.MedicationLog, error)
+	ListLogs(ctx context.Context, userID string, query LogQuery) ([]model.MedicationLog, string, error)
+	GetLogByID(ctx context.Context, userID string, logID string) (*model.MedicationLog, error)
+	UpdateLog(ctx context.Context, userID string, logID string, update model.MedicationLogUpdate) (*model.MedicationLog, error)
+	DeleteLog(ctx context.Context, userID string, logID string) error
+	RestoreLog(ctx context.Context, userID string, logID string) (*model.MedicationLog, error)
+}
+
+// NotificationRepository は通知設定の永続化を担うリポジトリ
+type NotificationRepository interface {
+	GetSetting(ctx context.Context, userID, platform string) (*model.NotificationSetting, error)
+	RegisterSetting(ctx context.Context, userID string, setting model.NotificationSetting) error
+	ListSettings(ctx context.Context, userID string) ([]model.NotificationSetting, error)
+	ListSchedules(ctx context.Context, userID string) ([]model.ReminderSchedule, error)
+	ReplaceSchedules(ctx context.Context, userID, platform string, schedules []model.ReminderSchedule) error
+	GetDevice(ctx context.Context, userID, deviceID string) (*model.NotificationDevice, error)
+	SaveDevice(ctx context.Context, userID string, device model.NotificationDevice) error
+	ListDevices(ctx context.Context, userID string) ([]model.NotificationDevice, error)
+	DeleteDevice(ctx context.Context, userID, deviceID string) error
+	ListNotificationLogs(ctx context.Context, userID string, query NotificationLogQuery) ([]model.NotificationLog, string, error)
+}
+
+// RegimenRepository はユーザーごとの服薬ルールの永続化を担うリポジトリ
+type RegimenRepository interface {
+	GetRegimen(ctx context.Context, userID string) (*model.Regimen, error)
+	SaveRegimen(ctx context.Context, userID string, regimen model.Regimen) error
+	DeleteRegimen(ctx context.Context, userID string) error
+}
+
+// ProfileRepository はユーザーのプロフィールの永続化を担うリポジトリ
+type ProfileRepository interface {
+	GetProfile(ctx context.Context, userID string) (*model.UserProfile, error)
+	SaveProfile(ctx context.Context, userID string, profile model.UserProfile) error
+}
+
+// SymptomRepository は日ごとの症状の永続化を担うリポジトリ
+type SymptomRepository interface {
+	AddSymptom(ctx context.Context, userID string, symptom model.Symptom) (*model.Symptom, error)
+	GetSymptom(ctx context.Context, userID string, symptomID string) (*model.Symptom, error)
+	UpdateSymptom(ctx context.Context, userID string, symptomID string, update model.SymptomUpdate) (*model.Symptom, error)
+	ListSymptoms(ctx context.Context, userID string, query SymptomQuery) ([]model.Symptom, string, error)
+}
+
+// InventoryRepository は薬ごとの在庫の永続化を担うリポジトリ
+type InventoryRepository interface {
+	ListInventories(ctx context.Context, userID string) ([]model.Inventory, error)
+	GetInventory(ctx context.Context, userID string, medicationID string) (*model.Inventory, error)
+	SaveInventory(ctx context.Context, userID string, inventory model.Inventory) error
+	DeleteInventory(ctx context.Context, userID string, medicationID string) error
+	AdjustPills(ctx context.Context, userID string, medicationID string, delta int) (*model.Inventory, error)
+}
+
+var (
+	_ MedicationRepository   = (*DynamoMedicationRepository)(nil)
+	_ MedicationRepository   = (*MemoryMedicationRepository)(nil)
+	_ NotificationRepository = (*DynamoNotificationRepository)(nil)
+	_ NotificationRepository = (*MemoryNotificationRepository)(nil)
+	_ RegimenRepository      = (*DynamoRegimenRepository)(nil)
+	_ RegimenRepository      = (*MemoryRegimenRepository)(nil)
+	_ ProfileRepository      = (*DynamoProfileRepository)(nil)
+	_ ProfileRepository      = (*MemoryProfileRepository)(nil)
+	_ SymptomRepository      = (*DynamoSymptomRepository)(nil)
+	_ SymptomRepository      = (*MemorySymptomRepository)(nil)
+	_ InventoryRepository    = (*DynamoInventoryRepository)(nil)
+	_ InventoryRepository    = (*MemoryInventoryRepository)(nil)
+)
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"okusuri-backend/pkg/helper"
+	"okusuri-shared/clock"
+	"time"
+
+	"github.com/guregu/dynamo/v2"
+)
+
+// symptomSKPrefix は症状のソートキーの接頭辞
+const symptomSKPrefix = "SYMPTOM#"
+
+// ErrSymptomNotFound は指定された症状が存在しない場合のエラー
+var ErrSymptomNotFound = errors.New("symptom not found")
+
+// SymptomQuery は症状一覧の検索条件
+type SymptomQuery struct {
+	From   string // 開始日（YYYY-MM-DD形式、空の場合は制限なし）
+	To     string // 終了日（YYYY-MM-DD形式、空の場合は制限なし）
+	Limit  int    // 最大取得件数（0以下の場合は制限なし）
+	Cursor string // 前回のレスポンスで返したカーソル
+}
+
+// DynamoSymptomRepository はDynamoDBを使用するSymptomRepositoryの実装
+type DynamoSymptomRepository struct {
+	db    *dynamo.DB
+	table dynamo.Table
+	clock clock.Clock
+}
+
+func NewDynamoSymptomRepository(db *dynamo.DB, clk clock.Clock) *DynamoSymptomRepository {
+	return &DynamoSymptomRepository{
+		db:    db,
+		table: db.Table(config.GetDynamoDBTableName()),
+		clock: clk,
+	}
+}
+
+// AddSymptom はユーザーの症状をDynamoDBに登録し、採番したIDを含む症状を返す
+func (r *DynamoSymptomRepository) AddSymptom(ctx context.Context, userID string, symptom model.Symptom) (*model.Symptom, error) {
+	symptom.ID = helper.NewID(r.clock.Now())
+
+	pk := userPK(userID)
+	// GSI1には症状IDをキーとして登録し、IDからの直接検索に使用する
+	item := model.OkusuriTable{
+		PK:     pk,
+		SK:     symptomSK(symptom.Date, symptom.ID),
+		GSI1PK: symptomGSI1PK(symptom.ID),
+		GSI1SK: pk,
+		Type:   "SYMPTOM",
+		Date:   symptom.Date,
+		Data: map[string]interface{}{
+			"type":      symptom.Type,
+			"intensity": symptom.Intensity,
+			"note":      symptom.Note,
+			"createdAt": symptom.CreatedAt.Format(time.RFC3339),
+			"updatedAt": symptom.UpdatedAt.Format(time.RFC3339),
+		},
+		CreatedAt: symptom.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: symptom.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if err := r.table.Put(item).Run(ctx); err != nil {
+		return nil, err
+	}
+	return &symptom, nil
+}
+
+// GetSymptom はIDに基づいて単一の症状を取得する
+func (r *DynamoSymptomRepository) GetSymptom(ctx context.Context, userID string, symptomID string) (*model.Symptom, error) {
+	item, err := r.findSymptomItem(ctx, userID, symptomID)
+	if err != nil {
+		return nil, err
+	}
+	return toSymptom(*item)
+}
+
+// UpdateSymptom は指定されたIDの症状を条件付きで更新し、更新後の症状を返す
+// 日付が変わる場合はソートキーが変わるため、旧アイテムの削除と新アイテムの作成をトランザクションで行う
+func (r *DynamoSymptomRepository) UpdateSymptom(ctx context.Context, userID string, symptomID string, update model.SymptomUpdate) (*model.Symptom, error) {
+	item, err := r.findSymptomItem(ctx, userID, symptomID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.clock.Now().Format(time.RFC3339)
+	if update.Date != nil && *update.Date != item.Date {
+		return r.moveSymptom(ctx, item, update, now)
+	}
+
+	u := r.table.Update("PK", item.PK).
+		Range("SK", item.SK).
+		Set("UpdatedAt", now).
+		Set("'Data'.'updatedAt'", now).
+		If("attribute_exists($) AND $ = ?", "PK", "GSI1SK", item.PK)
+	if update.Type != nil {
+		u = u.Set("'Data'.'type'", *update.Type)
+	}
+	if update.Intensity != nil {
+		u = u.Set("'Data'.'intensity'", *update.Intensity)
+	}
+	if update.Note != nil {
+		u = u.Set("'Data'.'note'", *update.Note)
+	}
+
+	var updated model.OkusuriTable
+	if err := u.Value(ctx, &updated); err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, ErrSymptomNotFound
+		}
+		return nil, err
+	}
+
+	return toSymptom(updated)
+}
+
+// moveSymptom は症状を別の日付へ移動する
+// IDは維持したまま、旧アイテムの削除と新アイテムの作成を1つのトランザクションで実行する
+func (r *DynamoSymptomRepository) moveSymptom(ctx context.Context, item *model.OkusuriTable, update model.SymptomUpdate, now string) (*model.Symptom, error) {
+	newDate := *update.Date
+
+	data := make(map[string]interface{}, len(item.Data))
+	for k, v := range item.Data {
+		data[k] = v
+	}
+	if update.Type != nil {
+		data["type"] = *update.Type
+	}
+	if update.Intensity != nil {
+		data["intensity"] = *update.Intensity
+	}
+	if update.Note != nil {
+		data["note"] = *update.Note
+	}
+	data["updatedAt"] = now
+
+	moved := *item
+	moved.SK = symptomSK(newDate, logIDFromSK(item.SK))
+	moved.Date = newDate
+	moved.Data = data
+	moved.UpdatedAt = now
+
+	err := r.db.WriteTx().
+		Delete(r.table.Delete("PK", item.PK).Range("SK", item.SK).
+			If("attribute_exists($) AND $ = ?", "PK", "GSI1SK", item.PK)).
+		Put(r.table.Put(moved).If("attribute_not_exists($)", "PK")).
+		Run(ctx)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, ErrSymptomNotFound
+		}
+		return nil, err
+	}
+
+	return toSymptom(moved)
+}
+
+// ListSymptoms は日付範囲とページングを指定して症状を日付順に取得する
+// 日付範囲は SYMPTOM#YYYY-MM-DD のソートキーに対するキー条件として評価される
+func (r *DynamoSymptomRepository) ListSymptoms(ctx context.Context, userID string, query SymptomQuery) ([]model.Symptom, string, error) {
+	pk := userPK(userID)
+
+	// ソートキーは SYMPTOM#<date>#<id> のため、終了日の全IDを含むよう "~" を上限に使う
+	lower := symptomSKPrefix + query.From
+	upper := symptomSKPrefix + "~"
+	if query.To != "" {
+		upper = symptomSKPrefix + query.To + "#~"
+	}
+
+	q := r.table.Get("PK", pk).
+		Range("SK", dynamo.Between, lower, upper)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+	if query.Cursor != "" {
+		startKey, err := decodeCursor(query.Cursor, pk, symptomSKPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		q = q.StartFrom(startKey)
+	}
+
+	var results []model.OkusuriTable
+	lek, err := q.AllWithLastEvaluatedKey(ctx, &results)
+	if err != nil {
+		return nil, "", err
+	}
+
+	symptoms := make([]model.Symptom, 0, len(results))
+	for _, item := range results {
+		symptom, err := toSymptom(item)
+		if err != nil {
+			return nil, "", err
+		}
+		symptoms = append(symptoms, *symptom)
+	}
+
+	return symptoms, encodeCursor(lek), nil
+}
+
+// findSymptomItem はGSI1のキー検索で症状IDに対応するアイテムを取得する
+// GSI1SKにユーザーのPKを持たせているため、他ユーザーの症状は取得できない
+func (r *DynamoSymptomRepository) findSymptomItem(ctx context.Context, userID string, symptomID string) (*model.OkusuriTable, error) {
+	var result model.OkusuriTable
+	err := r.table.Get("GSI1PK", symptomGSI1PK(symptomID)).
+		Range("GSI1SK", dynamo.Equal, userPK(userID)).
+		Index(gsi1IndexName).
+		One(ctx, &result)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return nil, ErrSymptomNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func symptomSK(date string, symptomID string) string {
+	return fmt.Sprintf("%s%s#%s", symptomSKPrefix, date, symptomID)
+}
+
+func symptomGSI1PK(symptomID string) string {
+	return fmt.Sprintf("%s%s", symptomSKPrefix, symptomID)
+}
+
+func toSymptom(item model.OkusuriTable) (*model.Symptom, error) {
+	createdAt, updatedAt, err := parseTimestamps(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Symptom{
+		ID:        logIDFromSK(item.SK),
+		Date:      item.Date,
+		Type:      getStringValue(item.Data, "type", ""),
+		Intensity: getIntValue(item.Data, "intensity", 0),
+		Note:      getStringValue(item.Data, "note", ""),
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}, nil
+}
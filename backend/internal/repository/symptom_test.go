@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"okusuri-backend/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSymptom(t *testing.T) {
+	// DynamoDBからデコードした数値はfloat64になる
+	symptom, err := toSymptom(model.OkusuriTable{
+		SK:   symptomSK("2025-09-24", "01K3WMQ9X3Z8Q4H6B3F2A1C0DE"),
+		Date: "2025-09-24",
+		Data: map[string]interface{}{
+			"type":      "cramps",
+			"intensity": float64(8),
+			"note":      "夜に強くなった",
+			"createdAt": "2025-09-24T10:00:00Z",
+			"updatedAt": "2025-09-24T12:00:00Z",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &model.Symptom{
+		ID:        "01K3WMQ9X3Z8Q4H6B3F2A1C0DE",
+		Date:      "2025-09-24",
+		Type:      model.SymptomTypeCramps,
+		Intensity: 8,
+		Note:      "夜に強くなった",
+		CreatedAt: time.Date(2025, 9, 24, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 9, 24, 12, 0, 0, 0, time.UTC),
+	}, symptom)
+}
@@ -4,18 +4,65 @@ import (
 	"okusuri-backend/internal/handler"
 	"okusuri-backend/internal/middleware"
 	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-shared/clock"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guregu/dynamo/v2"
 )
 
-func SetupRoutes() *gin.Engine {
-	// リポジトリの初期化（Cognitoベース）
-	medicationRepo := repository.NewMedicationRepository()
-	notificationRepo := repository.NewNotificationRepository()
+// Dependencies はルーターが利用するリポジトリなどの依存関係
+type Dependencies struct {
+	MedicationRepo   repository.MedicationRepository
+	NotificationRepo repository.NotificationRepository
+	RegimenRepo      repository.RegimenRepository
+	ProfileRepo      repository.ProfileRepository
+	SymptomRepo      repository.SymptomRepository
+	InventoryRepo    repository.InventoryRepository
+	Clock            clock.Clock // 現在時刻の取得元（テストでは固定した時刻を注入する）
+}
+
+// NewDynamoDependencies はDynamoDBを利用する依存関係を生成する
+func NewDynamoDependencies(db *dynamo.DB, clk clock.Clock) Dependencies {
+	return Dependencies{
+		MedicationRepo:   repository.NewDynamoMedicationRepository(db, clk),
+		NotificationRepo: repository.NewDynamoNotificationRepository(db, clk),
+		RegimenRepo:      repository.NewDynamoRegimenRepository(db),
+		ProfileRepo:      repository.NewDynamoProfileRepository(db),
+		SymptomRepo:      repository.NewDynamoSymptomRepository(db, clk),
+		InventoryRepo:    repository.NewDynamoInventoryRepository(db, clk),
+		Clock:            clk,
+	}
+}
+
+// NewMemoryDependencies はメモリ上にデータを保持する依存関係を生成する（テスト・ローカル開発用）
+func NewMemoryDependencies(clk clock.Clock) Dependencies {
+	return Dependencies{
+		MedicationRepo:   repository.NewMemoryMedicationRepository(clk),
+		NotificationRepo: repository.NewMemoryNotificationRepository(),
+		RegimenRepo:      repository.NewMemoryRegimenRepository(),
+		ProfileRepo:      repository.NewMemoryProfileRepository(),
+		SymptomRepo:      repository.NewMemorySymptomRepository(clk),
+		InventoryRepo:    repository.NewMemoryInventoryRepository(clk),
+		Clock:            clk,
+	}
+}
+
+func SetupRoutes(deps Dependencies) *gin.Engine {
+	// サービスの初期化
+	profileService := service.NewProfileService(deps.ProfileRepo)
+	medicationService := service.NewMedicationService(deps.MedicationRepo, deps.RegimenRepo, profileService, deps.Clock)
+	notificationService := service.NewNotificationService(deps.NotificationRepo, profileService, deps.Clock)
+	symptomService := service.NewSymptomService(deps.SymptomRepo, medicationService, deps.Clock)
+	inventoryService := service.NewInventoryService(deps.InventoryRepo, medicationService, deps.Clock)
 
 	// ハンドラーの初期化
-	medicationHandler := handler.NewMedicationHandler(medicationRepo)
-	notificationHandler := handler.NewNotificationHandler(notificationRepo)
+	medicationHandler := handler.NewMedicationHandler(deps.MedicationRepo, medicationService, inventoryService, deps.Clock)
+	notificationHandler := handler.NewNotificationHandler(deps.NotificationRepo, notificationService, deps.Clock)
+	regimenHandler := handler.NewRegimenHandler(deps.RegimenRepo, medicationService, deps.Clock)
+	profileHandler := handler.NewProfileHandler(deps.ProfileRepo, profileService, notificationService, deps.Clock)
+	symptomHandler := handler.NewSymptomHandler(deps.SymptomRepo, symptomService, deps.Clock)
+	inventoryHandler := handler.NewInventoryHandler(deps.InventoryRepo, inventoryService, deps.Clock)
 
 	// Ginのルーターを作成
 	router := gin.Default()
@@ -34,6 +81,8 @@ func SetupRoutes() *gin.Engine {
 
 		// Cognito認証必須エンドポイント
 		api.GET("/medication-status", middleware.CognitoAuth(), medicationHandler.GetMedicationStatus)
+		api.GET("/medication-stats", middleware.CognitoAuth(), medicationHandler.GetMedicationStats)
+		api.GET("/cycles", middleware.CognitoAuth(), medicationHandler.GetCycles)
 
 		medicationLog := api.Group("/medication-log")
 		medicationLog.Use(middleware.CognitoAuth())
@@ -42,6 +91,47 @@ func SetupRoutes() *gin.Engine {
 			medicationLog.GET("", medicationHandler.GetLogs)
 			medicationLog.GET("/:id", medicationHandler.GetLogByID)
 			medicationLog.PATCH("/:id", medicationHandler.UpdateLog)
+			medicationLog.DELETE("/:id", medicationHandler.DeleteLog)
+			medicationLog.POST("/:id/restore", medicationHandler.RestoreLog)
+		}
+
+		// 症状エンドポイント
+		symptoms := api.Group("/symptoms")
+		symptoms.Use(middleware.CognitoAuth())
+		{
+			symptoms.POST("", symptomHandler.AddSymptom)
+			symptoms.GET("", symptomHandler.GetSymptoms)
+			symptoms.GET("/summary", symptomHandler.GetSymptomSummary)
+			symptoms.PATCH("/:id", symptomHandler.UpdateSymptom)
+		}
+
+		// 在庫エンドポイント
+		inventory := api.Group("/inventory")
+		inventory.Use(middleware.CognitoAuth())
+		{
+			inventory.GET("", inventoryHandler.ListInventories)
+			inventory.POST("", inventoryHandler.AddInventory)
+			inventory.GET("/:medicationId", inventoryHandler.GetInventory)
+			inventory.PUT("/:medicationId", inventoryHandler.SaveInventory)
+			inventory.DELETE("/:medicationId", inventoryHandler.DeleteInventory)
+		}
+
+		// レジメン（服薬ルール）エンドポイント
+		regimen := api.Group("/regimen")
+		regimen.Use(middleware.CognitoAuth())
+		{
+			regimen.GET("", regimenHandler.GetRegimen)
+			regimen.GET("/templates", regimenHandler.GetTemplates)
+			regimen.PUT("", regimenHandler.SaveRegimen)
+			regimen.DELETE("", regimenHandler.DeleteRegimen)
+		}
+
+		// プロフィール（タイムゾーン）エンドポイント
+		profile := api.Group("/profile")
+		profile.Use(middleware.CognitoAuth())
+		{
+			profile.GET("", profileHandler.GetProfile)
+			profile.PUT("", profileHandler.SaveProfile)
 		}
 
 		// 通知設定エンドポイント
@@ -51,6 +141,21 @@ func SetupRoutes() *gin.Engine {
 			notificationSetting.GET("", notificationHandler.GetSetting)
 			notificationSetting.POST("", notificationHandler.RegisterSetting)
 		}
+
+		// 通知先デバイスエンドポイント
+		notificationDevices := api.Group("/notification/devices")
+		notificationDevices.Use(middleware.CognitoAuth())
+		{
+			notificationDevices.GET("", notificationHandler.ListDevices)
+			notificationDevices.DELETE("/:id", notificationHandler.DeleteDevice)
+		}
+
+		// 通知の送信履歴エンドポイント
+		notificationHistory := api.Group("/notification/history")
+		notificationHistory.Use(middleware.CognitoAuth())
+		{
+			notificationHistory.GET("", notificationHandler.GetHistory)
+		}
 	}
 
 	return router
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-shared/clock"
+	"time"
+)
+
+type InventoryService struct {
+	inventoryRepo     repository.InventoryRepository
+	medicationService *MedicationService
+	clock             clock.Clock
+}
+
+func NewInventoryService(
+	inventoryRepo repository.InventoryRepository,
+	medicationService *MedicationService,
+	clk clock.Clock,
+) *InventoryService {
+	return &InventoryService{
+		inventoryRepo:     inventoryRepo,
+		medicationService: medicationService,
+		clock:             clk,
+	}
+}
+
+// ListInventories はユーザーの全ての薬の在庫と、今後の休薬期間を考慮した手持ちがなくなる日の予測を返す
+func (s *InventoryService) ListInventories(ctx context.Context, userID string) ([]dto.InventoryResponse, error) {
+	inventories, err := s.inventoryRepo.ListInventories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.InventoryResponse, 0, len(inventories))
+	if len(inventories) == 0 {
+		return responses, nil
+	}
+
+	// 服用記録・服薬ルール・タイムゾーンは全ての薬で共通のため1度だけ読み込む
+	forecaster, err := s.medicationService.NewRunOutForecaster(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, inventory := range inventories {
+		response, err := s.toInventoryResponse(forecaster, inventory)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetInventory は薬の在庫と、今後の休薬期間を考慮した手持ちがなくなる日の予測を返す
+// 在庫が登録されていない場合はrepository.ErrInventoryNotFoundを返す
+func (s *InventoryService) GetInventory(ctx context.Context, userID string, medicationID string) (*dto.InventoryResponse, error) {
+	inventory, err := s.inventoryRepo.GetInventory(ctx, userID, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	forecaster, err := s.medicationService.NewRunOutForecaster(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toInventoryResponse(forecaster, *inventory)
+}
+
+// toInventoryResponse は在庫に手持ちがなくなる日の予測を加えたレスポンスを作成する
+func (s *InventoryService) toInventoryResponse(forecaster *RunOutForecaster, inventory model.Inventory) (*dto.InventoryResponse, error) {
+	forecast, err := forecaster.ForecastRunOut(inventory.RemainingPills, inventory.PillsPerDose)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.InventoryResponse{
+		MedicationID:       inventory.MedicationID,
+		Name:               inventory.Name,
+		PackSize:           inventory.PackSize,
+		PillsPerDose:       inventory.PillsPerDose,
+		RemainingPills:     inventory.RemainingPills,
+		PacksOnHand:        inventory.PacksOnHand,
+		LoosePills:         inventory.LoosePills,
+		DosesLeft:          forecast.Doses,
+		RefillReminderDays: inventory.RefillReminderDays,
+		RefillReminderSent: inventory.RefillReminderSent,
+		UpdatedAt:          inventory.UpdatedAt,
+	}
+	if !forecast.LastDoseDate.IsZero() {
+		response.LastDoseDate = forecast.LastDoseDate.Format("2006-01-02")
+	}
+	if !forecast.RunOutDate.IsZero() {
+		loc := forecaster.Location()
+		now := s.clock.Now().In(loc)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		daysUntilRunOut := int(forecast.RunOutDate.Sub(today).Round(24*time.Hour).Hours() / 24)
+
+		response.RunOutDate = forecast.RunOutDate.Format("2006-01-02")
+		response.DaysUntilRunOut = &daysUntilRunOut
+		if inventory.RefillReminderDays > 0 {
+			response.RefillReminderDate = forecast.RunOutDate.AddDate(0, 0, -inventory.RefillReminderDays).Format("2006-01-02")
+		}
+	}
+	return response, nil
+}
+
+// ConsumeDose は服用記録の登録・復元に合わせて、登録済みの全ての薬の在庫から1回分を減らす
+func (s *InventoryService) ConsumeDose(ctx context.Context, userID string) error {
+	return s.adjustDose(ctx, userID, -1)
+}
+
+// ReturnDose は服用記録の削除に合わせて、登録済みの全ての薬の在庫に1回分を戻す
+func (s *InventoryService) ReturnDose(ctx context.Context, userID string) error {
+	return s.adjustDose(ctx, userID, 1)
+}
+
+// adjustDose は薬ごとに、在庫の手持ちの錠数を1回に服用する錠数のdoses回分だけ増減する
+// 1つの薬の更新に失敗しても他の薬は更新し、失敗したエラーをまとめて返す
+func (s *InventoryService) adjustDose(ctx context.Context, userID string, doses int) error {
+	inventories, err := s.inventoryRepo.ListInventories(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, inventory := range inventories {
+		_, err := s.inventoryRepo.AdjustPills(ctx, userID, inventory.MedicationID, doses*inventory.PillsPerDose)
+		if err != nil && !errors.Is(err, repository.ErrInventoryNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %w", inventory.MedicationID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,238 +1,348 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/internal/repository"
-	"sort"
+	"okusuri-shared/clock"
+	"okusuri-shared/status"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
+// ErrInvalidStatsRange は統計の期間の開始日が終了日より後の場合のエラー
+var ErrInvalidStatsRange = errors.New("from must not be after to")
+
 type MedicationService struct {
-	medicationRepo *repository.MedicationRepository
+	medicationRepo repository.MedicationRepository
+	regimenRepo    repository.RegimenRepository
+	profileService *ProfileService
+	statusEngine   *status.Engine
+	clock          clock.Clock
 }
 
-func NewMedicationService(medicationRepo *repository.MedicationRepository) *MedicationService {
+func NewMedicationService(
+	medicationRepo repository.MedicationRepository,
+	regimenRepo repository.RegimenRepository,
+	profileService *ProfileService,
+	clk clock.Clock,
+) *MedicationService {
 	return &MedicationService{
 		medicationRepo: medicationRepo,
+		regimenRepo:    regimenRepo,
+		profileService: profileService,
+		statusEngine:   status.NewEngine(),
+		clock:          clk,
 	}
 }
 
+// RegisterRegimenStrategy はレジメンの種類に対応する計算方法を登録する
+// 組み込みの種類を指定した場合は計算方法を置き換える
+func (s *MedicationService) RegisterRegimenStrategy(regimenType string, strategy status.Strategy) {
+	s.statusEngine.Register(regimenType, strategy)
+}
+
+// SupportsRegimenType はレジメンの種類に対応する計算方法が登録されているかを返す
+func (s *MedicationService) SupportsRegimenType(regimenType string) bool {
+	return s.statusEngine.Supports(regimenType)
+}
+
+// GetRegimen はユーザーのレジメンを取得する
+// 未設定の場合はデフォルトのルールを返し、isDefaultにtrueを返す
+func (s *MedicationService) GetRegimen(ctx context.Context, userID string) (model.Regimen, bool, error) {
+	regimen, err := s.regimenRepo.GetRegimen(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRegimenNotFound) {
+			return model.DefaultRegimen(), true, nil
+		}
+		return model.Regimen{}, false, err
+	}
+	return *regimen, false, nil
+}
+
+// GetLocation はユーザーのタイムゾーンを返す
+func (s *MedicationService) GetLocation(ctx context.Context, userID string) (*time.Location, error) {
+	return s.profileService.GetLocation(ctx, userID)
+}
+
+// ValidateRegimen はレジメンの種類ごとに必要な設定値が揃っているかを検証する
+func (s *MedicationService) ValidateRegimen(regimen model.Regimen) error {
+	if !s.SupportsRegimenType(regimen.Type) {
+		return fmt.Errorf("未対応のレジメン種別です: %s", regimen.Type)
+	}
+	return regimen.Rules().Validate()
+}
+
 // GetMedicationStatus は現在の服薬ステータスを計算する
-func (s *MedicationService) GetMedicationStatus(userID string) (*dto.MedicationStatusResponse, error) {
-	// 服薬ログを取得
-	logs, err := s.medicationRepo.GetLogsByUserID(userID)
+func (s *MedicationService) GetMedicationStatus(ctx context.Context, userID string) (*dto.MedicationStatusResponse, error) {
+	input, err := s.statusInput(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 日付でソート（新しい順）
-	sort.Slice(logs, func(i, j int) bool {
-		return logs[i].CreatedAt.After(logs[j].CreatedAt)
+	// レジメンの種類に応じた計算方法でステータスを算出
+	var result status.Result
+	err = s.withDefaultRegimenFallback(userID, input, func(input status.Input) (err error) {
+		result, err = s.statusEngine.Evaluate(input)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	// 現在日時
-	now := time.Now()
+	return toMedicationStatusResponse(result), nil
+}
 
-	// デフォルトのレスポンス
-	response := &dto.MedicationStatusResponse{
-		CurrentStreak:           0,
-		IsRestPeriod:            false,
-		RestDaysLeft:            0,
-		ConsecutiveBleedingDays: 0,
+// GetMedicationStats は期間（YYYY-MM-DD形式、ユーザーのタイムゾーン）内の服薬の統計を計算する
+// fromが空の場合は最初の服用日から、toが空の場合は当日までを集計する
+// 指定したfromが終了日より後の場合はErrInvalidStatsRangeを返す
+func (s *MedicationService) GetMedicationStats(ctx context.Context, userID, from, to string) (*dto.MedicationStatsResponse, error) {
+	input, err := s.statusInput(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// ログが存在しない場合は初期値を返す
-	if len(logs) == 0 {
-		return response, nil
+	start, end := input.Now.In(input.Location), input.Now.In(input.Location)
+	if from != "" {
+		if start, err = time.ParseInLocation("2006-01-02", from, input.Location); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, medicationLog := range input.Logs {
+			if medicationLog.TakenAt.Before(start) {
+				start = medicationLog.TakenAt.In(input.Location)
+			}
+		}
+	}
+	if to != "" {
+		if end, err = time.ParseInLocation("2006-01-02", to, input.Location); err != nil {
+			return nil, err
+		}
+	}
+	// toを省略した場合も当日を終了日として確認する
+	if from != "" && start.After(end) {
+		return nil, ErrInvalidStatsRange
 	}
 
-	// 休薬期間の判定と連続出血日数の計算
-	isInRestPeriod, restDaysLeft, consecutiveBleedingDays := s.calculateRestPeriodStatus(logs, now)
-	response.IsRestPeriod = isInRestPeriod
-	response.RestDaysLeft = restDaysLeft
-	response.ConsecutiveBleedingDays = consecutiveBleedingDays
-
-	// 休薬期間中でなければ、現在の連続服用日数を計算
-	if !isInRestPeriod {
-		response.CurrentStreak = s.calculateCurrentStreak(logs, now)
+	var stats status.Stats
+	err = s.withDefaultRegimenFallback(userID, input, func(input status.Input) (err error) {
+		stats, err = s.statusEngine.Stats(input, start, end)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	return toMedicationStatsResponse(stats, start, end), nil
 }
 
-// calculateRestPeriodStatus は休薬期間の状態を計算する
-func (s *MedicationService) calculateRestPeriodStatus(logs []model.MedicationLog, now time.Time) (bool, int, int) {
-	const restPeriodDays = 4 // 休薬期間は4日間
+// GetCycles は服用記録の全期間を服用期間とそれに続く休薬期間ごとの周期に分割して古い順に返す
+func (s *MedicationService) GetCycles(ctx context.Context, userID string) (*dto.CycleListResponse, error) {
+	input, err := s.statusInput(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	// 日付ごとに整理したログを取得（同じ日の重複を除去）
-	dateLogMap := make(map[string]model.MedicationLog)
-	for _, log := range logs {
-		dateStr := log.CreatedAt.Format("2006-01-02")
-		// 同じ日付の場合は最新のログを使用
-		// logsは新しい順にソートされているため、最初に見つけたログが最新
-		if _, exists := dateLogMap[dateStr]; !exists {
-			dateLogMap[dateStr] = log
-		}
+	var cycles []status.Cycle
+	err = s.withDefaultRegimenFallback(userID, input, func(input status.Input) (err error) {
+		cycles, err = s.statusEngine.Cycles(input)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 日付を過去順（降順）にソート
-	var dates []time.Time
-	for _, log := range dateLogMap {
-		dates = append(dates, log.CreatedAt)
+	response := &dto.CycleListResponse{Cycles: make([]dto.CycleResponse, 0, len(cycles))}
+	for _, cycle := range cycles {
+		response.Cycles = append(response.Cycles, toCycleResponse(cycle))
 	}
-	sort.Slice(dates, func(i, j int) bool {
-		return dates[i].After(dates[j])
-	})
+	return response, nil
+}
 
-	// 連続出血日数を計算
-	consecutiveBleedingDays := 0
-	var consecutiveBleedingDates []time.Time
-	lastDate := time.Time{}
-
-	for _, date := range dates {
-		// 日付を正規化（時間部分を削除）
-		currDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-
-		// 日付の文字列を取得
-		dateStr := currDate.Format("2006-01-02")
-		log := dateLogMap[dateStr]
-
-		if log.HasBleeding {
-			// 初めての出血日または連続している場合
-			if consecutiveBleedingDays == 0 || lastDate.IsZero() {
-				consecutiveBleedingDays = 1
-				consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
-			} else {
-				// 日付の差を計算
-				dayDiff := int(lastDate.Sub(currDate).Hours() / 24)
-
-				// 前日からの連続か確認
-				if dayDiff == 1 {
-					consecutiveBleedingDays++
-					consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
-				} else {
-					// 日付が連続していない場合はリセット
-					consecutiveBleedingDays = 1
-					consecutiveBleedingDates = []time.Time{currDate}
-				}
-			}
-		} else {
-			// 出血がない場合はリセット
-			break
-		}
+// GetTimeline は期間（YYYY-MM-DD形式、ユーザーのタイムゾーン）内の各日のステータスを古い順に返す
+// 当日より後の日は含まない
+func (s *MedicationService) GetTimeline(ctx context.Context, userID, from, to string) ([]status.Day, error) {
+	input, err := s.statusInput(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-		lastDate = currDate
+	start, err := time.ParseInLocation("2006-01-02", from, input.Location)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, input.Location)
+	if err != nil {
+		return nil, err
 	}
 
-	// 連続3日間以上の出血がある場合、休薬期間判定
-	if consecutiveBleedingDays >= 3 && len(consecutiveBleedingDates) >= 3 {
-		// 休薬開始日は連続出血の最初の日
-		restStartDate := consecutiveBleedingDates[len(consecutiveBleedingDates)-1]
+	var days []status.Day
+	err = s.withDefaultRegimenFallback(userID, input, func(input status.Input) (err error) {
+		days, err = s.statusEngine.Timeline(input, start, end)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
 
-		// 休薬終了日は休薬開始日から4日後の終日
-		restEndDate := restStartDate.AddDate(0, 0, restPeriodDays)
-		restEndDate = time.Date(
-			restEndDate.Year(), restEndDate.Month(), restEndDate.Day(),
-			23, 59, 59, 0, restEndDate.Location(),
-		)
+// RunOutForecaster は1度読み込んだ服用記録・服薬ルール・タイムゾーンから、薬ごとに手持ちがなくなる日を予測する
+type RunOutForecaster struct {
+	service *MedicationService
+	userID  string
+	input   status.Input
+}
 
-		// 現在が休薬期間内かどうか
-		if now.Before(restEndDate) {
-			// 残り日数を計算（日単位で切り上げ）
-			duration := restEndDate.Sub(now)
-			daysLeft := int(duration.Hours() / 24)
-			if duration.Hours() > float64(daysLeft*24) {
-				daysLeft++
-			}
-			return true, daysLeft, consecutiveBleedingDays
-		}
+// NewRunOutForecaster はユーザーの服用記録・服薬ルール・タイムゾーンを読み込んだRunOutForecasterを作成する
+func (s *MedicationService) NewRunOutForecaster(ctx context.Context, userID string) (*RunOutForecaster, error) {
+	input, err := s.statusInput(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
+	return &RunOutForecaster{service: s, userID: userID, input: input}, nil
+}
 
-	return false, 0, consecutiveBleedingDays
+// Location はユーザーのタイムゾーンを返す
+func (f *RunOutForecaster) Location() *time.Location {
+	return f.input.Location
 }
 
-// calculateCurrentStreak は現在の連続服用日数を計算する
-func (s *MedicationService) calculateCurrentStreak(logs []model.MedicationLog, now time.Time) int {
-	lastRestPeriodEndDate := s.findLastRestPeriodEndDate(logs)
-	uniqueDates := s.extractUniqueDates(logs)
-	return s.countConsecutiveDays(uniqueDates, lastRestPeriodEndDate, now)
+// ForecastRunOut は手持ちの錠数（1回pillsPerDose錠）がなくなる日を、今後の休薬期間を考慮して予測する
+func (f *RunOutForecaster) ForecastRunOut(pills, pillsPerDose int) (status.Forecast, error) {
+	var forecast status.Forecast
+	err := f.service.withDefaultRegimenFallback(f.userID, f.input, func(input status.Input) (err error) {
+		forecast, err = f.service.statusEngine.ForecastRunOut(input, pills, pillsPerDose)
+		return err
+	})
+	if err != nil {
+		return status.Forecast{}, err
+	}
+	return forecast, nil
 }
 
-// findLastRestPeriodEndDate は最後の休薬期間終了日を探す
-func (s *MedicationService) findLastRestPeriodEndDate(logs []model.MedicationLog) time.Time {
-	consecutiveBleedingCount := 0
-	var bleedingDates []time.Time
+// statusInput はユーザーの服用記録・服薬ルール・タイムゾーンからステータス計算の入力を作成する
+func (s *MedicationService) statusInput(ctx context.Context, userID string) (status.Input, error) {
+	// 服薬ログを取得
+	logs, err := s.medicationRepo.GetLogsByUserIDWithContext(ctx, userID)
+	if err != nil {
+		return status.Input{}, err
+	}
 
-	for i, log := range logs {
-		if log.HasBleeding {
-			consecutiveBleedingCount++
-			bleedingDates = append(bleedingDates, log.CreatedAt)
+	// 服薬ルールを取得
+	regimen, _, err := s.GetRegimen(ctx, userID)
+	if err != nil {
+		return status.Input{}, err
+	}
 
-			if consecutiveBleedingCount >= 3 {
-				oldestBleedingDate := bleedingDates[len(bleedingDates)-1]
-				return oldestBleedingDate.AddDate(0, 0, 4)
-			}
-		} else {
-			consecutiveBleedingCount = 0
-			bleedingDates = nil
+	// 日付の境界はユーザーのタイムゾーンで判定する
+	loc, err := s.GetLocation(ctx, userID)
+	if err != nil {
+		return status.Input{}, err
+	}
 
-			if i >= 3 && logs[i-1].HasBleeding && logs[i-2].HasBleeding && logs[i-3].HasBleeding {
-				return log.CreatedAt
-			}
-		}
+	input := status.Input{
+		Logs:     make([]status.Log, 0, len(logs)),
+		Now:      s.clock.Now(),
+		Regimen:  regimen.Rules(),
+		Location: loc,
 	}
-	return time.Time{}
+	for _, medicationLog := range logs {
+		input.Logs = append(input.Logs, status.Log{
+			TakenAt:     medicationLog.CreatedAt,
+			HasBleeding: medicationLog.HasBleeding,
+			Severity:    status.BleedingSeverity(medicationLog.BleedingSeverity),
+		})
+	}
+	return input, nil
 }
 
-// extractUniqueDates は重複を除去した日付リストを取得する
-func (s *MedicationService) extractUniqueDates(logs []model.MedicationLog) []time.Time {
-	dateMap := make(map[string]time.Time)
-	for _, log := range logs {
-		dateStr := log.CreatedAt.Format("2006-01-02")
-		if _, exists := dateMap[dateStr]; !exists {
-			dateMap[dateStr] = log.CreatedAt
-		}
+// withDefaultRegimenFallback はcalculateを実行し、レジメンの種類に対応する計算方法がない場合はデフォルトのルールで再実行する
+func (s *MedicationService) withDefaultRegimenFallback(
+	userID string, input status.Input, calculate func(status.Input) error,
+) error {
+	err := calculate(input)
+	if errors.Is(err, status.ErrUnsupportedRegimenType) {
+		log.Warn().
+			Str("user_id", userID).
+			Str("regimen_type", input.Regimen.Type).
+			Msg("未対応のレジメン種別のためデフォルトのルールで計算します")
+		input.Regimen = status.DefaultRegimen()
+		err = calculate(input)
 	}
+	return err
+}
 
-	var dates []time.Time
-	for _, date := range dateMap {
-		dates = append(dates, date)
+func toMedicationStatusResponse(result status.Result) *dto.MedicationStatusResponse {
+	response := &dto.MedicationStatusResponse{
+		CurrentStreak:           result.CurrentStreak,
+		IsRestPeriod:            result.IsRestPeriod,
+		RestDaysLeft:            result.RestDaysLeft,
+		ConsecutiveBleedingDays: result.ConsecutiveBleedingDays,
+		RegimenType:             result.RegimenType,
+		Phase:                   string(result.Phase),
 	}
-	sort.Slice(dates, func(i, j int) bool {
-		return dates[i].After(dates[j])
-	})
-	return dates
+	if !result.NextRestDate.IsZero() {
+		response.NextRestDate = result.NextRestDate.Format("2006-01-02")
+	}
+	return response
 }
 
-// countConsecutiveDays は休薬期間後からの連続日数をカウントする
-func (s *MedicationService) countConsecutiveDays(dates []time.Time, restEndDate time.Time, now time.Time) int {
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	yesterday := today.AddDate(0, 0, -1)
-
-	streak := 0
-	lastDate := today
-
-	for _, date := range dates {
-		currDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-
-		if !restEndDate.IsZero() && currDate.Before(restEndDate) {
-			break
-		}
-
-		if currDate.Equal(today) || currDate.Equal(yesterday) {
-			streak++
-			lastDate = currDate
-			continue
-		}
+func toMedicationStatsResponse(stats status.Stats, from, to time.Time) *dto.MedicationStatsResponse {
+	response := &dto.MedicationStatsResponse{
+		From:                from.Format("2006-01-02"),
+		To:                  to.Format("2006-01-02"),
+		LongestStreak:       stats.LongestStreak,
+		BleedingDays:        stats.BleedingDays,
+		MonthlyBleedingDays: make([]dto.MonthlyBleedingDaysResponse, 0, len(stats.MonthlyBleedingDays)),
+		RestPeriodCount:     len(stats.RestPeriods),
+		RestPeriods:         make([]dto.RestPeriodResponse, 0, len(stats.RestPeriods)),
+		AverageCycleLength:  roundToTenth(stats.AverageCycleLength),
+		ScheduledDays:       stats.ScheduledDays,
+		TakenDays:           stats.TakenDays,
+		AdherenceRate:       roundToTenth(stats.AdherenceRate),
+	}
+	for _, monthly := range stats.MonthlyBleedingDays {
+		response.MonthlyBleedingDays = append(response.MonthlyBleedingDays, dto.MonthlyBleedingDaysResponse{
+			Month: monthly.Month.Format("2006-01"),
+			Days:  monthly.Days,
+		})
+	}
+	for _, period := range stats.RestPeriods {
+		response.RestPeriods = append(response.RestPeriods, dto.RestPeriodResponse{
+			StartDate: period.Start.Format("2006-01-02"),
+			EndDate:   period.End.Format("2006-01-02"),
+		})
+	}
+	return response
+}
 
-		dayDiff := int(lastDate.Sub(currDate).Hours() / 24)
-		if dayDiff == 1 {
-			streak++
-			lastDate = currDate
-		} else {
-			break
-		}
+func toCycleResponse(cycle status.Cycle) dto.CycleResponse {
+	response := dto.CycleResponse{
+		StartDate:            cycle.Start.Format("2006-01-02"),
+		IntakeDays:           cycle.IntakeDays,
+		TakenDays:            cycle.TakenDays,
+		TriggerBleedingDates: make([]string, 0, len(cycle.TriggerBleedingDates)),
+		BreakTiming:          string(cycle.Timing),
+		BreakTimingDays:      cycle.TimingDays,
+		Ongoing:              cycle.Ongoing,
+	}
+	for _, date := range cycle.TriggerBleedingDates {
+		response.TriggerBleedingDates = append(response.TriggerBleedingDates, date.Format("2006-01-02"))
 	}
-	return streak
+	if !cycle.RestStart.IsZero() {
+		response.RestStartDate = cycle.RestStart.Format("2006-01-02")
+		response.RestEndDate = cycle.RestEnd.Format("2006-01-02")
+	}
+	return response
+}
+
+// roundToTenth は小数第1位に丸める
+func roundToTenth(value float64) float64 {
+	return math.Round(value*10) / 10
 }
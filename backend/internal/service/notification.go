@@ -0,0 +1,267 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-shared/clock"
+	"okusuri-shared/reminder"
+	"slices"
+	"time"
+)
+
+const (
+	// maxReminderTimes は1つの通知設定に登録できるリマインダー時刻の上限
+	maxReminderTimes = 8
+	// deviceIDLength はデバイスID（エンドポイントのSHA-256の先頭16バイトの16進表記）の長さ
+	deviceIDLength = 32
+)
+
+// ErrInvalidSubscription はWeb Pushのサブスクリプションからエンドポイントを読み取れない場合のエラー
+var ErrInvalidSubscription = errors.New("invalid push subscription")
+
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	profileService   *ProfileService
+	clock            clock.Clock
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	profileService *ProfileService,
+	clk clock.Clock,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		profileService:   profileService,
+		clock:            clk,
+	}
+}
+
+// NormalizeReminder はリマインダー時刻と曜日を検証し、重複を除いて昇順に並べる
+// 時刻が未指定の場合はデフォルトの時刻を設定する
+func NormalizeReminder(times []string, weekdays []int) ([]string, []int, error) {
+	if len(times) > maxReminderTimes {
+		return nil, nil, fmt.Errorf("%w: リマインダー時刻は%d件まで指定できます", reminder.ErrInvalidClock, maxReminderTimes)
+	}
+	for _, t := range times {
+		if err := reminder.ValidateClock(t); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := reminder.ValidateWeekdays(toWeekdays(weekdays)); err != nil {
+		return nil, nil, err
+	}
+
+	if len(times) == 0 {
+		times = []string{model.DefaultReminderTime}
+	}
+	times = slices.Compact(slices.Sorted(slices.Values(times)))
+	weekdays = slices.Compact(slices.Sorted(slices.Values(weekdays)))
+	// 全曜日の指定は毎日と同じ扱いにする
+	if len(weekdays) == 7 {
+		weekdays = nil
+	}
+	return times, weekdays, nil
+}
+
+// DeviceID はWeb PushのサブスクリプションのエンドポイントからデバイスのIDを求める
+// 同じブラウザで購読し直してもエンドポイントが変わらない限り同じIDになる
+func DeviceID(subscription string) (string, error) {
+	var sub struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.Unmarshal([]byte(subscription), &sub); err != nil || sub.Endpoint == "" {
+		return "", ErrInvalidSubscription
+	}
+	sum := sha256.Sum256([]byte(sub.Endpoint))
+	return hex.EncodeToString(sum[:deviceIDLength/2]), nil
+}
+
+// IsValidDeviceID はDeviceIDが返す形式のIDかどうかを判定する
+func IsValidDeviceID(id string) bool {
+	if len(id) != deviceIDLength {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// SaveSetting は通知設定を保存し、リマインダーのスケジュールを作り直す
+// サブスクリプションが指定された場合は通知設定ではなく通知先のデバイスとして登録する
+func (s *NotificationService) SaveSetting(ctx context.Context, userID string, setting model.NotificationSetting, deviceLabel string) error {
+	times, weekdays, err := NormalizeReminder(setting.ReminderTimes, setting.Weekdays)
+	if err != nil {
+		return err
+	}
+	setting.ReminderTimes = times
+	setting.Weekdays = weekdays
+
+	subscription := setting.Subscription
+	var deviceID string
+	if subscription != "" {
+		if deviceID, err = DeviceID(subscription); err != nil {
+			return err
+		}
+	}
+	// デバイスの導入前の通知設定に残っているサブスクリプションは、保存し直した時点でデバイスに移す
+	setting.Subscription = ""
+
+	if err := s.notificationRepo.RegisterSetting(ctx, userID, setting); err != nil {
+		return err
+	}
+	if deviceID != "" {
+		if err := s.registerDevice(ctx, userID, deviceID, setting.Platform, subscription, deviceLabel); err != nil {
+			return err
+		}
+	}
+
+	profile, _, err := s.profileService.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.syncSchedules(ctx, userID, setting, profile.Timezone)
+}
+
+// RescheduleReminders はユーザーの全通知設定のスケジュールを現在のタイムゾーンで作り直す
+// タイムゾーンを変更するとリマインダー時刻が属するUTCのバケットが変わるため、プロフィールの保存後に呼び出す
+func (s *NotificationService) RescheduleReminders(ctx context.Context, userID string) error {
+	settings, err := s.notificationRepo.ListSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	profile, _, err := s.profileService.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		if err := s.syncSchedules(ctx, userID, setting, profile.Timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncSchedules は通知設定のリマインダー時刻からスケジュールを作成して保存する
+// 通知が無効な場合はスケジュールを削除し、通知Lambdaの検索対象から外す
+func (s *NotificationService) syncSchedules(ctx context.Context, userID string, setting model.NotificationSetting, timezone string) error {
+	var schedules []model.ReminderSchedule
+	if setting.IsEnabled {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("タイムゾーン %s を読み込めません: %w", timezone, err)
+		}
+
+		times := setting.ReminderTimes
+		if len(times) == 0 {
+			// リマインダー時刻の導入前に保存された通知設定
+			times = []string{model.DefaultReminderTime}
+		}
+		for _, t := range times {
+			bucket, err := reminder.UTCBucket(t, loc, s.clock.Now())
+			if err != nil {
+				return err
+			}
+			schedules = append(schedules, model.ReminderSchedule{
+				Platform: setting.Platform,
+				Time:     t,
+				Weekdays: setting.Weekdays,
+				Timezone: timezone,
+				Bucket:   bucket,
+			})
+		}
+	}
+
+	return s.notificationRepo.ReplaceSchedules(ctx, userID, setting.Platform, schedules)
+}
+
+// registerDevice は通知先のデバイスを登録し、最後に購読を登録した日時を更新する
+// 通知Lambdaが失効により無効化したデバイスも、購読を登録し直すと再び有効になる
+func (s *NotificationService) registerDevice(ctx context.Context, userID, deviceID, platform, subscription, label string) error {
+	now := s.clock.Now()
+	device := model.NotificationDevice{
+		ID:        deviceID,
+		CreatedAt: now,
+	}
+	existing, err := s.notificationRepo.GetDevice(ctx, userID, deviceID)
+	switch {
+	case err == nil:
+		device = *existing
+	case !errors.Is(err, repository.ErrDeviceNotFound):
+		return err
+	}
+
+	device.Platform = platform
+	device.Subscription = subscription
+	if label != "" {
+		device.Label = label
+	}
+	if device.Label == "" {
+		device.Label = platform
+	}
+	device.IsEnabled = true
+	device.DisabledReason = ""
+	device.LastSeenAt = now
+	device.UpdatedAt = now
+	return s.notificationRepo.SaveDevice(ctx, userID, device)
+}
+
+// ListDevices はユーザーの通知先のデバイスを最後に購読を登録した日時の新しい順に返す
+func (s *NotificationService) ListDevices(ctx context.Context, userID string) ([]model.NotificationDevice, error) {
+	devices, err := s.notificationRepo.ListDevices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(devices, func(a, b model.NotificationDevice) int {
+		return cmp.Or(b.LastSeenAt.Compare(a.LastSeenAt), cmp.Compare(a.ID, b.ID))
+	})
+	return devices, nil
+}
+
+// DeleteDevice は通知先のデバイスを削除し、以降そのデバイスには通知を送らない
+func (s *NotificationService) DeleteDevice(ctx context.Context, userID, deviceID string) error {
+	return s.notificationRepo.DeleteDevice(ctx, userID, deviceID)
+}
+
+// ListHistory は期間（YYYY-MM-DD形式、空の場合は制限なし）とページングを指定して送信履歴を新しい順に返す
+// 日付の境界はユーザーのタイムゾーンで判定する
+func (s *NotificationService) ListHistory(
+	ctx context.Context, userID, from, to string, limit int, cursor string,
+) ([]model.NotificationLog, string, error) {
+	loc, err := s.profileService.GetLocation(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := repository.NotificationLogQuery{Limit: limit, Cursor: cursor}
+	if from != "" {
+		if query.From, err = time.ParseInLocation("2006-01-02", from, loc); err != nil {
+			return nil, "", err
+		}
+	}
+	if to != "" {
+		end, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return nil, "", err
+		}
+		// 終了日の翌日0時より前の履歴を含める
+		query.To = end.AddDate(0, 0, 1)
+	}
+
+	return s.notificationRepo.ListNotificationLogs(ctx, userID, query)
+}
+
+func toWeekdays(weekdays []int) []time.Weekday {
+	result := make([]time.Weekday, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		result = append(result, time.Weekday(weekday))
+	}
+	return result
+}
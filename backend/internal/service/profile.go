@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/config"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ProfileService struct {
+	profileRepo repository.ProfileRepository
+}
+
+func NewProfileService(profileRepo repository.ProfileRepository) *ProfileService {
+	return &ProfileService{
+		profileRepo: profileRepo,
+	}
+}
+
+// GetProfile はユーザーのプロフィールを取得する
+// 未登録の場合はデフォルトのタイムゾーンを設定したプロフィールを返し、isDefaultにtrueを返す
+func (s *ProfileService) GetProfile(ctx context.Context, userID string) (model.UserProfile, bool, error) {
+	profile, err := s.profileRepo.GetProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProfileNotFound) {
+			return model.UserProfile{Timezone: config.GetDefaultTimezone()}, true, nil
+		}
+		return model.UserProfile{}, false, err
+	}
+	if profile.Timezone == "" {
+		profile.Timezone = config.GetDefaultTimezone()
+	}
+	return *profile, false, nil
+}
+
+// GetLocation はユーザーのタイムゾーンを返す
+// 日付（服用日・連続日数・休薬期間）の境界はすべてこのタイムゾーンで判定する
+func (s *ProfileService) GetLocation(ctx context.Context, userID string) (*time.Location, error) {
+	profile, _, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(profile.Timezone)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("user_id", userID).
+			Str("timezone", profile.Timezone).
+			Msg("タイムゾーンを読み込めないためUTCで計算します")
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// ValidateTimezone はIANAタイムゾーン名として有効かどうかを検証する
+func ValidateTimezone(timezone string) error {
+	// 空文字と"Local"はLoadLocationで読み込めるが、サーバーの設定に依存するため受け付けない
+	if timezone == "" || timezone == "Local" {
+		return fmt.Errorf("timezoneはIANAタイムゾーン名（例: Asia/Tokyo）で指定してください")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("不明なタイムゾーンです: %s", timezone)
+	}
+	return nil
+}
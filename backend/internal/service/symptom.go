@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-shared/clock"
+	"okusuri-shared/status"
+)
+
+type SymptomService struct {
+	symptomRepo       repository.SymptomRepository
+	medicationService *MedicationService
+	clock             clock.Clock
+}
+
+func NewSymptomService(
+	symptomRepo repository.SymptomRepository,
+	medicationService *MedicationService,
+	clk clock.Clock,
+) *SymptomService {
+	return &SymptomService{
+		symptomRepo:       symptomRepo,
+		medicationService: medicationService,
+		clock:             clk,
+	}
+}
+
+// Today はユーザーのタイムゾーンでの当日の日付（YYYY-MM-DD形式）を返す
+func (s *SymptomService) Today(ctx context.Context, userID string) (string, error) {
+	loc, err := s.medicationService.GetLocation(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.clock.Now().In(loc).Format("2006-01-02"), nil
+}
+
+// GetSymptomSummary は期間（YYYY-MM-DD形式、ユーザーのタイムゾーン）内の症状の頻度をフェーズごとに集計する
+// 各日のフェーズは服用記録から計算したその日のステータスに従い、当日より後の日の症状は集計しない
+func (s *SymptomService) GetSymptomSummary(ctx context.Context, userID, from, to string) (*dto.SymptomSummaryResponse, error) {
+	if to == "" {
+		today, err := s.Today(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		to = today
+	}
+	if from == "" {
+		// 最初に症状を記録した日から集計する（記録がない場合は終了日の1日だけ）
+		from = to
+		first, _, err := s.symptomRepo.ListSymptoms(ctx, userID, repository.SymptomQuery{To: to, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(first) > 0 {
+			from = first[0].Date
+		}
+	}
+
+	symptoms, _, err := s.symptomRepo.ListSymptoms(ctx, userID, repository.SymptomQuery{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.medicationService.GetTimeline(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeSymptoms(symptoms, days, from, to), nil
+}
+
+// symptomFrequency は症状の種類ごとの集計途中の値
+type symptomFrequency struct {
+	count          int
+	days           map[string]bool
+	totalIntensity int
+}
+
+// summarizeSymptoms は各日のフェーズに従って症状の頻度を集計する
+func summarizeSymptoms(symptoms []model.Symptom, days []status.Day, from, to string) *dto.SymptomSummaryResponse {
+	// 服用期間・休薬期間は記録がなくても常に返し、それ以外のフェーズは出現順に続ける
+	phases := []status.Phase{status.PhaseIntake, status.PhaseRest}
+	phaseDays := make(map[status.Phase]int)
+	phaseByDate := make(map[string]status.Phase, len(days))
+	for _, day := range days {
+		if _, ok := phaseDays[day.Phase]; !ok && day.Phase != status.PhaseIntake && day.Phase != status.PhaseRest {
+			phases = append(phases, day.Phase)
+		}
+		phaseDays[day.Phase]++
+		phaseByDate[day.Date.Format("2006-01-02")] = day.Phase
+	}
+
+	frequencies := make(map[status.Phase]map[string]*symptomFrequency)
+	for _, symptom := range symptoms {
+		phase, ok := phaseByDate[symptom.Date]
+		if !ok {
+			continue
+		}
+		if frequencies[phase] == nil {
+			frequencies[phase] = make(map[string]*symptomFrequency)
+		}
+		frequency := frequencies[phase][symptom.Type]
+		if frequency == nil {
+			frequency = &symptomFrequency{days: make(map[string]bool)}
+			frequencies[phase][symptom.Type] = frequency
+		}
+		frequency.count++
+		frequency.days[symptom.Date] = true
+		frequency.totalIntensity += symptom.Intensity
+	}
+
+	response := &dto.SymptomSummaryResponse{
+		From:   from,
+		To:     to,
+		Phases: make([]dto.SymptomPhaseSummaryResponse, 0, len(phases)),
+	}
+	for _, phase := range phases {
+		summary := dto.SymptomPhaseSummaryResponse{
+			Phase:    string(phase),
+			Days:     phaseDays[phase],
+			Symptoms: make([]dto.SymptomFrequencyResponse, 0),
+		}
+		for _, symptomType := range model.SymptomTypes() {
+			frequency, ok := frequencies[phase][symptomType]
+			if !ok {
+				continue
+			}
+			summary.Symptoms = append(summary.Symptoms, dto.SymptomFrequencyResponse{
+				Type:             symptomType,
+				Count:            frequency.count,
+				Days:             len(frequency.days),
+				AverageIntensity: roundToTenth(float64(frequency.totalIntensity) / float64(frequency.count)),
+			})
+		}
+		response.Phases = append(response.Phases, summary)
+	}
+	return response
+}
@@ -4,6 +4,12 @@ import (
 	"os"
 )
 
+// リポジトリの実装の種類
+const (
+	RepositoryBackendDynamoDB = "dynamodb"
+	RepositoryBackendMemory   = "memory"
+)
+
 // Environment はアプリケーションの環境変数を管理します
 type Environment struct {
 	// サーバー設定
@@ -15,6 +21,12 @@ type Environment struct {
 	// DynamoDB設定
 	DynamoDBTableName string
 
+	// リポジトリ設定（"dynamodb" または "memory"）
+	RepositoryBackend string
+
+	// プロフィール未設定のユーザーに適用するタイムゾーン（IANA形式）
+	DefaultTimezone string
+
 	// ログ設定
 	LogLevel string
 }
@@ -31,6 +43,12 @@ func Load() *Environment {
 		// DynamoDB設定
 		DynamoDBTableName: getEnv("DYNAMODB_TABLE_NAME", "okusuri-production-table"),
 
+		// リポジトリ設定
+		RepositoryBackend: getEnv("REPOSITORY_BACKEND", RepositoryBackendDynamoDB),
+
+		// タイムゾーン設定
+		DefaultTimezone: getEnv("DEFAULT_TIMEZONE", "Asia/Tokyo"),
+
 		// ログ設定
 		LogLevel: getEnv("LOG_LEVEL", "INFO"),
 	}
@@ -49,6 +67,16 @@ func GetDynamoDBTableName() string {
 	return Load().DynamoDBTableName
 }
 
+// GetRepositoryBackend はリポジトリの実装の種類を取得します
+func GetRepositoryBackend() string {
+	return Load().RepositoryBackend
+}
+
+// GetDefaultTimezone はプロフィール未設定のユーザーに適用するタイムゾーンを取得します
+func GetDefaultTimezone() string {
+	return Load().DefaultTimezone
+}
+
 // GetPort はサーバーポートを取得します
 func GetPort() string {
 	return Load().Port
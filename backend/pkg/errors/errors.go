@@ -78,6 +78,16 @@ func HandleNotFound(c *gin.Context, message string, err error, details ...string
 	HandleError(c, http.StatusNotFound, ErrCodeNotFound, message, err, details...)
 }
 
+// HandleConflict は409エラーを処理する
+func HandleConflict(c *gin.Context, message string, err error, details ...string) {
+	HandleError(c, http.StatusConflict, ErrCodeConflict, message, err, details...)
+}
+
+// HandleMedicationNotFound は服用記録が見つからない場合の404エラーを処理する
+func HandleMedicationNotFound(c *gin.Context, message string, err error, details ...string) {
+	HandleError(c, http.StatusNotFound, ErrCodeMedicationNotFound, message, err, details...)
+}
+
 // HandleInternalServerError は500エラーを処理する
 func HandleInternalServerError(c *gin.Context, message string, err error, details ...string) {
 	HandleError(c, http.StatusInternalServerError, ErrCodeInternalServer, message, err, details...)
@@ -0,0 +1,32 @@
+package helper
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// NewID は指定時刻をタイムスタンプに持つULIDを生成する
+// 同一ミリ秒内でも単調増加するため、ソートキーに埋め込んでも順序が保たれる
+func NewID(t time.Time) string {
+	return ulid.MustNew(ulid.Timestamp(t), ulid.DefaultEntropy()).String()
+}
+
+// IsValidID はULID形式のIDかどうかを判定する
+func IsValidID(id string) bool {
+	_, err := ulid.ParseStrict(id)
+	return err == nil
+}
+
+// IsLegacyID はULID導入前に採番した服用記録のID（登録時刻のUnixNanoを10進数にした19桁の数字）かどうかを判定する
+func IsLegacyID(id string) bool {
+	if len(id) != 19 {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
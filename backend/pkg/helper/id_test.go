@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewID(t *testing.T) {
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	ids := make([]string, 100)
+	for i := range ids {
+		ids[i] = NewID(now)
+	}
+
+	// 同一ミリ秒内でも生成順に並び、重複しない
+	assert.True(t, sort.StringsAreSorted(ids))
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		assert.False(t, seen[id], "IDが重複しています: %s", id)
+		seen[id] = true
+		assert.True(t, IsValidID(id))
+		assert.Equal(t, now, ulid.Time(ulid.MustParse(id).Time()).UTC())
+	}
+}
+
+func TestIsValidID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"ULID", "01K3WMQ9X3Z8Q4H6B3F2A1C0D1", true},
+		{"空文字", "", false},
+		{"ULID導入前の数値ID", "1756717200000000000", false},
+		{"桁数が足りない", "01K3WMQ9X3Z8Q4H6B3F2A1C0D", false},
+		{"使用できない文字を含む", "01K3WMQ9X3Z8Q4H6B3F2A1C0DU", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsValidID(tt.id))
+		})
+	}
+}
@@ -123,6 +123,7 @@ module "eventbridge" {
   lambda_function_arn  = module.lambda_notification.notification_function_arn
   lambda_function_name = module.lambda_notification.notification_function_name
   iam_role_arn         = module.iam.eventbridge_role_arn
+  schedule_expression  = var.notification_schedule
 }
 
 module "cloudwatch" {
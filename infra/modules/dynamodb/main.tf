@@ -20,6 +20,16 @@ resource "aws_dynamodb_table" "main" {
     type = "S"
   }
 
+  attribute {
+    name = "GSI1PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "GSI1SK"
+    type = "S"
+  }
+
   # GSI1: DateIndex（日付検索用）
   global_secondary_index {
     name            = "DateIndex"
@@ -28,6 +38,20 @@ resource "aws_dynamodb_table" "main" {
     projection_type = "ALL"
   }
 
+  # GSI1: 服用記録のID検索（MEDICATION#{id}）・リマインダーの時刻検索（REMINDER#{UTCのHH:MM}）
+  global_secondary_index {
+    name            = "GSI1"
+    hash_key        = "GSI1PK"
+    range_key       = "GSI1SK"
+    projection_type = "ALL"
+  }
+
+  # TTL（論理削除した服用記録・追いリマインダーの状態などを期限後に削除）
+  ttl {
+    attribute_name = "TTL"
+    enabled        = true
+  }
+
   # ポイントインタイムリカバリー（個人用のため無効化）
   point_in_time_recovery {
     enabled = false
@@ -1,4 +1,4 @@
-# EventBridge Scheduler（毎日09:00の通知Lambda発火は必須）
+# EventBridge Scheduler（5分ごとに通知Lambdaを発火し、その時刻にリマインダーを設定したユーザーへ送信する）
 resource "aws_scheduler_schedule" "notification" {
   name                = "${var.project}-${var.environment}-notification-schedule"
   group_name          = "default"
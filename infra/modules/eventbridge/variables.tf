@@ -17,7 +17,7 @@ variable "common_tags" {
 variable "schedule_expression" {
   description = "EventBridge schedule expression (cron or rate)"
   type        = string
-  default     = "cron(0 9 * * ? *)"  # 毎日9:00
+  default     = "cron(0/5 * * * ? *)"  # ユーザーごとのリマインダー時刻に合わせて5分の境界ごとに実行
 }
 
 variable "lambda_function_arn" {
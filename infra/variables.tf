@@ -90,9 +90,9 @@ variable "api_gateway_stage_name" {
 
 # EventBridge 設定
 variable "notification_schedule" {
-  description = "Notification schedule (cron expression)"
+  description = "Notification schedule (cron or rate expression)"
   type        = string
-  default     = "cron(0 22 * * ? *)"  # 毎日22:00（夜10時）
+  default     = "cron(0/5 * * * ? *)"  # リマインダー時刻のバケット幅（5分）の境界ごとに実行
 }
 
 # タグ設定
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// sentSKPrefix は送信済みの記録のソートキーの接頭辞（SENT#{slot}#{deviceKey}）
+	sentSKPrefix = "SENT#"
+	// sentRetention は送信済みの記録を保持する期間（経過後はTTLで削除される）
+	// 同じ枠の再実行や重複実行が起こりうる期間より十分長くする
+	sentRetention = 48 * time.Hour
+)
+
+// reminderSlot はリマインダーの送信枠（ユーザーのタイムゾーンでの日付とリマインダー時刻）を返す
+func reminderSlot(date, reminderTime string) string {
+	return date + "#" + reminderTime
+}
+
+// followUpSlot は追いリマインダーの送信枠（リマインダーの送信枠と何件目の追いリマインダーか）を返す
+func followUpSlot(e Escalation) string {
+	return reminderSlot(e.Date, e.Time) + "#" + strconv.Itoa(e.Step)
+}
+
+// deviceKey は送信済みの記録に使うデバイスの識別子を返す
+// デバイスの導入前のサブスクリプションはプラットフォームごとに1件のため、プラットフォームで識別する
+func deviceKey(device NotificationDevice) string {
+	if device.ID == "" {
+		return "NOTIFICATION#" + device.Platform
+	}
+	return deviceSK(device.ID)
+}
+
+func sentSK(slot string, device NotificationDevice) string {
+	return sentSKPrefix + slot + "#" + deviceKey(device)
+}
+
+// ClaimDelivery はデバイスへの送信枠の通知を送信済みとして記録する
+// 重複した実行や再実行で既に記録されている場合は書き込まずにfalseを返す
+func (r *Repository) ClaimDelivery(
+	ctx context.Context, device NotificationDevice, slot string, kind DeliveryKind, now time.Time,
+) (bool, error) {
+	ttl := now.Add(sentRetention).Unix()
+	item := OkusuriTable{
+		PK: userPK(device.UserID),
+		SK: sentSK(slot, device),
+		Data: map[string]interface{}{
+			"slot":     slot,
+			"platform": device.Platform,
+			"deviceId": device.ID,
+			"kind":     string(kind),
+			"sentAt":   now.UTC().Format(time.RFC3339),
+		},
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		UpdatedAt: now.UTC().Format(time.RFC3339),
+		TTL:       &ttl,
+	}
+	err := r.store.PutItemIfNotExists(ctx, item)
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("送信済みの記録エラー: %v", err)
+	}
+	return true, nil
+}
+
+// ReleaseDelivery は送信できなかった通知の送信済みの記録を削除し、再実行で送り直せるようにする
+func (r *Repository) ReleaseDelivery(ctx context.Context, device NotificationDevice, slot string) error {
+	if err := r.store.DeleteItem(ctx, userPK(device.UserID), sentSK(slot, device)); err != nil {
+		return fmt.Errorf("送信済みの記録の削除エラー: %v", err)
+	}
+	return nil
+}
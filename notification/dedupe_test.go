@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierDeduplicatesAcrossInvocations(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	first, store, push, clk := setupEscalationTest(t, now, EscalationPolicy{}, "user-a", "user-b")
+	recordSleeps(first.service)
+	push.script("/user-b",
+		pushResponse{status: http.StatusInternalServerError},
+		pushResponse{status: http.StatusInternalServerError},
+		pushResponse{status: http.StatusInternalServerError})
+
+	// 別のLambdaインスタンス（メモリを共有しない）が同じ枠で実行された場合を再現する
+	second := NewNotifier(first.repo, NewNotificationService(clk, nil), clk, EscalationPolicy{}, &recordingDeliveryLogger{}, 4)
+
+	result, err := first.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{SentCount: 1, FailedCount: 1}, result)
+
+	t.Run("送信済みの枠は別の実行では送らず、送れなかった枠だけを送り直す", func(t *testing.T) {
+		// 最初の実行が処理済みのバケットを記録する前に、別の実行がスケジュールを取得した場合を再現する
+		require.NoError(t, store.DeleteItem(context.Background(), reminderCursorPK, reminderCursorSK))
+		clk.Advance(time.Minute)
+		result, err := second.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{SentCount: 1, DuplicateCount: 1}, result)
+		assert.Equal(t, map[string]int{"/user-a": 1, "/user-b": 4}, push.counts())
+
+		records := second.deliveries.(*recordingDeliveryLogger).records
+		require.Len(t, records, 2)
+		outcomes := map[string]DeliveryOutcome{}
+		for _, record := range records {
+			outcomes[record.UserID] = record.Outcome
+		}
+		assert.Equal(t, map[string]DeliveryOutcome{"user-a": DeliverySkipped, "user-b": DeliverySent}, outcomes)
+	})
+
+	t.Run("送信済みの記録はTTL付きで保存される", func(t *testing.T) {
+		legacy := NotificationDevice{UserID: "user-a", Platform: "web"}
+		item, err := store.GetItem(context.Background(), userPK("user-a"), sentSK(reminderSlot("2025-09-01", "08:00"), legacy))
+		require.NoError(t, err)
+		require.NotNil(t, item.TTL)
+		assert.Equal(t, now.Add(sentRetention).Unix(), *item.TTL)
+		assert.Equal(t, string(DeliveryReminder), item.Data["kind"])
+	})
+
+	t.Run("翌日の同じ時刻は別の枠として送信する", func(t *testing.T) {
+		clk.Set(now.Add(24 * time.Hour))
+		result, err := first.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{SentCount: 2}, result)
+	})
+}
+
+func TestFollowUpSlotIsPerStep(t *testing.T) {
+	e := Escalation{UserID: "user-a", Date: "2025-09-01", Time: "08:00", Step: 1}
+	assert.Equal(t, "2025-09-01#08:00#1", followUpSlot(e))
+
+	e.Step = 2
+	assert.NotEqual(t, reminderSlot(e.Date, e.Time), followUpSlot(e))
+	assert.Equal(t, "SENT#2025-09-01#08:00#2#DEVICE#laptop",
+		sentSK(followUpSlot(e), NotificationDevice{UserID: "user-a", ID: "laptop", Platform: "web"}))
+}
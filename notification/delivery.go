@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"time"
+)
+
+// DeliveryOutcome は通知1件の送信結果
+type DeliveryOutcome string
+
+const (
+	DeliverySent    DeliveryOutcome = "sent"    // Pushサービスが受け付けた
+	DeliveryExpired DeliveryOutcome = "expired" // サブスクリプションが失効していた（404/410）
+	DeliveryFailed  DeliveryOutcome = "failed"  // 再試行しても送信できなかった、または再試行しないエラー
+	DeliverySkipped DeliveryOutcome = "skipped" // 重複した実行や再実行で送信済みだったため送信しなかった
+	DeliveryDryRun  DeliveryOutcome = "dry_run" // ローカル実行の--dry-runのため送信しなかった
+)
+
+// DeliveryKind は送信した通知の種類
+type DeliveryKind string
+
+const (
+	DeliveryReminder DeliveryKind = "reminder"  // リマインダー時刻の通知
+	DeliveryFollowUp DeliveryKind = "follow_up" // 未服用のユーザーへの追いリマインダー
+	DeliveryRefill   DeliveryKind = "refill"    // 手持ちの薬がなくなる前の補充のリマインダー
+)
+
+// Delivery はPushサービスへの送信結果
+type Delivery struct {
+	Outcome    DeliveryOutcome
+	StatusCode int           // 最後に受け取ったHTTPステータスコード（送信できなかった場合は0）
+	Attempts   int           // Pushサービスへのリクエスト回数
+	Reason     string        // 送信できなかった理由
+	Latency    time.Duration // 送信開始から結果が確定するまでの時間
+}
+
+// DeliveryRecord は配信ログに記録する通知1件の送信結果
+type DeliveryRecord struct {
+	UserID      string          `json:"userId"`
+	Platform    string          `json:"platform"`
+	DeviceID    string          `json:"deviceId,omitempty"` // 空の場合は通知設定に保存されたサブスクリプション
+	DeviceLabel string          `json:"deviceLabel,omitempty"`
+	Kind        DeliveryKind    `json:"kind"`
+	Outcome     DeliveryOutcome `json:"outcome"`
+	StatusCode  int             `json:"statusCode,omitempty"`
+	Attempts    int             `json:"attempts"`
+	Reason      string          `json:"reason,omitempty"`
+	PushHost    string          `json:"pushHost,omitempty"` // エンドポイント全体はトークンを含むためホストだけを記録する
+	Message     string          `json:"message"`
+	LatencyMs   int64           `json:"latencyMs"` // 送信開始から結果が確定するまでの時間（再試行の待機を含む）
+	At          time.Time       `json:"at"`
+}
+
+// DeliveryLogger は通知の送信結果を記録する
+type DeliveryLogger interface {
+	Record(ctx context.Context, record DeliveryRecord) error
+}
+
+// stdoutDeliveryLogger は送信結果を1行のJSONとして標準のログ（CloudWatch Logs）に出力する
+type stdoutDeliveryLogger struct{}
+
+func newStdoutDeliveryLogger() DeliveryLogger {
+	return stdoutDeliveryLogger{}
+}
+
+func (stdoutDeliveryLogger) Record(_ context.Context, record DeliveryRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	log.Printf("delivery %s", b)
+	return nil
+}
+
+// pushHost はサブスクリプションのエンドポイントのホストを返す
+func pushHost(subscription string) string {
+	var sub PushSubscription
+	if err := json.Unmarshal([]byte(subscription), &sub); err != nil {
+		return ""
+	}
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDeliveryLogger は送信結果をメモリに保持するDeliveryLogger
+type recordingDeliveryLogger struct {
+	mu      sync.Mutex
+	records []DeliveryRecord
+}
+
+func (l *recordingDeliveryLogger) Record(_ context.Context, record DeliveryRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+	return nil
+}
+
+// recordSleeps は再試行までの待機を行わずに待ち時間だけを記録するようにする
+func recordSleeps(service *NotificationService) *[]time.Duration {
+	var (
+		mu     sync.Mutex
+		delays []time.Duration
+	)
+	service.sleep = func(_ context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, d)
+		return nil
+	}
+	return &delays
+}
+
+func TestNotifierHandlesPushStatus(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	notifier, store, push, clk := setupEscalationTest(t, now, EscalationPolicy{},
+		"user-gone", "user-notfound", "user-unavailable", "user-throttled", "user-down", "user-rejected")
+	delays := recordSleeps(notifier.service)
+
+	push.script("/user-gone", pushResponse{status: http.StatusGone})
+	push.script("/user-notfound", pushResponse{status: http.StatusNotFound})
+	push.script("/user-unavailable",
+		pushResponse{status: http.StatusServiceUnavailable},
+		pushResponse{status: http.StatusServiceUnavailable})
+	push.script("/user-throttled", pushResponse{status: http.StatusTooManyRequests, retryAfter: "7"})
+	push.script("/user-down",
+		pushResponse{status: http.StatusInternalServerError},
+		pushResponse{status: http.StatusInternalServerError},
+		pushResponse{status: http.StatusInternalServerError})
+	push.script("/user-rejected", pushResponse{status: http.StatusBadRequest})
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, RunResult{SentCount: 2, FailedCount: 2, DisabledCount: 2}, result)
+	assert.Equal(t, map[string]int{
+		"/user-gone":        1,
+		"/user-notfound":    1,
+		"/user-unavailable": 3,
+		"/user-throttled":   2,
+		"/user-down":        3,
+		"/user-rejected":    1,
+	}, push.counts())
+	// ユーザーは並行して処理するため順序は問わない（down・unavailable: 1秒→2秒、throttled: 7秒）
+	assert.ElementsMatch(t, []time.Duration{time.Second, 2 * time.Second, 7 * time.Second, time.Second, 2 * time.Second}, *delays,
+		"Retry-Afterがない場合は指数的に待ち時間を延ばし、ある場合はその値だけ待つ")
+
+	t.Run("配信ログに全ての送信結果を記録する", func(t *testing.T) {
+		outcomes := make(map[string]DeliveryRecord)
+		for _, record := range notifier.deliveries.(*recordingDeliveryLogger).records {
+			outcomes[record.UserID] = record
+		}
+		require.Len(t, outcomes, 6)
+
+		assert.Equal(t, DeliveryExpired, outcomes["user-gone"].Outcome)
+		assert.Equal(t, http.StatusGone, outcomes["user-gone"].StatusCode)
+		assert.Equal(t, DeliveryExpired, outcomes["user-notfound"].Outcome)
+		assert.Equal(t, DeliverySent, outcomes["user-unavailable"].Outcome)
+		assert.Equal(t, 3, outcomes["user-unavailable"].Attempts)
+		assert.Equal(t, DeliverySent, outcomes["user-throttled"].Outcome)
+		assert.Equal(t, DeliveryFailed, outcomes["user-down"].Outcome)
+		assert.Equal(t, 3, outcomes["user-down"].Attempts)
+		assert.Equal(t, DeliveryFailed, outcomes["user-rejected"].Outcome)
+		assert.Equal(t, 1, outcomes["user-rejected"].Attempts, "再試行しないエラーは1回だけ送信する")
+
+		record := outcomes["user-gone"]
+		assert.Equal(t, DeliveryReminder, record.Kind)
+		assert.Equal(t, "web", record.Platform)
+		assert.Equal(t, push.Listener.Addr().String(), record.PushHost)
+	})
+
+	t.Run("デバイスの導入前のサブスクリプションが失効した場合は通知設定を無効化する", func(t *testing.T) {
+		for _, userID := range []string{"user-gone", "user-notfound"} {
+			item, err := store.GetItem(context.Background(), userPK(userID), notificationSK("web"))
+			require.NoError(t, err)
+			assert.Equal(t, false, item.Data["isEnabled"], userID)
+			assert.Contains(t, item.Data["disabledReason"], "subscription expired", userID)
+			assert.Equal(t, now.Format(time.RFC3339), item.Data["disabledAt"], userID)
+		}
+		for _, userID := range []string{"user-down", "user-rejected"} {
+			item, err := store.GetItem(context.Background(), userPK(userID), notificationSK("web"))
+			require.NoError(t, err)
+			assert.Equal(t, true, item.Data["isEnabled"], "一時的なエラーや拒否では無効化しない: %s", userID)
+		}
+	})
+
+	t.Run("無効化したデバイスには翌日以降送信しない", func(t *testing.T) {
+		clk.Advance(24 * time.Hour)
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{SentCount: 4}, result)
+		assert.Equal(t, 1, push.counts()["/user-gone"])
+		assert.Equal(t, 1, push.counts()["/user-notfound"])
+	})
+}
+
+func TestDisableDeviceKeepsResubscribedDevice(t *testing.T) {
+	store := &fakeStore{items: []OkusuriTable{
+		notificationItem("user-a", "web", true, `{"endpoint":"https://push.example.com/new"}`),
+	}}
+	repo := NewRepository(store, NewCognitoDirectory(&fakeCognito{}, "test-pool"), "Asia/Tokyo")
+
+	// 送信後にユーザーが新しいサブスクリプションを登録した場合は、古いサブスクリプションの失効で無効化しない
+	stale := NotificationDevice{
+		UserID:       "user-a",
+		Platform:     "web",
+		IsEnabled:    true,
+		Subscription: `{"endpoint":"https://push.example.com/old"}`,
+	}
+	disabled, err := repo.DisableDevice(context.Background(), stale, "subscription expired", time.Now())
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	settings, err := repo.GetNotificationSettings(context.Background(), "user-a")
+	require.NoError(t, err)
+	require.Len(t, settings, 1)
+	assert.True(t, settings[0].IsEnabled)
+}
+
+func TestSendNotificationStopsRetryingAtDeadline(t *testing.T) {
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	notifier, store, push, _ := setupEscalationTest(t, now, EscalationPolicy{}, "user-a")
+	delays := recordSleeps(notifier.service)
+	push.script("/user-a", pushResponse{status: http.StatusServiceUnavailable})
+
+	device := NotificationDevice{UserID: "user-a", Platform: "web", IsEnabled: true}
+	item, err := store.GetItem(context.Background(), userPK("user-a"), notificationSK("web"))
+	require.NoError(t, err)
+	device.Subscription = item.Data["subscription"].(string)
+
+	// 再試行までの待ち時間（1秒）より先にLambdaの実行期限が来る場合は待たずに失敗とする
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	delivery, err := notifier.service.SendNotificationWithDays(ctx, User{ID: "user-a"}, device, "お薬の時間です", 0)
+	require.Error(t, err)
+	assert.Equal(t, DeliveryFailed, delivery.Outcome)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.StatusCode)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Empty(t, *delays)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Mon, 01 Sep 2025 00:01:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("Sun, 31 Aug 2025 23:59:00 GMT", now), "過去の日時は待たない")
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"okusuri-shared/notifylog"
+)
+
+// dynamoDeliveryLogger は送信結果を送信履歴（NOTIFYLOG#）としてDynamoDBに保存するDeliveryLogger
+// バックエンドAPIの送信履歴（GET /api/notification/history）から参照する
+type dynamoDeliveryLogger struct {
+	store itemStore
+	// retention は送信履歴を保持する期間（経過後はTTLで削除される）
+	retention time.Duration
+}
+
+func newDynamoDeliveryLogger(store itemStore, retention time.Duration) DeliveryLogger {
+	return &dynamoDeliveryLogger{store: store, retention: retention}
+}
+
+func (l *dynamoDeliveryLogger) Record(ctx context.Context, record DeliveryRecord) error {
+	id, err := newLogID()
+	if err != nil {
+		return err
+	}
+
+	at := record.At.UTC()
+	ttl := at.Add(l.retention).Unix()
+	item := OkusuriTable{
+		PK: userPK(record.UserID),
+		SK: notifylog.SK(at, id),
+		Data: map[string]interface{}{
+			"kind":        string(record.Kind),
+			"outcome":     string(record.Outcome),
+			"platform":    record.Platform,
+			"deviceId":    record.DeviceID,
+			"deviceLabel": record.DeviceLabel,
+			"message":     record.Message,
+			"statusCode":  record.StatusCode,
+			"attempts":    record.Attempts,
+			"reason":      record.Reason,
+			"pushHost":    record.PushHost,
+			"latencyMs":   record.LatencyMs,
+			"sentAt":      at.Format(time.RFC3339Nano),
+		},
+		CreatedAt: at.Format(time.RFC3339),
+		UpdatedAt: at.Format(time.RFC3339),
+		TTL:       &ttl,
+	}
+	return l.store.PutItemIfNotExists(ctx, item)
+}
+
+// newLogID は同じ時刻の送信履歴を区別するランダムなIDを生成する
+func newLogID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// multiDeliveryLogger は送信結果を複数のDeliveryLoggerに記録する
+// 1つが失敗しても残りには記録し、エラーをまとめて返す
+type multiDeliveryLogger []DeliveryLogger
+
+func newMultiDeliveryLogger(loggers ...DeliveryLogger) DeliveryLogger {
+	return multiDeliveryLogger(loggers)
+}
+
+func (m multiDeliveryLogger) Record(ctx context.Context, record DeliveryRecord) error {
+	var errs []error
+	for _, logger := range m {
+		errs = append(errs, logger.Record(ctx, record))
+	}
+	return errors.Join(errs...)
+}
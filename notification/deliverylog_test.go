@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"okusuri-shared/notifylog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoDeliveryLoggerStoresHistory(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	notifier, store, push, _ := setupEscalationTest(t, now, EscalationPolicy{}, "user-a", "user-b")
+	recordSleeps(notifier.service)
+	push.script("/user-b", pushResponse{status: http.StatusBadRequest})
+	notifier.deliveries = newDynamoDeliveryLogger(store, 30*24*time.Hour)
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{SentCount: 1, FailedCount: 1}, result)
+
+	history := func(t *testing.T, userID string) []OkusuriTable {
+		t.Helper()
+		items, err := store.QueryBySKPrefix(context.Background(), userPK(userID), notifylog.SKPrefix)
+		require.NoError(t, err)
+		return items
+	}
+
+	sent := history(t, "user-a")
+	require.Len(t, sent, 1, "送信1件ごとに履歴を保存する")
+	at, _, ok := notifylog.Parse(sent[0].SK)
+	require.True(t, ok)
+	assert.True(t, at.Equal(now))
+	require.NotNil(t, sent[0].TTL)
+	assert.Equal(t, now.Add(30*24*time.Hour).Unix(), *sent[0].TTL, "保持期間の経過後にTTLで削除する")
+	assert.Equal(t, string(DeliverySent), sent[0].Data["outcome"])
+	assert.Equal(t, string(DeliveryReminder), sent[0].Data["kind"])
+	assert.Equal(t, "web", sent[0].Data["platform"])
+	assert.NotEmpty(t, sent[0].Data["message"])
+	assert.Equal(t, 1, sent[0].Data["attempts"])
+	assert.NotContains(t, sent[0].Data, "subscription", "サブスクリプションは履歴に保存しない")
+
+	failed := history(t, "user-b")
+	require.Len(t, failed, 1)
+	assert.Equal(t, string(DeliveryFailed), failed[0].Data["outcome"])
+	assert.Equal(t, http.StatusBadRequest, failed[0].Data["statusCode"])
+	assert.True(t, strings.HasPrefix(failed[0].Data["pushHost"].(string), "127.0.0.1:"))
+}
+
+// failingDeliveryLogger は常に失敗するDeliveryLogger
+type failingDeliveryLogger struct{}
+
+func (failingDeliveryLogger) Record(context.Context, DeliveryRecord) error {
+	return errors.New("unavailable")
+}
+
+func TestMultiDeliveryLoggerRecordsToAll(t *testing.T) {
+	recorder := &recordingDeliveryLogger{}
+	logger := newMultiDeliveryLogger(failingDeliveryLogger{}, recorder)
+
+	err := logger.Record(context.Background(), DeliveryRecord{UserID: "user-a", Outcome: DeliverySent})
+	assert.Error(t, err)
+	assert.Len(t, recorder.records, 1, "失敗したDeliveryLogger以外には記録する")
+}
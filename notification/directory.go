@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// profileSK はプロフィールのソートキー（ユーザーごとに1件）
+const profileSK = "PROFILE"
+
+// cognitoPageSize はListUsersの1回のリクエストで取得するユーザー数（Cognitoの上限）
+const cognitoPageSize = 60
+
+// UserDirectory は通知の送信先となるユーザーの一覧を提供する
+// 本番ではCognito、ローカル環境ではDynamoDBのプロフィールから取得する
+type UserDirectory interface {
+	// ListUsers は全ユーザーを取得する
+	ListUsers(ctx context.Context) ([]User, error)
+}
+
+// CognitoClient は通知処理が使用するCognito APIのクライアント
+type CognitoClient interface {
+	ListUsers(ctx context.Context, params *cognitoidentityprovider.ListUsersInput,
+		optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error)
+}
+
+// cognitoDirectory はCognitoのユーザープールからユーザーを取得するUserDirectoryの実装
+type cognitoDirectory struct {
+	client     CognitoClient
+	userPoolID string
+}
+
+func NewCognitoDirectory(client CognitoClient, userPoolID string) UserDirectory {
+	return &cognitoDirectory{client: client, userPoolID: userPoolID}
+}
+
+// ListUsers はPaginationTokenをたどってユーザープールの全ユーザーを取得する
+func (d *cognitoDirectory) ListUsers(ctx context.Context) ([]User, error) {
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: aws.String(d.userPoolID),
+		Limit:      aws.Int32(cognitoPageSize),
+	}
+
+	var users []User
+	for {
+		result, err := d.client.ListUsers(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("Cognitoユーザー取得エラー: %v", err)
+		}
+
+		for _, cognitoUser := range result.Users {
+			users = append(users, *unmarshalCognitoUser(cognitoUser))
+		}
+
+		if aws.ToString(result.PaginationToken) == "" {
+			return users, nil
+		}
+		input.PaginationToken = result.PaginationToken
+	}
+}
+
+// unmarshalCognitoUser はCognitoのユーザーを変換する
+// バックエンドAPIはIDトークンのsubをユーザーIDとして保存するため、Usernameではなくsub属性をIDに使う
+func unmarshalCognitoUser(cognitoUser types.UserType) *User {
+	user := &User{
+		ID:        aws.ToString(cognitoUser.Username),
+		CreatedAt: aws.ToTime(cognitoUser.UserCreateDate),
+		UpdatedAt: aws.ToTime(cognitoUser.UserLastModifiedDate),
+	}
+
+	// 属性から値を取得
+	for _, attr := range cognitoUser.Attributes {
+		switch aws.ToString(attr.Name) {
+		case "sub":
+			user.ID = aws.ToString(attr.Value)
+		case "name":
+			user.Name = aws.ToString(attr.Value)
+		case "email":
+			user.Email = aws.ToString(attr.Value)
+		case "email_verified":
+			user.EmailVerified = aws.ToString(attr.Value) == "true"
+		case "picture":
+			user.Image = attr.Value
+		}
+	}
+
+	return user
+}
+
+// dynamoDirectory はDynamoDBのプロフィール（SK: PROFILE）を持つユーザーを取得するUserDirectoryの実装
+// Cognitoを使わずにローカルのDynamoDBだけで通知処理を実行するために使う
+type dynamoDirectory struct {
+	store itemStore
+}
+
+func NewDynamoDirectory(store itemStore) UserDirectory {
+	return &dynamoDirectory{store: store}
+}
+
+// ListUsers はプロフィールを登録済みの全ユーザーを取得する（テーブル全体をスキャンする）
+func (d *dynamoDirectory) ListUsers(ctx context.Context) ([]User, error) {
+	results, err := d.store.ScanBySK(ctx, profileSK)
+	if err != nil {
+		return nil, fmt.Errorf("プロフィール一覧取得エラー: %v", err)
+	}
+
+	var users []User
+	for _, result := range results {
+		userID, ok := userIDFromPK(result.PK)
+		if !ok {
+			continue
+		}
+		createdAt, _ := time.Parse(time.RFC3339, result.CreatedAt)
+		updatedAt, _ := time.Parse(time.RFC3339, result.UpdatedAt)
+		users = append(users, User{
+			ID:            userID,
+			Name:          getStringValue(result.Data, "name", ""),
+			Email:         getStringValue(result.Data, "email", ""),
+			EmailVerified: getBoolValue(result.Data, "emailVerified", false),
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+		})
+	}
+
+	return users, nil
+}
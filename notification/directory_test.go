@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCognitoDirectoryPaginates(t *testing.T) {
+	// ListUsersの1ページ（60人）を超えるユーザー
+	cognito := &fakeCognito{}
+	for i := range 130 {
+		cognito.users = append(cognito.users, cognitoUser(fmt.Sprintf("user-%03d", i)))
+	}
+
+	users, err := NewCognitoDirectory(cognito, "test-pool").ListUsers(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, cognito.calls, "PaginationTokenがなくなるまで取得する")
+	require.Len(t, users, 130)
+	assert.Equal(t, "user-000", users[0].ID)
+	assert.Equal(t, "user-129", users[129].ID, "2ページ目以降のユーザーも含める")
+}
+
+func TestDynamoDirectoryReadsProfiles(t *testing.T) {
+	store := &fakeStore{items: []OkusuriTable{
+		{
+			PK:        userPK("user-a"),
+			SK:        profileSK,
+			Data:      map[string]interface{}{"timezone": "Asia/Tokyo", "name": "Alice", "email": "alice@example.com"},
+			CreatedAt: "2025-08-01T00:00:00Z",
+		},
+		{PK: userPK("user-b"), SK: profileSK, Data: map[string]interface{}{"timezone": "Europe/London"}},
+		// プロフィール以外のアイテムはユーザーとして扱わない
+		notificationItem("user-c", "web", true, `{}`),
+		{PK: "INVALID", SK: profileSK, Data: map[string]interface{}{}},
+	}}
+
+	users, err := NewDynamoDirectory(store).ListUsers(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, users, 2)
+	assert.Equal(t, "user-a", users[0].ID)
+	assert.Equal(t, "Alice", users[0].Name)
+	assert.Equal(t, "alice@example.com", users[0].Email)
+	assert.Equal(t, 2025, users[0].CreatedAt.Year())
+	assert.Equal(t, "user-b", users[1].ID)
+}
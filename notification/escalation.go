@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// escalationSKPrefix は追いリマインダーの状態のソートキーの接頭辞（ESCALATION#{date}#{HH:MM}）
+	escalationSKPrefix = "ESCALATION#"
+	// followUpGSI1PK は送信待ちの追いリマインダーをGSI1で検索するためのパーティションキー
+	// GSI1SKには次の送信日時（UTC、RFC3339）を設定し、現在時刻以前のものをまとめて取得する
+	followUpGSI1PK = "FOLLOWUP"
+	// escalationRetention は追いリマインダーの状態を打ち切り時刻から保持する期間（経過後はTTLで削除される）
+	escalationRetention = 48 * time.Hour
+)
+
+// EscalationPolicy は未服用のユーザーに送る追いリマインダーの設定
+type EscalationPolicy struct {
+	Intervals []time.Duration // 最初のリマインダーからの経過時間（昇順）
+	Cutoff    string          // この時刻（HH:MM、ユーザーのタイムゾーン）を過ぎたら送らない。空の場合はその日の終わり
+}
+
+// Escalation は1回のリマインダーに対する追いリマインダーの進捗（DynamoDBに保存する）
+type Escalation struct {
+	UserID      string
+	Date        string    // リマインダーを送った日（YYYY-MM-DD、ユーザーのタイムゾーン）
+	Time        string    // リマインダー時刻（HH:MM、ユーザーのタイムゾーン）
+	Platforms   []string  // リマインダーを送ったプラットフォーム
+	Step        int       // 送信済みの追いリマインダーの数
+	ScheduledAt time.Time // 最初のリマインダーの予定日時
+	NextAt      time.Time // 次の追いリマインダーの送信日時
+	CutoffAt    time.Time // 追いリマインダーを打ち切る日時
+	Done        bool      // 服用済み・打ち切り・全て送信済みのいずれかで完了した
+}
+
+// cutoffOn はリマインダーの予定日時と同じ日の打ち切り日時を返す
+func (p EscalationPolicy) cutoffOn(scheduledAt time.Time) time.Time {
+	y, m, d := scheduledAt.Date()
+	endOfDay := time.Date(y, m, d+1, 0, 0, 0, 0, scheduledAt.Location())
+	if p.Cutoff == "" {
+		return endOfDay
+	}
+	t, err := time.Parse("15:04", p.Cutoff)
+	if err != nil {
+		log.Printf("追いリマインダーの打ち切り時刻 %q を解析できないためその日の終わりまで送信します", p.Cutoff)
+		return endOfDay
+	}
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, scheduledAt.Location())
+}
+
+// nextAt はstep件目（0始まり）の追いリマインダーの送信日時を返す
+// 設定された回数を送り終えた場合や打ち切り日時を過ぎる場合はfalseを返す
+func (p EscalationPolicy) nextAt(e Escalation, step int) (time.Time, bool) {
+	if step >= len(p.Intervals) {
+		return time.Time{}, false
+	}
+	next := e.ScheduledAt.Add(p.Intervals[step])
+	if next.After(e.CutoffAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// start は最初のリマインダーを送った後の追いリマインダーの状態を作成する
+// 追いリマインダーを送る予定がない場合はfalseを返す
+func (p EscalationPolicy) start(userID, reminderTime string, scheduledAt time.Time, platforms []string) (Escalation, bool) {
+	e := Escalation{
+		UserID:      userID,
+		Date:        scheduledAt.Format("2006-01-02"),
+		Time:        reminderTime,
+		Platforms:   platforms,
+		ScheduledAt: scheduledAt,
+		CutoffAt:    p.cutoffOn(scheduledAt),
+	}
+	next, ok := p.nextAt(e, 0)
+	if !ok {
+		return Escalation{}, false
+	}
+	e.NextAt = next
+	return e, true
+}
+
+// advance は追いリマインダーを1件送った後の状態を返す
+func (p EscalationPolicy) advance(e Escalation) Escalation {
+	e.Step++
+	next, ok := p.nextAt(e, e.Step)
+	if !ok {
+		e.Done = true
+		e.NextAt = time.Time{}
+		return e
+	}
+	e.NextAt = next
+	return e
+}
+
+// finish は追いリマインダーを打ち切った状態を返す
+func (e Escalation) finish() Escalation {
+	e.Done = true
+	e.NextAt = time.Time{}
+	return e
+}
+
+// DynamoDBからユーザーの指定日（ユーザーのタイムゾーン）の服用記録があるかを確認
+func (r *Repository) HasMedicationOn(ctx context.Context, userID, date string) (bool, error) {
+	results, err := r.store.QueryBySKPrefix(ctx, userPK(userID), "MEDICATION#"+date+"#")
+	if err != nil {
+		return false, fmt.Errorf("服用記録取得エラー: %v", err)
+	}
+	for _, result := range results {
+		// 論理削除された記録は服用済みとみなさない
+		if result.DeletedAt == "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DynamoDBに追いリマインダーの状態を作成（同じリマインダーの状態が既にある場合は作成せずfalseを返す）
+func (r *Repository) CreateEscalation(ctx context.Context, e Escalation) (bool, error) {
+	err := r.store.PutItemIfNotExists(ctx, toEscalationItem(e))
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("追いリマインダーの状態作成エラー: %v", err)
+	}
+	return true, nil
+}
+
+// DynamoDBから送信日時を過ぎた追いリマインダーの状態を取得
+// 前回までの実行で送れなかったものも含めて取得するため、Lambdaの実行が遅れても送信漏れにならない
+func (r *Repository) GetDueEscalations(ctx context.Context, now time.Time) ([]Escalation, error) {
+	results, err := r.store.QueryByGSI1PKUpTo(ctx, followUpGSI1PK, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("追いリマインダーの状態取得エラー: %v", err)
+	}
+
+	escalations := make([]Escalation, 0, len(results))
+	for _, result := range results {
+		e, err := fromEscalationItem(result)
+		if err != nil {
+			log.Printf("追いリマインダーの状態 %s/%s を読み込めないためスキップします: %v", result.PK, result.SK, err)
+			continue
+		}
+		escalations = append(escalations, e)
+	}
+	return escalations, nil
+}
+
+// DynamoDBの追いリマインダーの状態を更新
+// 読み込んだ時点から進捗が変わっていない場合だけ更新し、他の実行が先に更新していた場合はfalseを返す
+func (r *Repository) UpdateEscalation(ctx context.Context, e Escalation, expectedStep int) (bool, error) {
+	err := r.store.PutItemIfDataEquals(ctx, toEscalationItem(e), "step", expectedStep)
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("追いリマインダーの状態更新エラー: %v", err)
+	}
+	return true, nil
+}
+
+func escalationSK(date, reminderTime string) string {
+	return escalationSKPrefix + date + "#" + reminderTime
+}
+
+func toEscalationItem(e Escalation) OkusuriTable {
+	platforms := make([]interface{}, 0, len(e.Platforms))
+	for _, platform := range e.Platforms {
+		platforms = append(platforms, platform)
+	}
+	ttl := e.CutoffAt.Add(escalationRetention).Unix()
+
+	item := OkusuriTable{
+		PK: userPK(e.UserID),
+		SK: escalationSK(e.Date, e.Time),
+		Data: map[string]interface{}{
+			"date":        e.Date,
+			"time":        e.Time,
+			"platforms":   platforms,
+			"step":        e.Step,
+			"scheduledAt": e.ScheduledAt.Format(time.RFC3339),
+			"cutoffAt":    e.CutoffAt.Format(time.RFC3339),
+			"done":        e.Done,
+		},
+		TTL: &ttl,
+	}
+	// 完了した状態はGSI1のキーを持たせず、送信待ちの検索対象から外す
+	if !e.Done {
+		item.GSI1PK = followUpGSI1PK
+		item.GSI1SK = e.NextAt.UTC().Format(time.RFC3339)
+		item.Data["nextAt"] = e.NextAt.Format(time.RFC3339)
+	}
+	return item
+}
+
+func fromEscalationItem(item OkusuriTable) (Escalation, error) {
+	userID, ok := userIDFromPK(item.PK)
+	if !ok {
+		return Escalation{}, errors.New("PKからユーザーIDを取得できません")
+	}
+	scheduledAt, err := parseTime(getStringValue(item.Data, "scheduledAt", ""))
+	if err != nil {
+		return Escalation{}, fmt.Errorf("scheduledAtが不正です: %w", err)
+	}
+	cutoffAt, err := parseTime(getStringValue(item.Data, "cutoffAt", ""))
+	if err != nil {
+		return Escalation{}, fmt.Errorf("cutoffAtが不正です: %w", err)
+	}
+
+	e := Escalation{
+		UserID:      userID,
+		Date:        getStringValue(item.Data, "date", ""),
+		Time:        getStringValue(item.Data, "time", ""),
+		Platforms:   getStringSliceValue(item.Data, "platforms"),
+		Step:        getIntValue(item.Data, "step", 0),
+		ScheduledAt: scheduledAt,
+		CutoffAt:    cutoffAt,
+		Done:        getBoolValue(item.Data, "done", false),
+	}
+	if !e.Done {
+		e.NextAt, err = parseTime(getStringValue(item.Data, "nextAt", ""))
+		if err != nil {
+			return Escalation{}, fmt.Errorf("nextAtが不正です: %w", err)
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"okusuri-shared/clock"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func medicationItem(userID, date string, deleted bool) OkusuriTable {
+	item := OkusuriTable{
+		PK: userPK(userID),
+		SK: "MEDICATION#" + date + "#01K00000000000000000000000",
+		Data: map[string]interface{}{
+			"hasBleeding": false,
+			"createdAt":   date + "T07:30:00+09:00",
+			"updatedAt":   date + "T07:30:00+09:00",
+		},
+	}
+	if deleted {
+		item.DeletedAt = date + "T07:40:00+09:00"
+	}
+	return item
+}
+
+// setupEscalationTest はユーザーごとに1台のデバイスと08:00（日本時間）のリマインダーを設定した通知処理を作成する
+func setupEscalationTest(t *testing.T, now time.Time, policy EscalationPolicy, userIDs ...string) (*Notifier, *fakeStore, *pushServer, *clock.Fixed) {
+	t.Helper()
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	t.Setenv("VAPID_PUBLIC_KEY", publicKey)
+	t.Setenv("VAPID_PRIVATE_KEY", privateKey)
+
+	push := newPushServer(t)
+	store := &fakeStore{}
+	cognito := &fakeCognito{}
+	for _, userID := range userIDs {
+		store.items = append(store.items,
+			notificationItem(userID, "web", true, subscriptionJSON(t, push.URL+"/"+userID)),
+			scheduleItem(t, userID, "web", "08:00", "Asia/Tokyo", now),
+		)
+		cognito.users = append(cognito.users, cognitoUser(userID))
+	}
+
+	clk := clock.NewFixed(now)
+	repo := NewRepository(store, NewCognitoDirectory(cognito, "test-pool"), "Asia/Tokyo")
+	return NewNotifier(repo, NewNotificationService(clk, nil), clk, policy, &recordingDeliveryLogger{}, 4), store, push, clk
+}
+
+func TestNotifierEscalation(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour, 3 * time.Hour}, Cutoff: "23:00"}
+	notifier, store, push, clk := setupEscalationTest(t, now, policy, "user-a", "user-b", "user-c")
+
+	// user-bは今日の服用を記録済み、user-cの記録は削除済み
+	store.items = append(store.items,
+		medicationItem("user-b", "2025-09-01", false),
+		medicationItem("user-c", "2025-09-01", true),
+	)
+
+	run := func(t *testing.T) RunResult {
+		t.Helper()
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("服用を記録済みのユーザーにはリマインダーを送らない", func(t *testing.T) {
+		result := run(t)
+		assert.Equal(t, RunResult{SentCount: 2, SkippedCount: 1}, result)
+		assert.Equal(t, map[string]int{"/user-a": 1, "/user-c": 1}, push.counts())
+	})
+
+	t.Run("1時間後に未記録のユーザーへ追いリマインダーを送る", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		assert.Equal(t, RunResult{FollowUpCount: 2}, run(t))
+		assert.Equal(t, map[string]int{"/user-a": 2, "/user-c": 2}, push.counts())
+	})
+
+	t.Run("再実行しても同じ追いリマインダーは送らない", func(t *testing.T) {
+		assert.Equal(t, RunResult{}, run(t))
+		assert.Equal(t, map[string]int{"/user-a": 2, "/user-c": 2}, push.counts())
+	})
+
+	t.Run("服用を記録すると以降の追いリマインダーは送らない", func(t *testing.T) {
+		store.items = append(store.items, medicationItem("user-a", "2025-09-01", false))
+
+		clk.Advance(2 * time.Hour)
+		assert.Equal(t, RunResult{FollowUpCount: 1}, run(t))
+		assert.Equal(t, map[string]int{"/user-a": 2, "/user-c": 3}, push.counts())
+
+		item, err := store.GetItem(context.Background(), userPK("user-a"), escalationSK("2025-09-01", "08:00"))
+		require.NoError(t, err)
+		assert.Equal(t, true, item.Data["done"])
+		assert.Empty(t, item.GSI1PK, "完了した状態は送信待ちの検索対象から外れる")
+	})
+
+	t.Run("全ての追いリマインダーを送ると終了する", func(t *testing.T) {
+		clk.Advance(6 * time.Hour)
+		assert.Equal(t, RunResult{}, run(t))
+	})
+}
+
+func TestNotifierSkipsMedicationLogWithInvalidTime(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour}, Cutoff: "23:00"}
+	notifier, store, push, _ := setupEscalationTest(t, now, policy, "user-a", "user-b")
+
+	// 両方のユーザーに日時が不正な記録があり、user-bだけが今日の服用を記録済み
+	broken := func(userID string) OkusuriTable {
+		item := medicationItem(userID, "2025-08-31", false)
+		item.Data["createdAt"] = "invalid"
+		return item
+	}
+	store.items = append(store.items,
+		broken("user-a"),
+		broken("user-b"),
+		medicationItem("user-b", "2025-08-30", false),
+		medicationItem("user-b", "2025-09-01", false),
+	)
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{SentCount: 1, SkippedCount: 1}, result, "不正な記録だけをスキップして判定を続ける")
+	assert.Equal(t, map[string]int{"/user-a": 1}, push.counts())
+
+	logs, err := notifier.repo.GetMedicationLogs(context.Background(), "user-b")
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+}
+
+func TestNotifierEscalationCatchUpAndCutoff(t *testing.T) {
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}, Cutoff: "10:30"}
+	notifier, store, push, clk := setupEscalationTest(t, now, policy, "user-a")
+
+	_, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	// 09:00・10:00の実行が行われなかった場合でも、送信日時を過ぎた追いリマインダーは1件にまとめて送る
+	clk.Advance(2*time.Hour + 10*time.Minute)
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{FollowUpCount: 1}, result)
+
+	// 11:00は打ち切り時刻（10:30）を過ぎるため送らない
+	clk.Advance(time.Hour)
+	result, err = notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{}, result)
+	assert.Equal(t, map[string]int{"/user-a": 2}, push.counts())
+
+	item, err := store.GetItem(context.Background(), userPK("user-a"), escalationSK("2025-09-01", "08:00"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, item.Data["step"])
+	assert.Equal(t, true, item.Data["done"])
+}
+
+func TestNotifierEscalationRetriesUnsentFollowUp(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour, 3 * time.Hour}, Cutoff: "23:00"}
+	notifier, store, push, clk := setupEscalationTest(t, now, policy, "user-a", "user-b")
+	// user-bは09:00にもリマインダーを設定しており、追いリマインダーと同じ実行で同じデバイスに送る
+	store.items = append(store.items, scheduleItem(t, "user-b", "web", "09:00", "Asia/Tokyo", now))
+
+	_, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	step := func(t *testing.T, userID string) interface{} {
+		t.Helper()
+		item, err := store.GetItem(context.Background(), userPK(userID), escalationSK("2025-09-01", "08:00"))
+		require.NoError(t, err)
+		return item.Data["step"]
+	}
+
+	clk.Advance(time.Hour) // 09:00
+	push.script("/user-a", pushResponse{status: http.StatusBadRequest})
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{SentCount: 1, FailedCount: 1}, result)
+	assert.Equal(t, 0, step(t, "user-a"), "送信に失敗した追いリマインダーは進捗を戻す")
+	assert.Equal(t, 0, step(t, "user-b"), "同じ実行でリマインダーを送ったため送らなかった追いリマインダーは進捗を戻す")
+
+	clk.Advance(5 * time.Minute) // 09:05
+	result, err = notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{FollowUpCount: 2}, result)
+	assert.Equal(t, map[string]int{"/user-a": 3, "/user-b": 3}, push.counts())
+	assert.Equal(t, 1, step(t, "user-a"))
+	assert.Equal(t, 1, step(t, "user-b"))
+}
+
+func TestEscalationPolicyStart(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	scheduledAt := time.Date(2025, 9, 1, 8, 0, 0, 0, tokyo)
+
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour, 3 * time.Hour}, Cutoff: "23:00"}
+	e, ok := policy.start("user-a", "08:00", scheduledAt, []string{"web"})
+	require.True(t, ok)
+	assert.Equal(t, "2025-09-01", e.Date)
+	assert.Equal(t, scheduledAt.Add(time.Hour), e.NextAt)
+	assert.Equal(t, time.Date(2025, 9, 1, 23, 0, 0, 0, tokyo), e.CutoffAt)
+
+	_, ok = EscalationPolicy{Intervals: []time.Duration{time.Hour}, Cutoff: "08:30"}.start("user-a", "08:00", scheduledAt, nil)
+	assert.False(t, ok, "最初の追いリマインダーが打ち切り時刻を過ぎる場合は作成しない")
+
+	_, ok = EscalationPolicy{}.start("user-a", "08:00", scheduledAt, nil)
+	assert.False(t, ok, "間隔が未設定の場合は追いリマインダーを送らない")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"okusuri-notification/pkg/config"
+	"okusuri-shared/clock"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// localOptions はローカル実行のコマンドライン引数
+type localOptions struct {
+	dryRun         bool
+	userID         string
+	now            time.Time // ゼロ値の場合は現在時刻
+	dynamoEndpoint string
+}
+
+// parseLocalOptions はローカル実行のコマンドライン引数を解析する
+func parseLocalOptions(args []string, output io.Writer) (localOptions, error) {
+	var (
+		opts localOptions
+		now  string
+	)
+	flags := flag.NewFlagSet("okusuri-notification", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Pushサービスに送信せず、DynamoDBにも書き込まずに送信内容をJSONで出力する")
+	flags.StringVar(&opts.userID, "user", "", "指定したユーザーIDだけを処理する")
+	flags.StringVar(&now, "now", "", "指定した日時（RFC3339形式、例: 2025-09-01T08:00:00+09:00）に実行したものとして処理する")
+	flags.StringVar(&opts.dynamoEndpoint, "dynamodb-endpoint", config.GetDynamoDBEndpoint(),
+		"接続するDynamoDBのエンドポイント（例: http://localhost:8000、既定はDYNAMODB_ENDPOINT）")
+	if err := flags.Parse(args); err != nil {
+		return localOptions{}, err
+	}
+	if flags.NArg() > 0 {
+		return localOptions{}, fmt.Errorf("不明な引数です: %v", flags.Args())
+	}
+
+	if now != "" {
+		t, err := time.Parse(time.RFC3339, now)
+		if err != nil {
+			return localOptions{}, fmt.Errorf("--nowはRFC3339形式で指定してください: %w", err)
+		}
+		opts.now = t
+	}
+	return opts, nil
+}
+
+// runLocal はLambdaを介さずに通知処理を1回実行し、結果をJSONで出力する
+func runLocal(ctx context.Context, args []string, stdout io.Writer) error {
+	opts, err := parseLocalOptions(args, stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("AWS設定エラー: %v", err)
+	}
+
+	var clk clock.Clock = clock.System()
+	if !opts.now.IsZero() {
+		clk = clock.NewFixed(opts.now)
+	}
+	var store itemStore = newDynamoStore(newDynamoDB(cfg, opts.dynamoEndpoint).Table(config.GetDynamoDBTableName()))
+
+	var (
+		service    *NotificationService
+		deliveries DeliveryLogger
+		planned    *dryRunDeliveryLogger
+	)
+	if opts.dryRun {
+		store = readOnlyStore{store}
+		service = newDryRunNotificationService(clk)
+		planned = &dryRunDeliveryLogger{}
+		deliveries = planned
+	} else {
+		service = NewNotificationService(clk, newHostRateLimiter(config.GetPushHostRateLimit()))
+		deliveries = newMultiDeliveryLogger(
+			newStdoutDeliveryLogger(),
+			newDynamoDeliveryLogger(store, config.GetNotificationLogRetention()),
+		)
+	}
+
+	notifier := newNotifierFromConfig(cfg, store, service, clk, deliveries)
+	notifier.targetUserID = opts.userID
+	// 指定した日時のバケットだけを処理し、Lambdaが記録した処理済みのバケットは使わない
+	notifier.currentBucketOnly = true
+
+	started := time.Now()
+	result, err := notifier.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := map[string]interface{}{
+		"dryRun": opts.dryRun,
+		"now":    clk.Now().Format(time.RFC3339),
+		"result": resultSummary(result, time.Since(started)),
+	}
+	if planned != nil {
+		output["deliveries"] = planned.sorted()
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// readOnlyStore は書き込みを行わないitemStore（ローカル実行の--dry-run）
+// 送信済みの記録の確認は実際の状態を反映するよう、同じキーのアイテムが存在する場合は書き込み済みとして扱う
+type readOnlyStore struct {
+	itemStore
+}
+
+func (s readOnlyStore) PutItemIfNotExists(ctx context.Context, item OkusuriTable) error {
+	_, err := s.GetItem(ctx, item.PK, item.SK)
+	switch {
+	case err == nil:
+		return errConditionFailed
+	case errors.Is(err, errItemNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+func (readOnlyStore) UpdateDataFieldsIfDataEquals(
+	context.Context, string, string, map[string]interface{}, string, interface{},
+) error {
+	return nil
+}
+
+func (readOnlyStore) PutItemIfDataEquals(context.Context, OkusuriTable, string, interface{}) error {
+	return nil
+}
+
+func (readOnlyStore) DeleteItem(context.Context, string, string) error {
+	return nil
+}
+
+// dryRunDeliveryLogger は送信する予定だった通知を保持するDeliveryLogger（ローカル実行の--dry-run）
+type dryRunDeliveryLogger struct {
+	mu      sync.Mutex
+	records []DeliveryRecord
+}
+
+func (l *dryRunDeliveryLogger) Record(_ context.Context, record DeliveryRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+	return nil
+}
+
+// sorted は並行して処理した順序によらないよう、ユーザー・通知の種類・プラットフォーム・デバイスの順に並べた送信予定を返す
+func (l *dryRunDeliveryLogger) sorted() []DeliveryRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]DeliveryRecord, len(l.records))
+	copy(records, l.records)
+	slices.SortFunc(records, func(a, b DeliveryRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.UserID, b.UserID),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Platform, b.Platform),
+			cmp.Compare(a.DeviceID, b.DeviceID),
+		)
+	})
+	return records
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocalOptions(t *testing.T) {
+	t.Setenv("DYNAMODB_ENDPOINT", "http://localhost:8000")
+
+	opts, err := parseLocalOptions([]string{"--dry-run", "--user", "user-a", "--now", "2025-09-01T08:00:00+09:00"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.True(t, opts.dryRun)
+	assert.Equal(t, "user-a", opts.userID)
+	assert.True(t, opts.now.Equal(time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "http://localhost:8000", opts.dynamoEndpoint, "エンドポイントの既定値は環境変数から取得する")
+
+	opts, err = parseLocalOptions([]string{"--dynamodb-endpoint", "http://127.0.0.1:4566"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.False(t, opts.dryRun)
+	assert.True(t, opts.now.IsZero(), "--now省略時は現在時刻で実行する")
+	assert.Equal(t, "http://127.0.0.1:4566", opts.dynamoEndpoint)
+
+	t.Run("不正な引数はエラーになる", func(t *testing.T) {
+		for _, args := range [][]string{{"--now", "2025-09-01 08:00"}, {"--unknown"}, {"extra"}} {
+			_, err := parseLocalOptions(args, &bytes.Buffer{})
+			assert.Error(t, err, strings.Join(args, " "))
+		}
+
+		_, err := parseLocalOptions([]string{"-h"}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, flag.ErrHelp)
+	})
+}
+
+func TestNotifierDryRun(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{Intervals: []time.Duration{time.Hour}, Cutoff: "23:00"}
+	base, store, push, clk := setupEscalationTest(t, now, policy, "user-a", "user-b")
+	itemCount := len(store.items)
+
+	planned := &dryRunDeliveryLogger{}
+	notifier := NewNotifier(NewRepository(readOnlyStore{store}, base.repo.users, "Asia/Tokyo"),
+		newDryRunNotificationService(clk), clk, policy, planned, 4)
+	notifier.targetUserID = "user-b"
+	notifier.currentBucketOnly = true
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, RunResult{SentCount: 1}, result)
+	assert.Empty(t, push.counts(), "Pushサービスには送信しない")
+	assert.Len(t, store.items, itemCount, "送信済みの記録や追いリマインダーの状態を書き込まない")
+
+	records := planned.sorted()
+	require.Len(t, records, 1, "指定したユーザーだけを処理する")
+	assert.Equal(t, "user-b", records[0].UserID)
+	assert.Equal(t, DeliveryDryRun, records[0].Outcome)
+	assert.Equal(t, DeliveryReminder, records[0].Kind)
+	assert.NotEmpty(t, records[0].Message)
+
+	t.Run("送信済みの枠は重複としてスキップする", func(t *testing.T) {
+		_, err := base.Run(context.Background())
+		require.NoError(t, err)
+
+		planned := &dryRunDeliveryLogger{}
+		notifier.deliveries = planned
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{DuplicateCount: 1}, result)
+		require.Len(t, planned.sorted(), 1)
+		assert.Equal(t, DeliverySkipped, planned.sorted()[0].Outcome)
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import "time"
+
+// DynamoDBテーブル構造（単一テーブル設計）
+type OkusuriTable struct {
+	PK        string                 `dynamo:"PK,hash"`                   // Partition Key
+	SK        string                 `dynamo:"SK,range"`                  // Sort Key
+	GSI1PK    string                 `dynamo:"GSI1PK,omitempty"`          // GSI1: リマインダーのバケット検索など
+	GSI1SK    string                 `dynamo:"GSI1SK,omitempty"`          // GSI1 Sort Key
+	Date      string                 `dynamo:"Date,index:DateIndex,hash"` // GSI1: 日付検索
+	Data      map[string]interface{} `dynamo:"Data"`                      // エンティティ固有のデータ
+	CreatedAt string                 `dynamo:"CreatedAt"`                 // 作成日時 (ISO8601)
+	UpdatedAt string                 `dynamo:"UpdatedAt"`                 // 更新日時 (ISO8601)
+	DeletedAt string                 `dynamo:"DeletedAt,omitempty"`       // 論理削除日時 (ISO8601)
+	TTL       *int64                 `dynamo:"TTL,omitempty"`             // TTL（必要に応じて）
+}
+
+// モデル定義（Cognitoから取得するユーザー情報）
+type User struct {
+	ID            string    `json:"id"` // Cognitoのsub（バックエンドAPIのユーザーIDと同じ値）
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	Image         *string   `json:"image"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// 通知設定（DynamoDBから取得）
+type NotificationSetting struct {
+	UserID       string `json:"userId"` // PK（USER#{cognitoUserId}）から取り出した所有ユーザーのID
+	Platform     string `json:"platform"`
+	IsEnabled    bool   `json:"isEnabled"`
+	Subscription string `json:"subscription"` // デバイスの導入前に保存されたサブスクリプション
+}
+
+// 通知先のデバイス（DynamoDBから取得）
+// 通知が有効なプラットフォームの全デバイスに送信する
+type NotificationDevice struct {
+	UserID       string `json:"userId"`
+	ID           string `json:"id"` // SK（DEVICE#{deviceId}）から取り出したID。空の場合は通知設定に保存されたサブスクリプション
+	Platform     string `json:"platform"`
+	Label        string `json:"label"`
+	IsEnabled    bool   `json:"isEnabled"`
+	Subscription string `json:"subscription"`
+}
+
+// リマインダーのスケジュール（DynamoDBのGSI1から取得）
+type ReminderSchedule struct {
+	UserID   string         `json:"userId"`
+	Platform string         `json:"platform"`
+	Time     string         `json:"time"`     // リマインダー時刻（HH:MM形式、ユーザーのタイムゾーン）
+	Weekdays []time.Weekday `json:"weekdays"` // 通知する曜日（空の場合は毎日）
+	Timezone string         `json:"timezone"`
+	DueAt    time.Time      `json:"dueAt"` // 送信対象になったバケットの開始時刻
+}
+
+// 服用履歴（DynamoDBから取得）
+type MedicationLog struct {
+	HasBleeding      bool      `json:"hasBleeding"`
+	BleedingSeverity string    `json:"bleedingSeverity"` // 出血の程度（程度を記録する前の記録は空）
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Push通知関連の構造体
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+type NotificationData struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"okusuri-shared/clock"
+	"okusuri-shared/reminder"
+)
+
+// RunResult は1回の通知処理の結果
+type RunResult struct {
+	SentCount     int // 送信したリマインダーの数（デバイスごとに数える）
+	FollowUpCount int // 送信した追いリマインダーの数（デバイスごとに数える）
+	RefillCount   int // 送信した補充のリマインダーの数（デバイスごとに数える）
+	SkippedCount  int // 今日の服用を記録済みのためリマインダーを送らなかったユーザーの数
+	FailedCount   int // 送信に失敗した通知の数
+	DisabledCount int // サブスクリプションの失効により無効化したデバイスの数
+	// DuplicateCount は重複した実行や再実行で既に送信済みだったため送らなかった通知の数
+	DuplicateCount int
+	// UnprocessedUserIDs はLambdaの実行期限が近づいたため処理しなかったユーザーのID
+	UnprocessedUserIDs []string
+}
+
+// Notifier はユーザーと通知設定を突き合わせて通知を送信する
+type Notifier struct {
+	repo       *Repository
+	service    *NotificationService
+	clock      clock.Clock
+	escalation EscalationPolicy
+	deliveries DeliveryLogger
+	// concurrency は同時に処理するユーザー数の上限
+	concurrency int
+	// targetUserID が空でない場合はこのユーザーだけを処理する（ローカル実行の--user）
+	targetUserID string
+	// currentBucketOnly がtrueの場合は最後に処理したバケットを使わず、現在時刻のバケットだけを処理する（ローカル実行）
+	currentBucketOnly bool
+	// refillTime は補充のリマインダーを送り始める時刻（HH:MM、ユーザーのタイムゾーン）。空の場合は時刻に関わらず送る
+	refillTime string
+}
+
+func NewNotifier(
+	repo *Repository, service *NotificationService, clk clock.Clock,
+	escalation EscalationPolicy, deliveries DeliveryLogger, concurrency int,
+) *Notifier {
+	return &Notifier{
+		repo:        repo,
+		service:     service,
+		clock:       clk,
+		escalation:  escalation,
+		deliveries:  deliveries,
+		concurrency: concurrency,
+	}
+}
+
+// Run は現在時刻にリマインダーを設定しているユーザーの、通知が有効なプラットフォームの全デバイスに通知を1件ずつ送信する
+// 今日の服用を記録済みのユーザーには送らず、未記録のユーザーには追いリマインダーを送る
+// 在庫を登録したユーザーのうち手持ちの薬がなくなる日が近いユーザーには、リマインダーとは別に補充のリマインダーを送る
+func (n *Notifier) Run(ctx context.Context) (RunResult, error) {
+	now := n.clock.Now()
+
+	// 最後に処理したバケットの次から現在のバケットまでで送信対象のスケジュールを取得（DynamoDBから）
+	// 実行時刻がずれて処理されなかったバケットも、次の実行で送信する
+	var cursor time.Time
+	if !n.currentBucketOnly {
+		var err error
+		cursor, err = n.repo.GetReminderCursor(ctx)
+		if err != nil {
+			log.Printf("%v（現在のバケットだけを処理します）", err)
+		}
+	}
+	buckets := reminder.PendingBuckets(cursor, now)
+	var schedules []ReminderSchedule
+	for _, bucket := range buckets {
+		bucketSchedules, err := n.repo.GetDueSchedules(ctx, bucket)
+		if err != nil {
+			log.Printf("スケジュール取得エラー: %v", err)
+			return RunResult{}, fmt.Errorf("スケジュール取得エラー: %v", err)
+		}
+		schedules = append(schedules, bucketSchedules...)
+	}
+	log.Printf("送信対象のスケジュール数: %d（バケット%d件）", len(schedules), len(buckets))
+
+	// 送信日時を過ぎた追いリマインダーを取得（DynamoDBから）
+	escalations, err := n.repo.GetDueEscalations(ctx, now)
+	if err != nil {
+		log.Printf("追いリマインダー取得エラー: %v", err)
+		return RunResult{}, fmt.Errorf("追いリマインダー取得エラー: %v", err)
+	}
+	log.Printf("送信対象の追いリマインダー数: %d", len(escalations))
+
+	// 補充のリマインダーを判定する在庫を取得（DynamoDBから）
+	// 取得できない場合もリマインダーは送り、補充のリマインダーは次の実行で判定する
+	inventoriesByUser, err := n.repo.GetInventoriesByUser(ctx)
+	if err != nil {
+		log.Printf("%v（補充のリマインダーは次の実行で判定します）", err)
+	}
+	for userID, inventories := range inventoriesByUser {
+		inventories = slices.DeleteFunc(inventories, func(i Inventory) bool { return !i.needsRefillCheck() })
+		if len(inventories) == 0 {
+			delete(inventoriesByUser, userID)
+			continue
+		}
+		inventoriesByUser[userID] = inventories
+	}
+	log.Printf("補充のリマインダーの判定対象の在庫があるユーザー数: %d", len(inventoriesByUser))
+
+	if n.targetUserID != "" {
+		schedules = slices.DeleteFunc(schedules, func(s ReminderSchedule) bool { return s.UserID != n.targetUserID })
+		escalations = slices.DeleteFunc(escalations, func(e Escalation) bool { return e.UserID != n.targetUserID })
+		maps.DeleteFunc(inventoriesByUser, func(userID string, _ []Inventory) bool { return userID != n.targetUserID })
+		log.Printf("ユーザーID: %s だけを処理します（スケジュール%d件・追いリマインダー%d件・在庫%d件）",
+			n.targetUserID, len(schedules), len(escalations), len(inventoriesByUser[n.targetUserID]))
+	}
+
+	if len(schedules) == 0 && len(escalations) == 0 && len(inventoriesByUser) == 0 {
+		n.saveReminderCursor(ctx, cursor, buckets)
+		return RunResult{}, nil
+	}
+
+	// ユーザー一覧を取得（UserDirectoryから）
+	users, err := n.repo.GetUsers(ctx)
+	if err != nil {
+		log.Printf("ユーザー取得エラー: %v", err)
+		return RunResult{}, fmt.Errorf("ユーザー取得エラー: %v", err)
+	}
+	log.Printf("取得したユーザー数: %d", len(users))
+
+	usersByID := make(map[string]User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// 実行期限が近づいたら新しいユーザーの処理を開始しない（処理中のユーザーは送信を終える）
+	admission, cancel := admissionContext(ctx)
+	defer cancel()
+	sentSubs := newSubscriptionSet()
+
+	log.Printf("----- 通知送信処理開始（並行数: %d） -----", n.concurrency)
+	result := n.sendReminders(ctx, admission, now, schedules, usersByID, sentSubs)
+	result.add(n.sendFollowUps(ctx, admission, now, escalations, usersByID, sentSubs))
+	result.add(n.sendRefillReminders(ctx, admission, now, inventoriesByUser, usersByID))
+	result.UnprocessedUserIDs = sortedUnique(result.UnprocessedUserIDs)
+	n.saveReminderCursor(ctx, cursor, buckets)
+	log.Printf("----- 通知送信処理完了: リマインダー%d件・追いリマインダー%d件・補充のリマインダー%d件送信、服用済み%d人、失敗%d件、無効化%d件、送信済み%d件 -----",
+		result.SentCount, result.FollowUpCount, result.RefillCount, result.SkippedCount, result.FailedCount, result.DisabledCount, result.DuplicateCount)
+	if len(result.UnprocessedUserIDs) > 0 {
+		log.Printf("実行期限が近づいたため %d 人のユーザーを処理しませんでした: %v",
+			len(result.UnprocessedUserIDs), result.UnprocessedUserIDs)
+	}
+
+	return result, nil
+}
+
+// saveReminderCursor は処理したバケットのうち最も新しいものを記録する
+// 記録する前に実行が中断した場合は次の実行で同じバケットを処理し直すが、送信済みの記録により重複して送ることはない
+func (n *Notifier) saveReminderCursor(ctx context.Context, cursor time.Time, buckets []time.Time) {
+	if n.currentBucketOnly || len(buckets) == 0 {
+		return
+	}
+	saved, err := n.repo.SaveReminderCursor(context.WithoutCancel(ctx), cursor, buckets[len(buckets)-1], n.clock.Now())
+	if err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	if !saved {
+		log.Printf("リマインダーの処理済みバケットは他の実行で更新済みのため記録しません")
+	}
+}
+
+// sendReminders はスケジュールに一致したユーザーにリマインダーを送信し、追いリマインダーの状態を作成する
+// ユーザーごとに並行して処理し、処理できなかったユーザーのIDを結果に含める
+func (n *Notifier) sendReminders(
+	ctx, admission context.Context, now time.Time, schedules []ReminderSchedule,
+	usersByID map[string]User, sentSubs *subscriptionSet,
+) RunResult {
+	// 送信対象のスケジュールをユーザーIDでまとめる
+	schedulesByUser := make(map[string][]ReminderSchedule)
+	for _, schedule := range schedules {
+		schedulesByUser[schedule.UserID] = append(schedulesByUser[schedule.UserID], schedule)
+	}
+	log.Printf("送信対象のユーザー数: %d", len(schedulesByUser))
+
+	return n.forEachUser(ctx, admission, sortedKeys(schedulesByUser), func(ctx context.Context, userID string, result *RunResult) {
+		n.sendReminder(ctx, now, userID, schedulesByUser[userID], usersByID, sentSubs, result)
+	})
+}
+
+// sendReminder はユーザーにリマインダーを送信し、追いリマインダーの状態を作成する
+func (n *Notifier) sendReminder(
+	ctx context.Context, now time.Time, userID string, userSchedules []ReminderSchedule,
+	usersByID map[string]User, sentSubs *subscriptionSet, result *RunResult,
+) {
+	user, ok := usersByID[userID]
+	if !ok {
+		log.Printf("ユーザーID: %s はユーザー一覧に存在しないためスキップします", userID)
+		return
+	}
+
+	loc, err := n.repo.GetUserLocation(ctx, userID)
+	if err != nil {
+		log.Printf("ユーザーID: %s のタイムゾーン取得エラー: %v", userID, err)
+		return
+	}
+
+	// 処理されなかったバケットのスケジュールを含む場合は、バケットごとに古い順に送る
+	for _, dueSchedules := range groupByDueAt(userSchedules) {
+		n.sendDueReminder(ctx, now, user, loc, dueSchedules, sentSubs, result)
+	}
+}
+
+// groupByDueAt はスケジュールを送信対象になったバケットごとに古い順にまとめる
+func groupByDueAt(schedules []ReminderSchedule) [][]ReminderSchedule {
+	sorted := slices.Clone(schedules)
+	slices.SortStableFunc(sorted, func(a, b ReminderSchedule) int { return a.DueAt.Compare(b.DueAt) })
+
+	var groups [][]ReminderSchedule
+	for i, schedule := range sorted {
+		if i == 0 || !schedule.DueAt.Equal(sorted[i-1].DueAt) {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], schedule)
+	}
+	return groups
+}
+
+// sendDueReminder は同じバケットで送信対象になったスケジュールのリマインダーを送信し、追いリマインダーの状態を作成する
+func (n *Notifier) sendDueReminder(
+	ctx context.Context, now time.Time, user User, loc *time.Location, userSchedules []ReminderSchedule,
+	sentSubs *subscriptionSet, result *RunResult,
+) {
+	userID := user.ID
+	// 遅れて処理したバケットでも、リマインダー時刻の日付を送信枠にする
+	today := userSchedules[0].DueAt.In(loc)
+
+	platforms := make(map[string]bool)
+	for _, schedule := range userSchedules {
+		platforms[schedule.Platform] = true
+	}
+
+	// 今日の服用を記録済みの場合はリマインダーを送らない
+	logged, err := n.repo.HasMedicationOn(ctx, userID, today.Format("2006-01-02"))
+	if err != nil {
+		log.Printf("ユーザーID: %s の服用記録確認エラー（リマインダーは送信します）: %v", userID, err)
+	}
+	if logged {
+		log.Printf("ユーザーID: %s は今日の服用を記録済みのためスキップします", userID)
+		result.SkippedCount++
+		return
+	}
+
+	// 同じバケットのリマインダーはまとめて1件送るため、最も早い時刻を送信枠とする
+	slotTime := userSchedules[0].Time
+	for _, schedule := range userSchedules[1:] {
+		slotTime = min(slotTime, schedule.Time)
+	}
+	slot := reminderSlot(today.Format("2006-01-02"), slotTime)
+
+	// 薬のステータスを取得してメッセージを生成
+	message := "お薬の時間です。忘れずに服用してください。"
+	consecutiveDays := 0
+	restPeriod := false
+
+	medicationStatus, statusErr := getMedicationStatus(ctx, n.repo, userID, now)
+	if statusErr == nil {
+		message = generateStatusBasedMessage(medicationStatus)
+		consecutiveDays = medicationStatus.CurrentStreak
+		restPeriod = medicationStatus.IsRestPeriod
+	} else {
+		log.Printf("ステータス計算エラー（既定のメッセージで送信します）: %v", statusErr)
+	}
+
+	sent := n.sendToDevices(ctx, DeliveryReminder, slot, user, platforms, message, consecutiveDays, sentSubs, result)
+	result.SentCount += len(sent)
+	if len(sent) == 0 || restPeriod {
+		// 休薬期間中は服用しないため追いリマインダーは送らない
+		return
+	}
+
+	// リマインダー時刻ごとに追いリマインダーの状態を作成する
+	started := make(map[string]bool)
+	for _, schedule := range userSchedules {
+		if started[schedule.Time] {
+			continue
+		}
+		started[schedule.Time] = true
+
+		clockTime, err := time.Parse("15:04", schedule.Time)
+		if err != nil {
+			continue
+		}
+		scheduledAt := time.Date(today.Year(), today.Month(), today.Day(), clockTime.Hour(), clockTime.Minute(), 0, 0, loc)
+		escalation, ok := n.escalation.start(userID, schedule.Time, scheduledAt, platformsOf(sent))
+		if !ok {
+			continue
+		}
+		if _, err := n.repo.CreateEscalation(ctx, escalation); err != nil {
+			log.Printf("ユーザーID: %s の追いリマインダーの状態作成エラー: %v", userID, err)
+		}
+	}
+}
+
+// sendFollowUps は送信日時を過ぎた追いリマインダーを、まだ服用を記録していないユーザーに送信する
+// ユーザーごとに並行して処理し（同じユーザーの追いリマインダーは順に送る）、処理できなかったユーザーのIDを結果に含める
+func (n *Notifier) sendFollowUps(
+	ctx, admission context.Context, now time.Time, escalations []Escalation,
+	usersByID map[string]User, sentSubs *subscriptionSet,
+) RunResult {
+	escalationsByUser := make(map[string][]Escalation)
+	for _, escalation := range escalations {
+		escalationsByUser[escalation.UserID] = append(escalationsByUser[escalation.UserID], escalation)
+	}
+
+	return n.forEachUser(ctx, admission, sortedKeys(escalationsByUser), func(ctx context.Context, userID string, result *RunResult) {
+		for _, escalation := range escalationsByUser[userID] {
+			n.sendFollowUp(ctx, now, escalation, usersByID, sentSubs, result)
+		}
+	})
+}
+
+// sendFollowUp は追いリマインダーを1件送信し、進捗を更新する
+func (n *Notifier) sendFollowUp(
+	ctx context.Context, now time.Time, escalation Escalation,
+	usersByID map[string]User, sentSubs *subscriptionSet, result *RunResult,
+) {
+	user, ok := usersByID[escalation.UserID]
+	if !ok {
+		log.Printf("ユーザーID: %s はユーザー一覧に存在しないため追いリマインダーを終了します", escalation.UserID)
+		n.finishEscalation(ctx, escalation)
+		return
+	}
+	if now.After(escalation.CutoffAt) {
+		log.Printf("ユーザーID: %s の追いリマインダーは打ち切り時刻を過ぎたため終了します", escalation.UserID)
+		n.finishEscalation(ctx, escalation)
+		return
+	}
+
+	logged, err := n.repo.HasMedicationOn(ctx, escalation.UserID, escalation.Date)
+	if err != nil {
+		log.Printf("ユーザーID: %s の服用記録確認エラー: %v", escalation.UserID, err)
+		return
+	}
+	if logged {
+		log.Printf("ユーザーID: %s は服用を記録したため追いリマインダーを終了します", escalation.UserID)
+		n.finishEscalation(ctx, escalation)
+		return
+	}
+
+	// 実行が遅れて複数の追いリマインダーの送信日時を過ぎている場合は、まとめて1件だけ送る
+	next := n.escalation.advance(escalation)
+	for !next.Done && !next.NextAt.After(now) {
+		next = n.escalation.advance(next)
+	}
+
+	// 送信前に進捗を更新し、同時に実行された他のLambdaや再実行で同じ追いリマインダーを重複して送らないようにする
+	claimed, err := n.repo.UpdateEscalation(ctx, next, escalation.Step)
+	if err != nil {
+		log.Printf("ユーザーID: %s の追いリマインダーの状態更新エラー: %v", escalation.UserID, err)
+		return
+	}
+	if !claimed {
+		log.Printf("ユーザーID: %s の追いリマインダーは他の実行で処理済みのためスキップします", escalation.UserID)
+		return
+	}
+
+	platforms := make(map[string]bool, len(escalation.Platforms))
+	for _, platform := range escalation.Platforms {
+		platforms[platform] = true
+	}
+	sent := n.sendToDevices(ctx, DeliveryFollowUp, followUpSlot(next), user, platforms,
+		generateFollowUpMessage(next.Step), 0, sentSubs, result)
+	result.FollowUpCount += len(sent)
+	if len(sent) == 0 {
+		// 1件も送れなかった場合（送信の失敗や、同じ実行で他の通知を送ったサブスクリプションだけだった場合）は
+		// 進捗を元に戻し、次の実行で同じ追いリマインダーを送り直せるようにする
+		if _, err := n.repo.UpdateEscalation(context.WithoutCancel(ctx), escalation, next.Step); err != nil {
+			log.Printf("ユーザーID: %s の追いリマインダーの状態を戻せませんでした: %v", escalation.UserID, err)
+		}
+	}
+}
+
+// sendToDevices は対象プラットフォームのうち通知が有効なものについて、全デバイスに通知を1件ずつ送信する
+// 送信前にデバイスと送信枠ごとの送信済みの記録をDynamoDBに書き込み、重複した実行や再実行では送らない
+// 送信結果は全て配信ログに記録し、サブスクリプションが失効していたデバイスは無効化する
+// 送信に成功したデバイスを返す
+func (n *Notifier) sendToDevices(
+	ctx context.Context, kind DeliveryKind, slot string, user User, platforms map[string]bool,
+	message string, consecutiveDays int, sentSubs *subscriptionSet, result *RunResult,
+) []NotificationDevice {
+	settings, err := n.repo.GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		log.Printf("ユーザーID: %s の通知設定取得エラー: %v", user.ID, err)
+		return nil
+	}
+	devices, err := n.repo.GetNotificationDevices(ctx, user.ID)
+	if err != nil {
+		log.Printf("ユーザーID: %s の通知先デバイス取得エラー: %v", user.ID, err)
+		return nil
+	}
+
+	var sent []NotificationDevice
+	for _, device := range deliveryTargets(settings, devices, platforms) {
+		if sentSubs.has(device.Subscription) {
+			continue
+		}
+
+		claimed, err := n.repo.ClaimDelivery(ctx, device, slot, kind, n.clock.Now())
+		if err != nil {
+			// 記録できない場合も通知が届かないよりは重複する方がよいため送信する
+			log.Printf("ユーザーID: %s (%s %s) の送信済みの記録エラー（通知は送信します）: %v", user.ID, device.Platform, device.ID, err)
+		} else if !claimed {
+			log.Printf("ユーザーID: %s (%s %s) の送信枠 %s は送信済みのためスキップします", user.ID, device.Platform, device.ID, slot)
+			n.recordDelivery(ctx, kind, device, message, Delivery{Outcome: DeliverySkipped, Reason: "送信済み"})
+			result.DuplicateCount++
+			continue
+		}
+
+		started := n.clock.Now()
+		delivery, sendErr := n.service.SendNotificationWithDays(ctx, user, device, message, consecutiveDays)
+		delivery.Latency = n.clock.Now().Sub(started)
+		n.recordDelivery(ctx, kind, device, message, delivery)
+
+		switch {
+		case errors.Is(sendErr, ErrSubscriptionExpired):
+			n.disableDevice(ctx, device, delivery.Reason, result)
+			continue
+		case sendErr != nil:
+			log.Printf("通知送信失敗: %v", sendErr)
+			result.FailedCount++
+			// 送れなかった通知は再実行で送り直せるよう送信済みの記録を取り消す
+			if claimed {
+				// 実行期限による中断で送れなかった場合も取り消せるよう、キャンセルされないコンテキストで削除する
+				if err := n.repo.ReleaseDelivery(context.WithoutCancel(ctx), device, slot); err != nil {
+					log.Printf("ユーザーID: %s (%s %s) の%v", user.ID, device.Platform, device.ID, err)
+				}
+			}
+			continue
+		}
+
+		sentSubs.add(device.Subscription)
+		sent = append(sent, device)
+		log.Printf("ユーザーID: %s (%s %s) への通知送信成功", user.ID, device.Platform, device.Label)
+	}
+	return sent
+}
+
+// deliveryTargets は通知設定が有効で対象に含まれるプラットフォームの、有効なデバイスをプラットフォーム順に返す
+// デバイスを1台も登録していないプラットフォームは、デバイスの導入前に通知設定に保存されたサブスクリプションに送信する
+func deliveryTargets(settings []NotificationSetting, devices []NotificationDevice, platforms map[string]bool) []NotificationDevice {
+	devicesByPlatform := make(map[string][]NotificationDevice)
+	for _, device := range devices {
+		devicesByPlatform[device.Platform] = append(devicesByPlatform[device.Platform], device)
+	}
+
+	var targets []NotificationDevice
+	for _, setting := range settings {
+		if !setting.IsEnabled || !platforms[setting.Platform] {
+			continue
+		}
+
+		registered, ok := devicesByPlatform[setting.Platform]
+		if !ok {
+			if setting.Subscription != "" {
+				targets = append(targets, NotificationDevice{
+					UserID:       setting.UserID,
+					Platform:     setting.Platform,
+					IsEnabled:    true,
+					Subscription: setting.Subscription,
+				})
+			}
+			continue
+		}
+		for _, device := range registered {
+			if device.IsEnabled && device.Subscription != "" {
+				targets = append(targets, device)
+			}
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool { return targets[i].Platform < targets[j].Platform })
+	return targets
+}
+
+// platformsOf はデバイスのプラットフォームを重複を除いて返す
+func platformsOf(devices []NotificationDevice) []string {
+	seen := make(map[string]bool)
+	var platforms []string
+	for _, device := range devices {
+		if !seen[device.Platform] {
+			seen[device.Platform] = true
+			platforms = append(platforms, device.Platform)
+		}
+	}
+	return platforms
+}
+
+// disableDevice は失効したサブスクリプションのデバイスを無効化し、以降の実行で送信しないようにする
+func (n *Notifier) disableDevice(ctx context.Context, device NotificationDevice, reason string, result *RunResult) {
+	disabled, err := n.repo.DisableDevice(ctx, device, "subscription expired ("+reason+")", n.clock.Now())
+	if err != nil {
+		log.Printf("ユーザーID: %s (%s %s) のデバイスの無効化エラー: %v", device.UserID, device.Platform, device.ID, err)
+		return
+	}
+	if !disabled {
+		log.Printf("ユーザーID: %s (%s %s) のサブスクリプションは登録し直されたため無効化しません", device.UserID, device.Platform, device.ID)
+		return
+	}
+	log.Printf("ユーザーID: %s (%s %s) のデバイスを無効化しました", device.UserID, device.Platform, device.ID)
+	result.DisabledCount++
+}
+
+// recordDelivery は通知1件の送信結果を配信ログに記録する
+func (n *Notifier) recordDelivery(
+	ctx context.Context, kind DeliveryKind, device NotificationDevice, message string, delivery Delivery,
+) {
+	record := DeliveryRecord{
+		UserID:      device.UserID,
+		Platform:    device.Platform,
+		DeviceID:    device.ID,
+		DeviceLabel: device.Label,
+		Kind:        kind,
+		Outcome:     delivery.Outcome,
+		StatusCode:  delivery.StatusCode,
+		Attempts:    delivery.Attempts,
+		Reason:      delivery.Reason,
+		PushHost:    pushHost(device.Subscription),
+		Message:     message,
+		LatencyMs:   delivery.Latency.Milliseconds(),
+		At:          n.clock.Now().UTC(),
+	}
+	if err := n.deliveries.Record(ctx, record); err != nil {
+		log.Printf("ユーザーID: %s (%s) の配信ログ記録エラー: %v", device.UserID, device.Platform, err)
+	}
+}
+
+func (n *Notifier) finishEscalation(ctx context.Context, escalation Escalation) {
+	if _, err := n.repo.UpdateEscalation(ctx, escalation.finish(), escalation.Step); err != nil {
+		log.Printf("ユーザーID: %s の追いリマインダーの状態更新エラー: %v", escalation.UserID, err)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
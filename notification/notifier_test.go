@@ -0,0 +1,591 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"okusuri-shared/clock"
+	"okusuri-shared/reminder"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCognito は固定のユーザー一覧をLimit件ずつ返すCognitoClient
+// PaginationTokenには次のページの先頭の位置を返す
+type fakeCognito struct {
+	users []types.UserType
+	calls int
+}
+
+func (f *fakeCognito) ListUsers(_ context.Context, params *cognitoidentityprovider.ListUsersInput,
+	_ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error) {
+	f.calls++
+
+	start := 0
+	if token := aws.ToString(params.PaginationToken); token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil || start > len(f.users) {
+			return nil, fmt.Errorf("invalid pagination token: %q", token)
+		}
+	}
+	end := min(start+int(aws.ToInt32(params.Limit)), len(f.users))
+	if params.Limit == nil {
+		end = len(f.users)
+	}
+
+	output := &cognitoidentityprovider.ListUsersOutput{Users: f.users[start:end]}
+	if end < len(f.users) {
+		output.PaginationToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+// fakeStore はインメモリのitemStore（並行して送信する通知処理から呼ばれるためロックする）
+type fakeStore struct {
+	mu    sync.Mutex
+	items []OkusuriTable
+}
+
+func (s *fakeStore) QueryByGSI1PK(_ context.Context, gsi1pk string) ([]OkusuriTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []OkusuriTable
+	for _, item := range s.items {
+		if item.GSI1PK == gsi1pk {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+func (s *fakeStore) QueryBySKPrefix(_ context.Context, pk, prefix string) ([]OkusuriTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []OkusuriTable
+	for _, item := range s.items {
+		if item.PK == pk && strings.HasPrefix(item.SK, prefix) {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+func (s *fakeStore) ScanBySK(_ context.Context, sk string) ([]OkusuriTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []OkusuriTable
+	for _, item := range s.items {
+		if item.SK == sk {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+func (s *fakeStore) QueryByGSI1PKUpTo(_ context.Context, gsi1pk, maxGSI1SK string) ([]OkusuriTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []OkusuriTable
+	for _, item := range s.items {
+		if item.GSI1PK == gsi1pk && item.GSI1SK <= maxGSI1SK {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+// find はPKとSKが一致するアイテムの位置を返す（ロックを取得してから呼ぶ）
+func (s *fakeStore) find(pk, sk string) int {
+	for i, item := range s.items {
+		if item.PK == pk && item.SK == sk {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *fakeStore) PutItemIfNotExists(_ context.Context, item OkusuriTable) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(item.PK, item.SK) >= 0 {
+		return errConditionFailed
+	}
+	s.items = append(s.items, item)
+	return nil
+}
+
+func (s *fakeStore) PutItemIfDataEquals(_ context.Context, item OkusuriTable, key string, expected interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(item.PK, item.SK)
+	if i < 0 || fmt.Sprint(s.items[i].Data[key]) != fmt.Sprint(expected) {
+		return errConditionFailed
+	}
+	s.items[i] = item
+	return nil
+}
+
+func (s *fakeStore) UpdateDataFieldsIfDataEquals(
+	_ context.Context, pk, sk string, fields map[string]interface{}, key string, expected interface{},
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(pk, sk)
+	if i < 0 || fmt.Sprint(s.items[i].Data[key]) != fmt.Sprint(expected) {
+		return errConditionFailed
+	}
+	for field, value := range fields {
+		s.items[i].Data[field] = value
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteItem(_ context.Context, pk, sk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.find(pk, sk); i >= 0 {
+		s.items = append(s.items[:i], s.items[i+1:]...)
+	}
+	return nil
+}
+
+func (s *fakeStore) GetItem(_ context.Context, pk, sk string) (OkusuriTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.find(pk, sk); i >= 0 {
+		return s.items[i], nil
+	}
+	return OkusuriTable{}, errItemNotFound
+}
+
+// pushResponse はPushサービスのモックが返す応答
+type pushResponse struct {
+	status     int
+	retryAfter string
+}
+
+// pushServer はデバイスごとの受信件数を数えるPushサービスのモック
+// scriptで設定した応答を順に返し、設定がなくなった後は201を返す
+type pushServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	received  map[string]int
+	responses map[string][]pushResponse
+}
+
+func newPushServer(t *testing.T) *pushServer {
+	t.Helper()
+
+	s := &pushServer{received: make(map[string]int), responses: make(map[string][]pushResponse)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.received[r.URL.Path]++
+		res := pushResponse{status: http.StatusCreated}
+		if scripted := s.responses[r.URL.Path]; len(scripted) > 0 {
+			res, s.responses[r.URL.Path] = scripted[0], scripted[1:]
+		}
+		s.mu.Unlock()
+
+		if res.retryAfter != "" {
+			w.Header().Set("Retry-After", res.retryAfter)
+		}
+		w.WriteHeader(res.status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// script はデバイスのエンドポイントへのリクエストに順に返す応答を設定する
+func (s *pushServer) script(path string, responses ...pushResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[path] = append(s.responses[path], responses...)
+}
+
+func (s *pushServer) counts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(s.received))
+	for path, n := range s.received {
+		counts[path] = n
+	}
+	return counts
+}
+
+// subscriptionJSON はPushサービスのモックに届くサブスクリプションを作成する
+func subscriptionJSON(t *testing.T, endpoint string) string {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+
+	var sub PushSubscription
+	sub.Endpoint = endpoint
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(auth)
+
+	b, err := json.Marshal(sub)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func cognitoUser(sub string) types.UserType {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return types.UserType{
+		// Google連携ユーザーのUsernameはsubとは異なる
+		Username:             aws.String("Google_" + sub),
+		UserCreateDate:       aws.Time(now),
+		UserLastModifiedDate: aws.Time(now),
+		Attributes: []types.AttributeType{
+			{Name: aws.String("sub"), Value: aws.String(sub)},
+			{Name: aws.String("email"), Value: aws.String(sub + "@example.com")},
+		},
+	}
+}
+
+func notificationItem(userID, platform string, enabled bool, subscription string) OkusuriTable {
+	return OkusuriTable{
+		PK: userPK(userID),
+		SK: "NOTIFICATION#" + platform,
+		Data: map[string]interface{}{
+			"platform":     platform,
+			"isEnabled":    enabled,
+			"subscription": subscription,
+		},
+	}
+}
+
+// deviceItem はバックエンドAPIと同じ形式で通知先のデバイスを作成する
+func deviceItem(userID, deviceID, platform, label string, enabled bool, subscription string) OkusuriTable {
+	return OkusuriTable{
+		PK: userPK(userID),
+		SK: deviceSK(deviceID),
+		Data: map[string]interface{}{
+			"platform":     platform,
+			"label":        label,
+			"isEnabled":    enabled,
+			"subscription": subscription,
+		},
+	}
+}
+
+// scheduleItem はバックエンドAPIと同じ形式でリマインダーのスケジュールを作成する
+func scheduleItem(t *testing.T, userID, platform, clockTime, timezone string, ref time.Time, weekdays ...int) OkusuriTable {
+	t.Helper()
+
+	loc, err := time.LoadLocation(timezone)
+	require.NoError(t, err)
+	bucket, err := reminder.UTCBucket(clockTime, loc, ref)
+	require.NoError(t, err)
+
+	days := make([]interface{}, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		days = append(days, float64(weekday))
+	}
+	return OkusuriTable{
+		PK:     userPK(userID),
+		SK:     "SCHEDULE#" + platform + "#" + clockTime,
+		GSI1PK: reminder.BucketKey(bucket),
+		GSI1SK: userPK(userID),
+		Data: map[string]interface{}{
+			"platform": platform,
+			"time":     clockTime,
+			"weekdays": days,
+			"timezone": timezone,
+			"bucket":   bucket,
+		},
+	}
+}
+
+func TestNotifierRunSendsOnePushPerEnabledDevice(t *testing.T) {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	t.Setenv("VAPID_PUBLIC_KEY", publicKey)
+	t.Setenv("VAPID_PRIVATE_KEY", privateKey)
+
+	push := newPushServer(t)
+	device := func(name string) string {
+		return subscriptionJSON(t, push.URL+"/"+name)
+	}
+
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	schedule := func(userID, platform, clockTime string, weekdays ...int) OkusuriTable {
+		return scheduleItem(t, userID, platform, clockTime, "Asia/Tokyo", now, weekdays...)
+	}
+
+	store := &fakeStore{items: []OkusuriTable{
+		// 2台のデバイスで通知を有効にしているユーザー
+		notificationItem("user-a", "web", true, device("a-web")),
+		notificationItem("user-a", "ios", true, device("a-ios")),
+		schedule("user-a", "web", "08:00"),
+		schedule("user-a", "ios", "08:00", 1, 3, 5),
+		// 1台で有効、もう1台で無効にしているユーザー
+		notificationItem("user-b", "web", true, device("b-web")),
+		notificationItem("user-b", "ios", false, device("b-ios")),
+		schedule("user-b", "web", "08:00"),
+		schedule("user-b", "ios", "08:00"),
+		// 通知を無効にしているユーザー
+		notificationItem("user-c", "web", false, device("c-web")),
+		schedule("user-c", "web", "08:00"),
+		// 別の時刻にリマインダーを設定しているユーザー
+		notificationItem("user-e", "web", true, device("e-web")),
+		schedule("user-e", "web", "21:00"),
+		// 月曜日以外にリマインダーを設定しているユーザー
+		notificationItem("user-f", "web", true, device("f-web")),
+		schedule("user-f", "web", "08:00", 0, 6),
+		// Cognitoに存在しないユーザーの通知設定
+		notificationItem("user-deleted", "web", true, device("deleted-web")),
+		schedule("user-deleted", "web", "08:00"),
+	}}
+	cognito := &fakeCognito{users: []types.UserType{
+		cognitoUser("user-a"),
+		cognitoUser("user-b"),
+		cognitoUser("user-c"),
+		// 通知設定を持たないユーザー
+		cognitoUser("user-d"),
+		cognitoUser("user-e"),
+		cognitoUser("user-f"),
+	}}
+
+	clk := clock.NewFixed(now)
+	repo := NewRepository(store, NewCognitoDirectory(cognito, "test-pool"), "Asia/Tokyo")
+	notifier := NewNotifier(repo, NewNotificationService(clk, nil), clk, EscalationPolicy{}, &recordingDeliveryLogger{}, 4)
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.SentCount)
+	assert.Equal(t, map[string]int{"/a-web": 1, "/a-ios": 1, "/b-web": 1}, push.counts())
+
+	t.Run("別の時刻のユーザーはそのバケットで送信される", func(t *testing.T) {
+		clk.Set(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.SentCount)
+		assert.Equal(t, 1, push.counts()["/e-web"])
+	})
+}
+
+func TestNotifierRunFansOutToAllDevices(t *testing.T) {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	t.Setenv("VAPID_PUBLIC_KEY", publicKey)
+	t.Setenv("VAPID_PRIVATE_KEY", privateKey)
+
+	push := newPushServer(t)
+	device := func(name string) string {
+		return subscriptionJSON(t, push.URL+"/"+name)
+	}
+
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	store := &fakeStore{items: []OkusuriTable{
+		// 同じプラットフォームで複数のデバイスを登録しているユーザー
+		// デバイスを登録済みのため、通知設定に残っている古いサブスクリプションには送らない
+		notificationItem("user-a", "web", true, device("a-legacy")),
+		deviceItem("user-a", "laptop", "web", "会社のPC", true, device("a-laptop")),
+		deviceItem("user-a", "phone", "web", "iPhone", true, device("a-phone")),
+		deviceItem("user-a", "old", "web", "古いPC", false, device("a-old")),
+		scheduleItem(t, "user-a", "web", "08:00", "Asia/Tokyo", now),
+		// 通知設定を無効にしているプラットフォームのデバイス
+		notificationItem("user-a", "ios", false, ""),
+		deviceItem("user-a", "ipad", "ios", "iPad", true, device("a-ipad")),
+		scheduleItem(t, "user-a", "ios", "08:00", "Asia/Tokyo", now),
+		// 1台のサブスクリプションが失効しているユーザー
+		notificationItem("user-b", "web", true, ""),
+		deviceItem("user-b", "laptop", "web", "PC", true, device("b-laptop")),
+		deviceItem("user-b", "phone", "web", "Android", true, device("b-phone")),
+		scheduleItem(t, "user-b", "web", "08:00", "Asia/Tokyo", now),
+	}}
+	cognito := &fakeCognito{users: []types.UserType{cognitoUser("user-a"), cognitoUser("user-b")}}
+	push.script("/b-laptop", pushResponse{status: http.StatusGone})
+
+	clk := clock.NewFixed(now)
+	repo := NewRepository(store, NewCognitoDirectory(cognito, "test-pool"), "Asia/Tokyo")
+	deliveries := &recordingDeliveryLogger{}
+	notifier := NewNotifier(repo, NewNotificationService(clk, nil), clk, EscalationPolicy{}, deliveries, 4)
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, RunResult{SentCount: 3, DisabledCount: 1}, result)
+	assert.Equal(t, map[string]int{"/a-laptop": 1, "/a-phone": 1, "/b-laptop": 1, "/b-phone": 1}, push.counts())
+
+	var deviceIDs []string
+	for _, record := range deliveries.records {
+		deviceIDs = append(deviceIDs, record.UserID+"/"+record.DeviceID)
+	}
+	sort.Strings(deviceIDs)
+	assert.Equal(t, []string{"user-a/laptop", "user-a/phone", "user-b/laptop", "user-b/phone"}, deviceIDs)
+
+	t.Run("失効したデバイスだけを無効化する", func(t *testing.T) {
+		expired, err := store.GetItem(context.Background(), userPK("user-b"), deviceSK("laptop"))
+		require.NoError(t, err)
+		assert.Equal(t, false, expired.Data["isEnabled"])
+
+		setting, err := store.GetItem(context.Background(), userPK("user-b"), notificationSK("web"))
+		require.NoError(t, err)
+		assert.Equal(t, true, setting.Data["isEnabled"], "同じプラットフォームの他のデバイスには引き続き送信する")
+
+		clk.Advance(24 * time.Hour)
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.SentCount)
+		assert.Equal(t, 1, push.counts()["/b-laptop"])
+	})
+}
+
+func TestNotifierRunCatchesUpSkippedBuckets(t *testing.T) {
+	// 2025-09-01（月）07:57 JST
+	now := time.Date(2025, 8, 31, 22, 57, 0, 0, time.UTC)
+	notifier, store, push, clk := setupEscalationTest(t, now, EscalationPolicy{}, "user-a", "user-night")
+	store.items = append(store.items, scheduleItem(t, "user-night", "web", "23:55", "Asia/Tokyo", now))
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{}, result)
+
+	t.Run("実行時刻がずれて処理されなかったバケットを次の実行で送る", func(t *testing.T) {
+		clk.Set(now.Add(9*time.Minute + 10*time.Second)) // 08:06:10
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{SentCount: 2}, result)
+
+		clk.Set(now.Add(13 * time.Minute)) // 08:10
+		result, err = notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{}, result, "処理済みのバケットは処理し直さない")
+		assert.Equal(t, map[string]int{"/user-a": 1, "/user-night": 1}, push.counts())
+	})
+
+	t.Run("日付をまたいで遅れた場合もリマインダー時刻の日付を送信枠にする", func(t *testing.T) {
+		clk.Set(time.Date(2025, 9, 1, 14, 50, 0, 0, time.UTC)) // 23:50
+		_, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+
+		clk.Set(time.Date(2025, 9, 1, 15, 1, 0, 0, time.UTC)) // 9/2 00:01
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{SentCount: 1}, result)
+
+		legacy := NotificationDevice{UserID: "user-night", Platform: "web"}
+		_, err = store.GetItem(context.Background(), userPK("user-night"), sentSK(reminderSlot("2025-09-01", "23:55"), legacy))
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetDueSchedules(t *testing.T) {
+	// 冬時間（UTC-5）に保存したニューヨークの08:00のスケジュール
+	winter := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{items: []OkusuriTable{
+		scheduleItem(t, "user-ny", "web", "08:00", "America/New_York", winter),
+		scheduleItem(t, "user-tokyo", "web", "23:00", "Asia/Tokyo", winter),
+	}}
+	repo := NewRepository(store, NewCognitoDirectory(&fakeCognito{}, "test-pool"), "Asia/Tokyo")
+
+	dueUsers := func(t *testing.T, now time.Time) []string {
+		t.Helper()
+		schedules, err := repo.GetDueSchedules(context.Background(), now)
+		require.NoError(t, err)
+		var users []string
+		for _, schedule := range schedules {
+			users = append(users, schedule.UserID)
+		}
+		return users
+	}
+
+	assert.Equal(t, []string{"user-ny"}, dueUsers(t, time.Date(2025, 1, 20, 13, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"user-ny"}, dueUsers(t, time.Date(2025, 7, 14, 12, 4, 59, 0, time.UTC)),
+		"夏時間に切り替わっても現地の08:00に送信する")
+	assert.Empty(t, dueUsers(t, time.Date(2025, 7, 14, 13, 0, 0, 0, time.UTC)), "ニューヨークの09:00は送信対象外")
+	assert.Equal(t, []string{"user-tokyo"}, dueUsers(t, time.Date(2025, 7, 14, 14, 0, 0, 0, time.UTC)))
+}
+
+func TestGetNotificationSettingsKeepsUserID(t *testing.T) {
+	store := &fakeStore{items: []OkusuriTable{
+		notificationItem("user-a", "web", true, `{}`),
+		notificationItem("user-a", "ios", false, `{}`),
+		notificationItem("user-b", "ios", false, `{}`),
+		{PK: userPK("user-a"), SK: "REGIMEN", Data: map[string]interface{}{"type": "continuous"}},
+	}}
+	repo := NewRepository(store, NewCognitoDirectory(&fakeCognito{}, "test-pool"), "Asia/Tokyo")
+
+	settings, err := repo.GetNotificationSettings(context.Background(), "user-a")
+	require.NoError(t, err)
+
+	var owners []string
+	for _, setting := range settings {
+		owners = append(owners, setting.UserID+"/"+setting.Platform)
+	}
+	sort.Strings(owners)
+	assert.Equal(t, []string{"user-a/ios", "user-a/web"}, owners)
+}
+
+func TestGetMedicationStatusUsesBleedingSeverity(t *testing.T) {
+	// bleedingItem は指定した日に出血ありで服用した記録（severityが空の場合は程度を記録する前の記録）
+	bleedingItem := func(userID, date, severity string) OkusuriTable {
+		item := medicationItem(userID, date, false)
+		item.Data["hasBleeding"] = true
+		if severity != "" {
+			item.Data["bleedingSeverity"] = severity
+		}
+		return item
+	}
+	regimenItem := func(userID string) OkusuriTable {
+		return OkusuriTable{PK: userPK(userID), SK: "REGIMEN", Data: map[string]interface{}{
+			"type": "flexible_extended", "restPeriodDays": 4, "bleedingTriggerDays": 3, "bleedingThreshold": "light",
+		}}
+	}
+
+	var items []OkusuriTable
+	for _, userID := range []string{"user-spotting", "user-legacy"} {
+		items = append(items, regimenItem(userID))
+		for day := 1; day <= 10; day++ {
+			items = append(items, medicationItem(userID, time.Date(2025, 9, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), false))
+		}
+	}
+	for _, date := range []string{"2025-09-11", "2025-09-12", "2025-09-13"} {
+		items = append(items, bleedingItem("user-spotting", date, "spotting"), bleedingItem("user-legacy", date, ""))
+	}
+	repo := NewRepository(&fakeStore{items: items}, NewCognitoDirectory(&fakeCognito{}, "test-pool"), "Asia/Tokyo")
+	now := time.Date(2025, 9, 13, 12, 0, 0, 0, time.UTC)
+
+	result, err := getMedicationStatus(context.Background(), repo, "user-spotting", now)
+	require.NoError(t, err)
+	assert.False(t, result.IsRestPeriod, "しきい値未満の点状出血では休薬に入らない")
+
+	result, err = getMedicationStatus(context.Background(), repo, "user-legacy", now)
+	require.NoError(t, err)
+	assert.True(t, result.IsRestPeriod, "程度を記録する前の出血は中等量として数える")
+}
@@ -1,7 +1,17 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ユーザー一覧の取得元
+const (
+	UserDirectoryCognito  = "cognito"
+	UserDirectoryDynamoDB = "dynamodb"
 )
 
 // Environment はnotification層の環境変数を管理します
@@ -11,14 +21,37 @@ type Environment struct {
 
 	// DynamoDB設定
 	DynamoDBTableName string
+	// DynamoDBEndpoint はローカル実行で接続するエンドポイント（DynamoDB Localなど、空の場合はAWS）
+	DynamoDBEndpoint string
 
 	// Cognito設定
 	CognitoUserPoolID string
 
+	// ユーザー一覧の取得元（cognito: Cognitoのユーザープール、dynamodb: DynamoDBのプロフィール）
+	UserDirectory string
+
 	// Push通知設定
 	VAPIDPublicKey  string
 	VAPIDPrivateKey string
 
+	// プロフィール未設定のユーザーに適用するタイムゾーン（IANA形式）
+	DefaultTimezone string
+
+	// 未服用のユーザーへの追いリマインダー設定
+	// 最初のリマインダーからの経過時間（カンマ区切り、例: 1h,3h）と、それ以降は送らない時刻（HH:MM、ユーザーのタイムゾーン）
+	FollowUpIntervals  string
+	ReminderCutoffTime string
+
+	// 補充のリマインダーを送り始める時刻（HH:MM、ユーザーのタイムゾーン）
+	RefillReminderTime string
+
+	// 送信処理の並行数と、Pushサービスのホストごとの1秒あたりのリクエスト数の上限
+	Concurrency       string
+	PushHostRateLimit string
+
+	// 送信履歴（NOTIFYLOG#）を保持する日数
+	NotificationLogRetentionDays string
+
 	// ログ設定
 	LogLevel string
 }
@@ -31,14 +64,35 @@ func Load() *Environment {
 
 		// DynamoDB設定
 		DynamoDBTableName: getEnv("DYNAMODB_TABLE_NAME", "okusuri-production-table"),
+		DynamoDBEndpoint:  getEnv("DYNAMODB_ENDPOINT", ""),
 
 		// Cognito設定
 		CognitoUserPoolID: getEnv("COGNITO_USER_POOL_ID", ""),
 
+		// ユーザー一覧の取得元
+		UserDirectory: getEnv("USER_DIRECTORY", UserDirectoryCognito),
+
 		// Push通知設定
 		VAPIDPublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
 		VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
 
+		// タイムゾーン設定
+		DefaultTimezone: getEnv("DEFAULT_TIMEZONE", "Asia/Tokyo"),
+
+		// 追いリマインダー設定
+		FollowUpIntervals:  getEnv("REMINDER_FOLLOW_UP_INTERVALS", "1h,3h"),
+		ReminderCutoffTime: getEnv("REMINDER_CUTOFF_TIME", "23:00"),
+
+		// 補充のリマインダー設定
+		RefillReminderTime: getEnv("REFILL_REMINDER_TIME", "09:00"),
+
+		// 送信処理の並行数・レート制限
+		Concurrency:       getEnv("NOTIFICATION_CONCURRENCY", "8"),
+		PushHostRateLimit: getEnv("PUSH_HOST_RATE_LIMIT", "20"),
+
+		// 送信履歴の保持期間
+		NotificationLogRetentionDays: getEnv("NOTIFICATION_LOG_RETENTION_DAYS", "30"),
+
 		// ログ設定
 		LogLevel: getEnv("LOG_LEVEL", "INFO"),
 	}
@@ -57,11 +111,29 @@ func GetDynamoDBTableName() string {
 	return Load().DynamoDBTableName
 }
 
+// GetDynamoDBEndpoint はローカル実行で接続するDynamoDBのエンドポイントを取得します
+func GetDynamoDBEndpoint() string {
+	return Load().DynamoDBEndpoint
+}
+
 // GetCognitoUserPoolID はCognito User Pool IDを取得します
 func GetCognitoUserPoolID() string {
 	return Load().CognitoUserPoolID
 }
 
+// GetUserDirectory はユーザー一覧の取得元を取得します
+// 不明な値の場合はCognitoとします
+func GetUserDirectory() string {
+	value := Load().UserDirectory
+	switch value {
+	case UserDirectoryCognito, UserDirectoryDynamoDB:
+		return value
+	default:
+		log.Printf("ユーザー一覧の取得元 %q は不明なためCognitoを使用します", value)
+		return UserDirectoryCognito
+	}
+}
+
 // GetVAPIDPublicKey はVAPID公開鍵を取得します
 func GetVAPIDPublicKey() string {
 	return Load().VAPIDPublicKey
@@ -71,3 +143,77 @@ func GetVAPIDPublicKey() string {
 func GetVAPIDPrivateKey() string {
 	return Load().VAPIDPrivateKey
 }
+
+// GetDefaultTimezone はプロフィール未設定のユーザーに適用するタイムゾーンを取得します
+func GetDefaultTimezone() string {
+	return Load().DefaultTimezone
+}
+
+// GetFollowUpIntervals は最初のリマインダーから追いリマインダーを送るまでの経過時間を昇順で取得します
+// 解析できない値は無視します
+func GetFollowUpIntervals() []time.Duration {
+	var intervals []time.Duration
+	for _, value := range strings.Split(Load().FollowUpIntervals, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Printf("追いリマインダーの間隔 %q を解析できないため無視します", value)
+			continue
+		}
+		if len(intervals) > 0 && interval <= intervals[len(intervals)-1] {
+			log.Printf("追いリマインダーの間隔 %q は昇順ではないため無視します", value)
+			continue
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals
+}
+
+// GetReminderCutoffTime は追いリマインダーを送らなくなる時刻（HH:MM）を取得します
+func GetReminderCutoffTime() string {
+	return Load().ReminderCutoffTime
+}
+
+// GetRefillReminderTime は補充のリマインダーを送り始める時刻（HH:MM）を取得します
+func GetRefillReminderTime() string {
+	return Load().RefillReminderTime
+}
+
+// GetConcurrency は同時に処理するユーザー数の上限を取得します
+// 解析できない値や1未満の値の場合は1（逐次処理）とします
+func GetConcurrency() int {
+	value := Load().Concurrency
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("送信処理の並行数 %q を解析できないため1とします", value)
+		return 1
+	}
+	return n
+}
+
+// GetPushHostRateLimit はPushサービスのホストごとの1秒あたりのリクエスト数の上限を取得します
+// 0以下の場合は制限しません
+func GetPushHostRateLimit() float64 {
+	value := Load().PushHostRateLimit
+	limit, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Pushサービスのレート制限 %q を解析できないため制限しません", value)
+		return 0
+	}
+	return limit
+}
+
+// GetNotificationLogRetention は送信履歴を保持する期間を取得します
+// 解析できない値や1未満の値の場合は30日とします
+func GetNotificationLogRetention() time.Duration {
+	value := Load().NotificationLogRetentionDays
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		log.Printf("送信履歴の保持日数 %q を解析できないため30日とします", value)
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
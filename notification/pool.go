@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// deadlineMargin はLambdaの実行期限の手前で新しいユーザーの処理を打ち切る余裕
+// 処理中のユーザーの送信を終えて結果を返すまでの時間を残す
+const deadlineMargin = 3 * time.Second
+
+// add は別のユーザーの処理結果を集計する
+func (r *RunResult) add(other RunResult) {
+	r.SentCount += other.SentCount
+	r.FollowUpCount += other.FollowUpCount
+	r.RefillCount += other.RefillCount
+	r.SkippedCount += other.SkippedCount
+	r.FailedCount += other.FailedCount
+	r.DisabledCount += other.DisabledCount
+	r.DuplicateCount += other.DuplicateCount
+	r.UnprocessedUserIDs = append(r.UnprocessedUserIDs, other.UnprocessedUserIDs...)
+}
+
+// admissionContext はLambdaの実行期限のdeadlineMargin前に終了するコンテキストを返す
+// 新しいユーザーの処理を開始してよいかの判定にだけ使い、処理中の送信は元のコンテキストで続ける
+func admissionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-deadlineMargin))
+}
+
+// forEachUser はユーザーごとの処理を最大concurrency人ずつ並行して実行し、結果を集計する
+// admissionが終了した後は新しいユーザーの処理を開始せず、処理しなかったユーザーのIDを結果に含める
+func (n *Notifier) forEachUser(
+	ctx, admission context.Context, userIDs []string,
+	process func(ctx context.Context, userID string, result *RunResult),
+) RunResult {
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range userIDs {
+			select {
+			case <-admission.Done():
+				return
+			case jobs <- i:
+			}
+		}
+	}()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		result  RunResult
+		started = make([]bool, len(userIDs))
+	)
+	for range max(1, n.concurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if admission.Err() != nil {
+					continue
+				}
+				started[i] = true
+
+				var userResult RunResult
+				process(ctx, userIDs[i], &userResult)
+
+				mu.Lock()
+				result.add(userResult)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, userID := range userIDs {
+		if !started[i] {
+			result.UnprocessedUserIDs = append(result.UnprocessedUserIDs, userID)
+		}
+	}
+	return result
+}
+
+// subscriptionSet は1回の実行で送信済みのサブスクリプション（並行して参照・更新する）
+type subscriptionSet struct {
+	mu   sync.Mutex
+	subs map[string]bool
+}
+
+func newSubscriptionSet() *subscriptionSet {
+	return &subscriptionSet{subs: make(map[string]bool)}
+}
+
+func (s *subscriptionSet) has(subscription string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subs[subscription]
+}
+
+func (s *subscriptionSet) add(subscription string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[subscription] = true
+}
+
+// sortedUnique は重複を除いて昇順に並べたIDを返す
+func sortedUnique(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(ids))
+	var result []string
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
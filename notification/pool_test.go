@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachUserLimitsConcurrency(t *testing.T) {
+	notifier := &Notifier{concurrency: 2}
+	userIDs := []string{"user-a", "user-b", "user-c", "user-d", "user-e"}
+
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+		processed   []string
+	)
+	ctx := context.Background()
+	result := notifier.forEachUser(ctx, ctx, userIDs, func(_ context.Context, userID string, result *RunResult) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		processed = append(processed, userID)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+		result.SentCount++
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	assert.Equal(t, RunResult{SentCount: 5}, result, "ユーザーごとの結果を集計する")
+	assert.ElementsMatch(t, userIDs, processed)
+	assert.Equal(t, 2, maxInFlight, "同時に処理するユーザーは並行数までに制限する")
+}
+
+func TestForEachUserReportsUnprocessedUsers(t *testing.T) {
+	notifier := &Notifier{concurrency: 1}
+	ctx := context.Background()
+	admission, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := notifier.forEachUser(ctx, admission, []string{"user-a", "user-b", "user-c"},
+		func(_ context.Context, _ string, result *RunResult) {
+			// 最初のユーザーの処理中に実行期限が近づいた場合を再現する
+			cancel()
+			result.SentCount++
+		})
+
+	assert.Equal(t, 1, result.SentCount, "処理中のユーザーの送信は最後まで行う")
+	assert.Equal(t, []string{"user-b", "user-c"}, result.UnprocessedUserIDs)
+}
+
+func TestNotifierRunStopsNearDeadline(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	notifier, _, push, _ := setupEscalationTest(t, now, EscalationPolicy{}, "user-b", "user-a")
+
+	// 残り時間がdeadlineMarginより短い場合は新しいユーザーの処理を始めない
+	ctx, cancel := context.WithTimeout(context.Background(), deadlineMargin/2)
+	defer cancel()
+
+	result, err := notifier.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RunResult{UnprocessedUserIDs: []string{"user-a", "user-b"}}, result)
+	assert.Empty(t, push.counts())
+}
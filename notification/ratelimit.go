@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// hostRateLimiter はPushサービスのホスト（FCM・Mozilla・Appleなど）ごとにリクエストの頻度を制限する
+// 並行して送信しても1つのPushサービスに短時間に集中してレート制限（429）を受けないようにする
+type hostRateLimiter struct {
+	limit    rate.Limit
+	burst    int
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newHostRateLimiter はホストごとに1秒あたりperSecond件までリクエストを許可するレート制限を作成する
+// perSecondが0以下の場合は制限しない（nilを返す）
+func newHostRateLimiter(perSecond float64) *hostRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &hostRateLimiter{
+		limit:    rate.Limit(perSecond),
+		burst:    max(1, int(math.Ceil(perSecond))),
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Wait はホストへのリクエストが許可されるまで待機する
+// 待機するとコンテキストの期限を過ぎる場合は待たずにエラーを返す
+func (l *hostRateLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	limiter, ok := l.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[host] = limiter
+	}
+	l.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
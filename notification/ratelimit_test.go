@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostRateLimiter(t *testing.T) {
+	t.Run("0以下の場合は制限しない", func(t *testing.T) {
+		limiter := newHostRateLimiter(0)
+		assert.Nil(t, limiter)
+		assert.NoError(t, limiter.Wait(context.Background(), "fcm.googleapis.com"))
+	})
+
+	t.Run("ホストごとに上限を超えたリクエストを待機させる", func(t *testing.T) {
+		limiter := newHostRateLimiter(1)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, limiter.Wait(ctx, "fcm.googleapis.com"))
+		require.NoError(t, limiter.Wait(ctx, "updates.push.services.mozilla.com"), "別のホストは制限を共有しない")
+		assert.Error(t, limiter.Wait(ctx, "fcm.googleapis.com"), "待機すると期限を過ぎる場合はエラーにする")
+	})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// inventorySKPrefix は薬の在庫のソートキーの接頭辞（INVENTORY#{medicationId}、薬ごとに1件、バックエンドAPIが登録する）
+	inventorySKPrefix = "INVENTORY#"
+	// inventoryGSI1PK は在庫を登録したユーザーをGSI1で検索するためのパーティションキー（GSI1SKはユーザーのPK）
+	inventoryGSI1PK = "INVENTORY"
+	// defaultRefillReminderDays は補充のリマインダーの日数が未設定の在庫に適用する日数（バックエンドAPIと同じ）
+	defaultRefillReminderDays = 7
+)
+
+// Inventory は薬の在庫（DynamoDBから取得）
+type Inventory struct {
+	MedicationID       string // 薬のID
+	Name               string // 薬の名前
+	PillsPerDose       int    // 1回に服用する錠数
+	RemainingPills     int    // 手持ちの錠数
+	RefillReminderDays int    // 手持ちがなくなる何日前に補充のリマインダーを送るか（0の場合は送らない）
+	RefillReminderSent bool   // 在庫を登録してから補充のリマインダーを送ったかどうか
+}
+
+// mayRunOutWithin は手持ちの薬がdays日以内になくなる可能性があるかどうかを返す
+// 休薬期間を挟んでもなくなる日は服用できる回数の日数より前にならないため、超える場合はなくなる日を予測しない
+func (i Inventory) mayRunOutWithin(days int) bool {
+	return i.RemainingPills/max(1, i.PillsPerDose) <= days
+}
+
+// needsRefillCheck は補充のリマインダーを送るかどうか、なくなる日を予測して判定する必要があるかを返す
+func (i Inventory) needsRefillCheck() bool {
+	return i.RefillReminderDays > 0 && !i.RefillReminderSent && i.mayRunOutWithin(i.RefillReminderDays)
+}
+
+// refillSlot は補充のリマインダーの送信枠（ユーザーのタイムゾーンでの日付と薬のID）を返す
+func refillSlot(date, medicationID string) string {
+	return date + "#refill#" + medicationID
+}
+
+// DynamoDBから在庫を登録した全ユーザーの薬の在庫を取得し、ユーザーIDごとにまとめる
+// GSI1（GSI1PK: INVENTORY）で取得するため、リマインダーのスケジュールの有無に関わらず全ての在庫が対象になる
+func (r *Repository) GetInventoriesByUser(ctx context.Context) (map[string][]Inventory, error) {
+	results, err := r.store.QueryByGSI1PK(ctx, inventoryGSI1PK)
+	if err != nil {
+		return nil, fmt.Errorf("在庫取得エラー: %v", err)
+	}
+
+	inventoriesByUser := make(map[string][]Inventory)
+	for _, result := range results {
+		userID, ok := userIDFromPK(result.PK)
+		if !ok {
+			log.Printf("在庫 %s/%s のPKからユーザーIDを取得できないためスキップします", result.PK, result.SK)
+			continue
+		}
+		inventoriesByUser[userID] = append(inventoriesByUser[userID], Inventory{
+			MedicationID:       strings.TrimPrefix(result.SK, inventorySKPrefix),
+			Name:               getStringValue(result.Data, "name", ""),
+			PillsPerDose:       getIntValue(result.Data, "pillsPerDose", 1),
+			RemainingPills:     getIntValue(result.Data, "remainingPills", 0),
+			RefillReminderDays: getIntValue(result.Data, "refillReminderDays", defaultRefillReminderDays),
+			RefillReminderSent: getBoolValue(result.Data, "refillReminderSent", false),
+		})
+	}
+	return inventoriesByUser, nil
+}
+
+// SetRefillReminderSent は薬の在庫の補充のリマインダーの送信済みを更新する
+// 読み込んだ時点から送信済みの状態が変わっていない場合だけ更新し、他の実行が先に更新していた場合はfalseを返す
+func (r *Repository) SetRefillReminderSent(ctx context.Context, userID, medicationID string, sent bool, now time.Time) (bool, error) {
+	fields := map[string]interface{}{
+		"refillReminderSent": sent,
+		"updatedAt":          now.UTC().Format(time.RFC3339),
+	}
+	err := r.store.UpdateDataFieldsIfDataEquals(ctx, userPK(userID), inventorySKPrefix+medicationID,
+		fields, "refillReminderSent", !sent)
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("在庫の更新エラー: %v", err)
+	}
+	return true, nil
+}
+
+// sendRefillReminders は在庫を登録したユーザーに、手持ちの薬がなくなる日が近い薬の補充のリマインダーを送信する
+// リマインダーのスケジュールとは独立して実行のたびに判定し、ユーザーごとに並行して処理する
+func (n *Notifier) sendRefillReminders(
+	ctx, admission context.Context, now time.Time, inventoriesByUser map[string][]Inventory, usersByID map[string]User,
+) RunResult {
+	return n.forEachUser(ctx, admission, sortedKeys(inventoriesByUser), func(ctx context.Context, userID string, result *RunResult) {
+		n.sendUserRefillReminders(ctx, now, userID, inventoriesByUser[userID], usersByID, result)
+	})
+}
+
+// sendUserRefillReminders はユーザーのタイムゾーンで補充のリマインダーの時刻を過ぎていれば、薬ごとに補充のリマインダーを送信する
+// 通知設定が有効な全てのプラットフォームに送る
+func (n *Notifier) sendUserRefillReminders(
+	ctx context.Context, now time.Time, userID string, inventories []Inventory,
+	usersByID map[string]User, result *RunResult,
+) {
+	user, ok := usersByID[userID]
+	if !ok {
+		log.Printf("ユーザーID: %s はユーザー一覧に存在しないため補充のリマインダーをスキップします", userID)
+		return
+	}
+
+	loc, err := n.repo.GetUserLocation(ctx, userID)
+	if err != nil {
+		log.Printf("ユーザーID: %s のタイムゾーン取得エラー: %v", userID, err)
+		return
+	}
+	today := now.In(loc)
+	if !n.isRefillTime(today) {
+		return
+	}
+
+	settings, err := n.repo.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		log.Printf("ユーザーID: %s の通知設定取得エラー: %v", userID, err)
+		return
+	}
+	platforms := make(map[string]bool)
+	for _, setting := range settings {
+		if setting.IsEnabled {
+			platforms[setting.Platform] = true
+		}
+	}
+	if len(platforms) == 0 {
+		return
+	}
+
+	for _, inventory := range inventories {
+		n.sendRefillReminder(ctx, now, today, user, inventory, platforms, result)
+	}
+}
+
+// isRefillTime はユーザーのタイムゾーンの現在時刻が補充のリマインダーを送り始める時刻を過ぎているかどうかを返す
+// 時刻が未設定の場合や解析できない場合は時刻に関わらず送る
+func (n *Notifier) isRefillTime(localNow time.Time) bool {
+	if n.refillTime == "" {
+		return true
+	}
+	t, err := time.Parse("15:04", n.refillTime)
+	if err != nil {
+		log.Printf("補充のリマインダーの時刻 %q を解析できないため時刻に関わらず送信します", n.refillTime)
+		return true
+	}
+	return localNow.Hour()*60+localNow.Minute() >= t.Hour()*60+t.Minute()
+}
+
+// sendRefillReminder は手持ちの薬が補充のリマインダーの日数以内になくなる見込みの場合に、補充のリマインダーを1回だけ送信する
+// 送信済みの状態はユーザーが在庫を登録し直すまで残るため、在庫ごとに1回だけ送る
+func (n *Notifier) sendRefillReminder(
+	ctx context.Context, now, today time.Time, user User, inventory Inventory, platforms map[string]bool, result *RunResult,
+) {
+	if !inventory.needsRefillCheck() {
+		return
+	}
+
+	forecast, err := getRunOutForecast(ctx, n.repo, user.ID, inventory, now)
+	if err != nil {
+		log.Printf("ユーザーID: %s の薬 %s のなくなる日の予測エラー: %v", user.ID, inventory.MedicationID, err)
+		return
+	}
+	if forecast.RunOutDate.IsZero() {
+		return
+	}
+	startOfToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	daysLeft := int(forecast.RunOutDate.Sub(startOfToday).Round(24*time.Hour).Hours() / 24)
+	if daysLeft > inventory.RefillReminderDays {
+		return
+	}
+
+	// 送信前に送信済みにし、同時に実行された他のLambdaや翌日以降の実行で重複して送らないようにする
+	claimed, err := n.repo.SetRefillReminderSent(ctx, user.ID, inventory.MedicationID, true, n.clock.Now())
+	if err != nil {
+		log.Printf("ユーザーID: %s の%v", user.ID, err)
+		return
+	}
+	if !claimed {
+		log.Printf("ユーザーID: %s の薬 %s の補充のリマインダーは他の実行で処理済みのためスキップします", user.ID, inventory.MedicationID)
+		return
+	}
+
+	// リマインダーとは別の通知のため、同じサブスクリプションにも送る
+	sent := n.sendToDevices(ctx, DeliveryRefill, refillSlot(today.Format("2006-01-02"), inventory.MedicationID), user, platforms,
+		generateRefillMessage(inventory.Name, forecast.RunOutDate, daysLeft), 0, newSubscriptionSet(), result)
+	result.RefillCount += len(sent)
+	if len(sent) == 0 {
+		// 1件も送れなかった場合は次の実行で送り直せるよう送信済みを取り消す
+		if _, err := n.repo.SetRefillReminderSent(context.WithoutCancel(ctx), user.ID, inventory.MedicationID, false, n.clock.Now()); err != nil {
+			log.Printf("ユーザーID: %s の%v", user.ID, err)
+		}
+	}
+}
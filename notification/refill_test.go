@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"okusuri-shared/reminder"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMedicationID はテストで使う薬のID
+const testMedicationID = "01K3ZQ8ZJ0V6W7X8Y9Z0A1B2C3"
+
+// inventoryItem はバックエンドAPIと同じ形式で薬の在庫を作成する
+func inventoryItem(userID, medicationID, name string, remainingPills, refillReminderDays int) OkusuriTable {
+	return OkusuriTable{
+		PK:     userPK(userID),
+		SK:     inventorySKPrefix + medicationID,
+		GSI1PK: inventoryGSI1PK,
+		GSI1SK: userPK(userID),
+		Data: map[string]interface{}{
+			"name":               name,
+			"packSize":           28,
+			"pillsPerDose":       1,
+			"remainingPills":     remainingPills,
+			"refillReminderDays": refillReminderDays,
+			"refillReminderSent": false,
+		},
+	}
+}
+
+// getTestInventory はユーザーの指定した薬の在庫を取得する
+func getTestInventory(t *testing.T, notifier *Notifier, userID, medicationID string) Inventory {
+	t.Helper()
+	inventoriesByUser, err := notifier.repo.GetInventoriesByUser(context.Background())
+	require.NoError(t, err)
+	for _, inventory := range inventoriesByUser[userID] {
+		if inventory.MedicationID == medicationID {
+			return inventory
+		}
+	}
+	t.Fatalf("薬 %s の在庫が見つかりません", medicationID)
+	return Inventory{}
+}
+
+func TestNotifierRefillReminder(t *testing.T) {
+	// 2025-09-01（月）08:00 JST
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+	notifier, store, push, clk := setupEscalationTest(t, now, EscalationPolicy{},
+		"user-low", "user-logged", "user-enough", "user-rest", "user-disabled", "user-none")
+	store.items = append(store.items,
+		// 9/1〜9/3の3回分で、9/4になくなる
+		inventoryItem("user-low", testMedicationID, "ヤーズフレックス", 3, 7),
+		// 同じユーザーの別の薬は十分に残っているため送らない
+		inventoryItem("user-low", "01K3ZQ8ZJ0V6W7X8Y9Z0A1B2C4", "鉄剤", 30, 7),
+		// 今日の服用を記録済みで、9/2〜9/3の2回分
+		inventoryItem("user-logged", testMedicationID, "ヤーズフレックス", 2, 7),
+		medicationItem("user-logged", "2025-09-01", false),
+		// 9/1〜9/10の10回分で、リマインダーの7日前より先になくなる
+		inventoryItem("user-enough", testMedicationID, "ヤーズフレックス", 10, 7),
+		// 休薬が無ければ9/6になくなるが、9/5〜9/11の休薬を挟むため9/13になくなる
+		inventoryItem("user-rest", testMedicationID, "ヤーズフレックス", 5, 7),
+		OkusuriTable{PK: userPK("user-rest"), SK: "REGIMEN", Data: map[string]interface{}{
+			"type": "fixed_cycle", "activeDays": 21, "restPeriodDays": 7, "cycleStartDate": "2025-08-15",
+		}},
+		// 補充のリマインダーを送らない設定
+		inventoryItem("user-disabled", testMedicationID, "ヤーズフレックス", 1, 0),
+	)
+
+	result, err := notifier.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.RefillCount)
+	assert.Equal(t, 5, result.SentCount)
+	assert.Equal(t, 1, result.SkippedCount)
+	assert.Equal(t, map[string]int{
+		"/user-low": 2, "/user-logged": 1, "/user-enough": 1, "/user-rest": 1, "/user-disabled": 1, "/user-none": 1,
+	}, push.counts(), "服用を記録済みでも補充のリマインダーは送る")
+
+	messages := make(map[string]string)
+	for _, record := range notifier.deliveries.(*recordingDeliveryLogger).records {
+		if record.Kind == DeliveryRefill {
+			messages[record.UserID] = record.Message
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"user-low":    "ヤーズフレックスの残りが少なくなっています。9月4日頃になくなる見込みです（あと3日）。早めに補充してください。",
+		"user-logged": "ヤーズフレックスの残りが少なくなっています。9月4日頃になくなる見込みです（あと3日）。早めに補充してください。",
+	}, messages)
+
+	t.Run("送信済みにした在庫には再び送らない", func(t *testing.T) {
+		assert.True(t, getTestInventory(t, notifier, "user-low", testMedicationID).RefillReminderSent)
+		assert.False(t, getTestInventory(t, notifier, "user-low", "01K3ZQ8ZJ0V6W7X8Y9Z0A1B2C4").RefillReminderSent)
+
+		clk.Advance(24 * time.Hour)
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.RefillCount)
+	})
+
+	t.Run("送信に失敗した場合は次の実行で送り直す", func(t *testing.T) {
+		clk.Set(now.Add(48 * time.Hour)) // 9/3
+		// 9/3〜9/4の2回分で、9/5になくなる
+		store.items = append(store.items, inventoryItem("user-none", testMedicationID, "ヤーズフレックス", 2, 7))
+		// リマインダーは届き、補充のリマインダーの送信に失敗する
+		push.script("/user-none", pushResponse{status: http.StatusCreated}, pushResponse{status: http.StatusBadRequest})
+
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.RefillCount)
+		assert.Equal(t, 1, result.FailedCount)
+
+		assert.False(t, getTestInventory(t, notifier, "user-none", testMedicationID).RefillReminderSent)
+
+		// リマインダーのスケジュールがない次の実行で送り直す
+		clk.Advance(reminder.Interval)
+		result, err = notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.RefillCount)
+		assert.Equal(t, 0, result.SentCount)
+	})
+}
+
+func TestNotifierRefillReminderWithoutSchedule(t *testing.T) {
+	// 2025-09-01（月）06:00 JST、リマインダーは08:00
+	now := time.Date(2025, 8, 31, 21, 0, 0, 0, time.UTC)
+	notifier, store, push, clk := setupEscalationTest(t, now, EscalationPolicy{}, "user-low", "user-sent")
+	notifier.refillTime = "09:00"
+	sent := inventoryItem("user-sent", testMedicationID, "ヤーズフレックス", 1, 7)
+	sent.Data["refillReminderSent"] = true
+	store.items = append(store.items,
+		// 9/1〜9/3の3回分で、9/4になくなる
+		inventoryItem("user-low", testMedicationID, "ヤーズフレックス", 3, 7),
+		// 補充のリマインダーを送信済み
+		sent,
+	)
+
+	t.Run("補充のリマインダーの時刻より前は送らない", func(t *testing.T) {
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{}, result)
+		assert.Empty(t, push.counts())
+	})
+
+	t.Run("リマインダーのスケジュールがない時刻でも在庫があるユーザーに送る", func(t *testing.T) {
+		clk.Set(now.Add(3*time.Hour + 30*time.Minute)) // 09:30
+		result, err := notifier.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, RunResult{RefillCount: 1}, result)
+		assert.Equal(t, map[string]int{"/user-low": 1}, push.counts())
+		assert.True(t, getTestInventory(t, notifier, "user-low", testMedicationID).RefillReminderSent)
+	})
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"okusuri-shared/reminder"
+	"okusuri-shared/status"
+)
+
+const (
+	userPKPrefix = "USER#"
+	// notificationSKPrefix は通知設定のソートキーの接頭辞（NOTIFICATION#{platform}）
+	notificationSKPrefix = "NOTIFICATION#"
+	// deviceSKPrefix は通知先のデバイスのソートキーの接頭辞（DEVICE#{deviceId}）
+	deviceSKPrefix = "DEVICE#"
+	// reminderCursorPK / reminderCursorSK は最後に処理したリマインダーのバケットを記録するアイテムのキー
+	reminderCursorPK = "SYSTEM#NOTIFICATION"
+	reminderCursorSK = "REMINDER_CURSOR"
+)
+
+// リポジトリ層
+type Repository struct {
+	store itemStore
+	users UserDirectory
+	// defaultTimezone はプロフィール未設定のユーザーに適用するタイムゾーン
+	defaultTimezone string
+}
+
+func NewRepository(store itemStore, users UserDirectory, defaultTimezone string) *Repository {
+	return &Repository{
+		store:           store,
+		users:           users,
+		defaultTimezone: defaultTimezone,
+	}
+}
+
+// ユーザー一覧を取得（CognitoまたはDynamoDBのプロフィールから）
+func (r *Repository) GetUsers(ctx context.Context) ([]User, error) {
+	return r.users.ListUsers(ctx)
+}
+
+// DynamoDBからユーザーの通知設定を取得（PKから所有ユーザーのIDを取り出して保持する）
+func (r *Repository) GetNotificationSettings(ctx context.Context, userID string) ([]NotificationSetting, error) {
+	results, err := r.store.QueryBySKPrefix(ctx, userPK(userID), notificationSKPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("通知設定取得エラー: %v", err)
+	}
+
+	var settings []NotificationSetting
+	for _, result := range results {
+		owner, ok := userIDFromPK(result.PK)
+		if !ok {
+			log.Printf("通知設定 %s/%s のPKからユーザーIDを取得できないためスキップします", result.PK, result.SK)
+			continue
+		}
+		if data, ok := result.Data["platform"].(string); ok {
+			setting := NotificationSetting{
+				UserID:       owner,
+				Platform:     data,
+				IsEnabled:    getBoolValue(result.Data, "isEnabled", true),
+				Subscription: getStringValue(result.Data, "subscription", ""),
+			}
+			settings = append(settings, setting)
+		}
+	}
+
+	return settings, nil
+}
+
+// DynamoDBからユーザーの通知先のデバイスを取得
+func (r *Repository) GetNotificationDevices(ctx context.Context, userID string) ([]NotificationDevice, error) {
+	results, err := r.store.QueryBySKPrefix(ctx, userPK(userID), deviceSKPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("通知先デバイス取得エラー: %v", err)
+	}
+
+	devices := make([]NotificationDevice, 0, len(results))
+	for _, result := range results {
+		devices = append(devices, NotificationDevice{
+			UserID:       userID,
+			ID:           strings.TrimPrefix(result.SK, deviceSKPrefix),
+			Platform:     getStringValue(result.Data, "platform", ""),
+			Label:        getStringValue(result.Data, "label", ""),
+			IsEnabled:    getBoolValue(result.Data, "isEnabled", true),
+			Subscription: getStringValue(result.Data, "subscription", ""),
+		})
+	}
+	return devices, nil
+}
+
+// DisableDevice は失効したサブスクリプションのデバイスを無効化する
+// デバイスの導入前のサブスクリプションの場合は通知設定を無効化する
+// 読み込んだ後にユーザーがサブスクリプションを登録し直していた場合は更新せずにfalseを返す
+func (r *Repository) DisableDevice(
+	ctx context.Context, device NotificationDevice, reason string, now time.Time,
+) (bool, error) {
+	sk := deviceSK(device.ID)
+	if device.ID == "" {
+		sk = notificationSK(device.Platform)
+	}
+	fields := map[string]interface{}{
+		"isEnabled":      false,
+		"disabledReason": reason,
+		"disabledAt":     now.UTC().Format(time.RFC3339),
+		"updatedAt":      now.UTC().Format(time.RFC3339),
+	}
+	err := r.store.UpdateDataFieldsIfDataEquals(ctx, userPK(device.UserID), sk,
+		fields, "subscription", device.Subscription)
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("通知先デバイスの無効化エラー: %v", err)
+	}
+	return true, nil
+}
+
+// DynamoDBから最後に処理したリマインダーのバケットの開始時刻を取得（記録がない場合はゼロ値）
+func (r *Repository) GetReminderCursor(ctx context.Context) (time.Time, error) {
+	result, err := r.store.GetItem(ctx, reminderCursorPK, reminderCursorSK)
+	if errors.Is(err, errItemNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("リマインダーの処理済みバケット取得エラー: %v", err)
+	}
+	return parseTime(getStringValue(result.Data, "bucketAt", ""))
+}
+
+// SaveReminderCursor は最後に処理したリマインダーのバケットを記録する
+// 読み込んだ時点から記録が変わっていない場合だけ更新し、他の実行が先に更新していた場合はfalseを返す
+func (r *Repository) SaveReminderCursor(ctx context.Context, prev, bucket, now time.Time) (bool, error) {
+	item := OkusuriTable{
+		PK:        reminderCursorPK,
+		SK:        reminderCursorSK,
+		Data:      map[string]interface{}{"bucketAt": bucket.UTC().Format(time.RFC3339)},
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		UpdatedAt: now.UTC().Format(time.RFC3339),
+	}
+	var err error
+	if prev.IsZero() {
+		err = r.store.PutItemIfNotExists(ctx, item)
+	} else {
+		err = r.store.PutItemIfDataEquals(ctx, item, "bucketAt", prev.UTC().Format(time.RFC3339))
+	}
+	if errors.Is(err, errConditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("リマインダーの処理済みバケット更新エラー: %v", err)
+	}
+	return true, nil
+}
+
+// DynamoDBから現在時刻に送信するリマインダーのスケジュールを取得
+// GSI1のUTCのバケットで候補を絞り込み、各ユーザーのタイムゾーンで時刻と曜日が一致するものだけを返す
+func (r *Repository) GetDueSchedules(ctx context.Context, now time.Time) ([]ReminderSchedule, error) {
+	dueAt := now.UTC().Truncate(reminder.Interval)
+	locations := make(map[string]*time.Location)
+	seen := make(map[string]bool)
+
+	var schedules []ReminderSchedule
+	for _, bucketKey := range reminder.CandidateBuckets(now) {
+		results, err := r.store.QueryByGSI1PK(ctx, bucketKey)
+		if err != nil {
+			return nil, fmt.Errorf("リマインダーのスケジュール取得エラー: %v", err)
+		}
+
+		for _, result := range results {
+			key := result.PK + "/" + result.SK
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			userID, ok := userIDFromPK(result.PK)
+			if !ok {
+				log.Printf("スケジュール %s のPKからユーザーIDを取得できないためスキップします", key)
+				continue
+			}
+			schedule := ReminderSchedule{
+				UserID:   userID,
+				Platform: getStringValue(result.Data, "platform", ""),
+				Time:     getStringValue(result.Data, "time", ""),
+				Weekdays: getWeekdaysValue(result.Data, "weekdays"),
+				Timezone: getStringValue(result.Data, "timezone", r.defaultTimezone),
+				DueAt:    dueAt,
+			}
+
+			loc, ok := locations[schedule.Timezone]
+			if !ok {
+				loc, err = time.LoadLocation(schedule.Timezone)
+				if err != nil {
+					log.Printf("タイムゾーン %s を読み込めないためUTCで判定します: %v", schedule.Timezone, err)
+					loc = time.UTC
+				}
+				locations[schedule.Timezone] = loc
+			}
+
+			if reminder.IsDue(reminder.Schedule{Clock: schedule.Time, Weekdays: schedule.Weekdays}, loc, now) {
+				schedules = append(schedules, schedule)
+			}
+		}
+	}
+
+	return schedules, nil
+}
+
+// DynamoDBから服用履歴を取得
+func (r *Repository) GetMedicationLogs(ctx context.Context, userID string) ([]MedicationLog, error) {
+	results, err := r.store.QueryBySKPrefix(ctx, userPK(userID), "MEDICATION#")
+	if err != nil {
+		return nil, fmt.Errorf("服用履歴取得エラー: %v", err)
+	}
+
+	var logs []MedicationLog
+	for _, result := range results {
+		// 論理削除された記録はステータス計算に含めない
+		if result.DeletedAt != "" {
+			continue
+		}
+		// 日時が不正な記録が1件あってもリマインダーなどの判定を止めないよう、その記録だけをスキップする
+		createdAt, err := parseTime(getStringValue(result.Data, "createdAt", ""))
+		if err != nil {
+			log.Printf("服用履歴 %s のcreatedAtが不正なためスキップします: %v", result.SK, err)
+			continue
+		}
+		updatedAt, err := parseTime(getStringValue(result.Data, "updatedAt", ""))
+		if err != nil {
+			log.Printf("服用履歴 %s のupdatedAtが不正なためスキップします: %v", result.SK, err)
+			continue
+		}
+		logs = append(logs, MedicationLog{
+			HasBleeding:      getBoolValue(result.Data, "hasBleeding", false),
+			BleedingSeverity: getStringValue(result.Data, "bleedingSeverity", ""),
+			CreatedAt:        createdAt,
+			UpdatedAt:        updatedAt,
+		})
+	}
+
+	return logs, nil
+}
+
+// DynamoDBからレジメン（服薬ルール）を取得（未設定の場合はデフォルトのルール）
+func (r *Repository) GetRegimen(ctx context.Context, userID string) (status.Regimen, error) {
+	result, err := r.store.GetItem(ctx, userPK(userID), "REGIMEN")
+	if err != nil {
+		if errors.Is(err, errItemNotFound) {
+			return status.DefaultRegimen(), nil
+		}
+		return status.Regimen{}, fmt.Errorf("レジメン取得エラー: %v", err)
+	}
+
+	defaults := status.DefaultRegimen()
+	return status.Regimen{
+		Type:                    getStringValue(result.Data, "type", defaults.Type),
+		RestPeriodDays:          getIntValue(result.Data, "restPeriodDays", defaults.RestPeriodDays),
+		BleedingTriggerDays:     getIntValue(result.Data, "bleedingTriggerDays", defaults.BleedingTriggerDays),
+		MinIntakeDaysBeforeRest: getIntValue(result.Data, "minIntakeDaysBeforeRest", defaults.MinIntakeDaysBeforeRest),
+		MaxContinuousDays:       getIntValue(result.Data, "maxContinuousDays", 0),
+		ActiveDays:              getIntValue(result.Data, "activeDays", 0),
+		CycleStartDate:          getStringValue(result.Data, "cycleStartDate", ""),
+		BleedingThreshold:       status.BleedingSeverity(getStringValue(result.Data, "bleedingThreshold", "")),
+	}, nil
+}
+
+// DynamoDBからユーザーのタイムゾーンを取得（未設定の場合はデフォルトのタイムゾーン）
+func (r *Repository) GetUserLocation(ctx context.Context, userID string) (*time.Location, error) {
+	timezone := r.defaultTimezone
+
+	result, err := r.store.GetItem(ctx, userPK(userID), profileSK)
+	if err != nil && !errors.Is(err, errItemNotFound) {
+		return nil, fmt.Errorf("プロフィール取得エラー: %v", err)
+	}
+	if err == nil {
+		timezone = getStringValue(result.Data, "timezone", timezone)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("タイムゾーン %s を読み込めないためUTCで計算します: %v", timezone, err)
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// ヘルパー関数
+func userPK(userID string) string {
+	return userPKPrefix + userID
+}
+
+func notificationSK(platform string) string {
+	return notificationSKPrefix + platform
+}
+
+func deviceSK(deviceID string) string {
+	return deviceSKPrefix + deviceID
+}
+
+// userIDFromPK はPK（USER#{cognitoUserId}）からユーザーIDを取り出す
+func userIDFromPK(pk string) (string, bool) {
+	userID, ok := strings.CutPrefix(pk, userPKPrefix)
+	if !ok || userID == "" {
+		return "", false
+	}
+	return userID, true
+}
+
+func getBoolValue(data map[string]interface{}, key string, defaultValue bool) bool {
+	if value, ok := data[key].(bool); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func getIntValue(data map[string]interface{}, key string, defaultValue int) int {
+	switch value := data[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return defaultValue
+}
+
+func getWeekdaysValue(data map[string]interface{}, key string) []time.Weekday {
+	values, _ := data[key].([]interface{})
+	weekdays := make([]time.Weekday, 0, len(values))
+	for _, value := range values {
+		switch n := value.(type) {
+		case float64:
+			weekdays = append(weekdays, time.Weekday(n))
+		case int:
+			weekdays = append(weekdays, time.Weekday(n))
+		}
+	}
+	return weekdays
+}
+
+func getStringSliceValue(data map[string]interface{}, key string) []string {
+	switch values := data[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func getStringValue(data map[string]interface{}, key string, defaultValue string) string {
+	if value, ok := data[key].(string); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// RFC3339形式の日時を解析（空文字や不正な形式の場合は現在時刻で補わずにエラーを返す）
+func parseTime(timeStr string) (time.Time, error) {
+	if timeStr == "" {
+		return time.Time{}, errors.New("日時が空です")
+	}
+	t, err := time.Parse(time.RFC3339, timeStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日時の形式が不正です: %q", timeStr)
+	}
+	return t, nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"okusuri-notification/pkg/config"
+	"okusuri-shared/clock"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+)
+
+const (
+	// pushMaxAttempts はPushサービスが一時的なエラー（429・5xx）を返した場合を含めた最大リクエスト回数
+	pushMaxAttempts = 3
+	// pushRetryBaseDelay は再試行までの待ち時間の初期値（再試行ごとに2倍にする）
+	pushRetryBaseDelay = time.Second
+	// pushRetryMaxDelay は再試行までの待ち時間の上限（Retry-Afterヘッダーの値にも適用する）
+	pushRetryMaxDelay = 10 * time.Second
+)
+
+// ErrSubscriptionExpired はPushサービスがサブスクリプションの失効（404/410）を返したことを表す
+var ErrSubscriptionExpired = errors.New("サブスクリプションが失効しています")
+
+// サービス層
+type NotificationService struct {
+	clock clock.Clock
+	// limiter はPushサービスのホストごとのレート制限（nilの場合は制限しない）
+	limiter *hostRateLimiter
+	// sleep は再試行までの待機処理（テストでは待たずに待ち時間だけを記録する）
+	sleep func(ctx context.Context, d time.Duration) error
+	// dryRun の場合はPushサービスに送信せず、送信したものとして結果を返す
+	dryRun bool
+}
+
+func NewNotificationService(clk clock.Clock, limiter *hostRateLimiter) *NotificationService {
+	return &NotificationService{
+		clock:   clk,
+		limiter: limiter,
+		sleep:   sleepContext,
+	}
+}
+
+// newDryRunNotificationService はPushサービスに送信しないNotificationServiceを作成する（ローカル実行の--dry-run）
+func newDryRunNotificationService(clk clock.Clock) *NotificationService {
+	service := NewNotificationService(clk, nil)
+	service.dryRun = true
+	return service
+}
+
+// sleepContext はdだけ待機する。待機中にコンテキストが終了した場合はそのエラーを返す
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SendNotificationWithDays はデバイスのサブスクリプションに通知を送信し、Pushサービスの応答に応じた送信結果を返す
+// 404/410はサブスクリプションの失効としてErrSubscriptionExpiredを返す。429・5xxは待ち時間を空けて再試行し、
+// それ以外の4xxは再試行せずにエラーを返す
+func (s *NotificationService) SendNotificationWithDays(
+	ctx context.Context, user User, device NotificationDevice, message string, consecutiveDays int,
+) (Delivery, error) {
+	if device.Subscription == "" {
+		log.Printf("ユーザーID: %s のサブスクリプションが空です", user.ID)
+		return failedDelivery(0, 0, "サブスクリプションが見つかりません")
+	}
+
+	subscriptionPreview := device.Subscription
+	if len(subscriptionPreview) > 10 {
+		subscriptionPreview = subscriptionPreview[:10] + "..."
+	}
+
+	log.Printf("ユーザーID: %s の処理を開始します", user.ID)
+	log.Printf("サブスクリプション: %s", subscriptionPreview)
+
+	var subscription PushSubscription
+	err := json.Unmarshal([]byte(device.Subscription), &subscription)
+	if err != nil {
+		log.Printf("サブスクリプションのパースに失敗: %v", err)
+		return failedDelivery(0, 0, fmt.Sprintf("サブスクリプションのパースに失敗: %v", err))
+	}
+	if s.dryRun {
+		log.Printf("ユーザーID: %s はドライランのため送信しません", user.ID)
+		return Delivery{Outcome: DeliveryDryRun}, nil
+	}
+
+	vapidPublicKey := config.GetVAPIDPublicKey()
+	vapidPrivateKey := config.GetVAPIDPrivateKey()
+
+	if vapidPublicKey == "" || vapidPrivateKey == "" {
+		log.Printf("VAPID鍵が設定されていません")
+		return failedDelivery(0, 0, "VAPID鍵が設定されていません")
+	}
+
+	notificationData := NotificationData{
+		Title: "お薬通知",
+		Body:  message,
+		Data: map[string]string{
+			"messageId":       fmt.Sprintf("medication-%d", s.clock.Now().UnixNano()),
+			"timestamp":       fmt.Sprintf("%d", s.clock.Now().Unix()),
+			"userId":          user.ID,
+			"consecutiveDays": fmt.Sprintf("%d", consecutiveDays),
+		},
+	}
+
+	payload, err := json.Marshal(notificationData)
+	if err != nil {
+		log.Printf("通知内容のJSON変換に失敗: %v", err)
+		return failedDelivery(0, 0, fmt.Sprintf("通知内容のJSON変換に失敗: %v", err))
+	}
+
+	pushSubscription := &webpush.Subscription{
+		Endpoint: subscription.Endpoint,
+		Keys: webpush.Keys{
+			P256dh: subscription.Keys.P256dh,
+			Auth:   subscription.Keys.Auth,
+		},
+	}
+	options := &webpush.Options{
+		VAPIDPublicKey:  vapidPublicKey,
+		VAPIDPrivateKey: vapidPrivateKey,
+		TTL:             30,
+		Subscriber:      "example@example.com",
+	}
+
+	host := pushHost(device.Subscription)
+	for attempt := 1; ; attempt++ {
+		if err := s.limiter.Wait(ctx, host); err != nil {
+			log.Printf("Pushサービス %s のレート制限の待機中に実行期限を過ぎるため送信を中止します: %v", host, err)
+			return failedDelivery(0, attempt-1, fmt.Sprintf("レート制限の待機を中止: %v", err))
+		}
+
+		resp, err := webpush.SendNotificationWithContext(ctx, payload, pushSubscription, options)
+		if err != nil {
+			// 接続エラーなどでPushサービスの応答を受け取れなかった場合も一時的なエラーとして再試行する
+			log.Printf("通知送信エラー（%d回目）: %v", attempt, err)
+			if waitErr := s.waitRetry(ctx, attempt, 0); waitErr != nil {
+				return failedDelivery(0, attempt, fmt.Sprintf("通知送信エラー: %v", err))
+			}
+			continue
+		}
+		resp.Body.Close()
+
+		switch code := resp.StatusCode; {
+		case code >= 200 && code < 300:
+			log.Printf("通知送信成功 - ユーザーID: %s", user.ID)
+			return Delivery{Outcome: DeliverySent, StatusCode: code, Attempts: attempt}, nil
+
+		case code == http.StatusNotFound || code == http.StatusGone:
+			log.Printf("ユーザーID: %s (%s) のサブスクリプションは失効しています（HTTP %d）", user.ID, device.Platform, code)
+			return Delivery{
+				Outcome:    DeliveryExpired,
+				StatusCode: code,
+				Attempts:   attempt,
+				Reason:     fmt.Sprintf("HTTP %d", code),
+			}, ErrSubscriptionExpired
+
+		case code == http.StatusTooManyRequests || code >= 500:
+			log.Printf("Pushサービスが一時的なエラーを返しました（%d回目、HTTP %d）", attempt, code)
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), s.clock.Now())
+			if waitErr := s.waitRetry(ctx, attempt, retryAfter); waitErr != nil {
+				return failedDelivery(code, attempt, fmt.Sprintf("HTTP %d: %v", code, waitErr))
+			}
+
+		default:
+			log.Printf("Pushサービスが通知を拒否しました（HTTP %d）", code)
+			return failedDelivery(code, attempt, fmt.Sprintf("HTTP %d", code))
+		}
+	}
+}
+
+// waitRetry はattempt回目の送信に失敗した後、再試行までの待ち時間だけ待機する
+// 最大回数に達した場合や、待機するとLambdaの実行期限を過ぎる場合は待たずにエラーを返す
+func (s *NotificationService) waitRetry(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	if attempt >= pushMaxAttempts {
+		return fmt.Errorf("%d回再試行しても送信できませんでした", pushMaxAttempts-1)
+	}
+
+	delay := pushRetryBaseDelay << (attempt - 1)
+	if retryAfter > 0 {
+		delay = retryAfter
+	}
+	delay = min(delay, pushRetryMaxDelay)
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return fmt.Errorf("実行期限までに再試行できません")
+	}
+	return s.sleep(ctx, delay)
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）を待ち時間に変換する
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+func failedDelivery(statusCode, attempts int, reason string) (Delivery, error) {
+	return Delivery{
+		Outcome:    DeliveryFailed,
+		StatusCode: statusCode,
+		Attempts:   attempts,
+		Reason:     reason,
+	}, errors.New(reason)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"okusuri-shared/status"
+)
+
+// statusEngine はバックエンドAPIと共通の服薬ステータス計算エンジン
+var statusEngine = status.NewEngine()
+
+// ユーザーの服用履歴とレジメンを取得してステータスを計算
+func getMedicationStatus(ctx context.Context, repo *Repository, userID string, now time.Time) (status.Result, error) {
+	medicationLogs, err := repo.GetMedicationLogs(ctx, userID)
+	if err != nil {
+		return status.Result{}, err
+	}
+	regimen, err := repo.GetRegimen(ctx, userID)
+	if err != nil {
+		return status.Result{}, err
+	}
+	loc, err := repo.GetUserLocation(ctx, userID)
+	if err != nil {
+		return status.Result{}, err
+	}
+	return calculateMedicationStatus(medicationLogs, regimen, now, loc)
+}
+
+// 薬のステータス計算（バックエンドAPIと共通の計算方法を使用、日付の境界はユーザーのタイムゾーン）
+func calculateMedicationStatus(logs []MedicationLog, regimen status.Regimen, now time.Time, loc *time.Location) (status.Result, error) {
+	input := statusInput(logs, regimen, now, loc)
+	result, err := statusEngine.Evaluate(input)
+	if errors.Is(err, status.ErrUnsupportedRegimenType) {
+		log.Printf("未対応のレジメン種別のためデフォルトのルールで計算します: %s", regimen.Type)
+		input.Regimen = status.DefaultRegimen()
+		result, err = statusEngine.Evaluate(input)
+	}
+	return result, err
+}
+
+// ユーザーの服用履歴とレジメンを取得して在庫の手持ちがなくなる日を予測
+func getRunOutForecast(ctx context.Context, repo *Repository, userID string, inventory Inventory, now time.Time) (status.Forecast, error) {
+	medicationLogs, err := repo.GetMedicationLogs(ctx, userID)
+	if err != nil {
+		return status.Forecast{}, err
+	}
+	regimen, err := repo.GetRegimen(ctx, userID)
+	if err != nil {
+		return status.Forecast{}, err
+	}
+	loc, err := repo.GetUserLocation(ctx, userID)
+	if err != nil {
+		return status.Forecast{}, err
+	}
+	return forecastRunOut(medicationLogs, regimen, inventory, now, loc)
+}
+
+// 在庫の手持ちがなくなる日の予測（バックエンドAPIと共通の計算方法を使用、今後の休薬期間の分だけ後ろにずれる）
+func forecastRunOut(
+	logs []MedicationLog, regimen status.Regimen, inventory Inventory, now time.Time, loc *time.Location,
+) (status.Forecast, error) {
+	input := statusInput(logs, regimen, now, loc)
+	forecast, err := statusEngine.ForecastRunOut(input, inventory.RemainingPills, inventory.PillsPerDose)
+	if errors.Is(err, status.ErrUnsupportedRegimenType) {
+		log.Printf("未対応のレジメン種別のためデフォルトのルールで予測します: %s", regimen.Type)
+		input.Regimen = status.DefaultRegimen()
+		forecast, err = statusEngine.ForecastRunOut(input, inventory.RemainingPills, inventory.PillsPerDose)
+	}
+	return forecast, err
+}
+
+// statusInput は服用履歴をステータス計算の入力に変換する
+func statusInput(logs []MedicationLog, regimen status.Regimen, now time.Time, loc *time.Location) status.Input {
+	input := status.Input{
+		Logs:     make([]status.Log, 0, len(logs)),
+		Now:      now,
+		Regimen:  regimen,
+		Location: loc,
+	}
+	for _, medicationLog := range logs {
+		input.Logs = append(input.Logs, status.Log{
+			TakenAt:     medicationLog.CreatedAt,
+			HasBleeding: medicationLog.HasBleeding,
+			Severity:    status.BleedingSeverity(medicationLog.BleedingSeverity),
+		})
+	}
+	return input
+}
+
+// メッセージ生成
+func generateStatusBasedMessage(result status.Result) string {
+	if result.IsRestPeriod {
+		if result.RestDaysLeft > 0 {
+			return fmt.Sprintf("現在休薬期間中です。あと%d日で服薬を再開してください。", result.RestDaysLeft)
+		} else {
+			return "休薬期間が終了しました。本日から服薬を再開してください。"
+		}
+	} else {
+		if result.CurrentStreak > 0 {
+			return fmt.Sprintf("お薬の時間です。忘れずに服用してください。（連続%d日目）", result.CurrentStreak)
+		} else {
+			return "お薬の時間です。忘れずに服用してください。"
+		}
+	}
+}
+
+// 追いリマインダーのメッセージ生成（countは何回目の追いリマインダーか）
+func generateFollowUpMessage(count int) string {
+	if count <= 1 {
+		return "今日のお薬の記録がまだありません。服用したら忘れずに記録してください。"
+	}
+	return fmt.Sprintf("今日のお薬はお済みですか？服用したら記録してください。（%d回目のお知らせ）", count)
+}
+
+// 補充のリマインダーのメッセージ生成（nameは薬の名前、daysLeftは手持ちがなくなる日までの日数）
+func generateRefillMessage(name string, runOutDate time.Time, daysLeft int) string {
+	if name == "" {
+		name = "お薬"
+	}
+	if daysLeft <= 0 {
+		return fmt.Sprintf("%sの手持ちがなくなりました。早めに補充してください。", name)
+	}
+	return fmt.Sprintf("%sの残りが少なくなっています。%d月%d日頃になくなる見込みです（あと%d日）。早めに補充してください。",
+		name, int(runOutDate.Month()), runOutDate.Day(), daysLeft)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/guregu/dynamo/v2"
+)
+
+// gsi1IndexName はGSI1PK/GSI1SKをキーに持つグローバルセカンダリインデックス名
+const gsi1IndexName = "GSI1"
+
+// errItemNotFound はキーに一致するアイテムが存在しない場合のエラー
+var errItemNotFound = errors.New("item not found")
+
+// errConditionFailed は条件付き書き込みの条件を満たさなかった場合のエラー
+var errConditionFailed = errors.New("condition failed")
+
+// itemStore は通知処理が必要とするDynamoDBのアイテム操作
+// テストではインメモリの実装に差し替える
+type itemStore interface {
+	// QueryByGSI1PK はGSI1PKが一致するアイテムをGSI1から取得する
+	QueryByGSI1PK(ctx context.Context, gsi1pk string) ([]OkusuriTable, error)
+	// QueryBySKPrefix は指定したPKのうちSKが指定の接頭辞で始まるアイテムを取得する
+	QueryBySKPrefix(ctx context.Context, pk, prefix string) ([]OkusuriTable, error)
+	// QueryByGSI1PKUpTo はGSI1PKが一致し、GSI1SKが指定値以下のアイテムをGSI1から取得する
+	QueryByGSI1PKUpTo(ctx context.Context, gsi1pk, maxGSI1SK string) ([]OkusuriTable, error)
+	// ScanBySK はSKが一致するアイテムをテーブル全体から取得する（ローカル環境向け）
+	ScanBySK(ctx context.Context, sk string) ([]OkusuriTable, error)
+	// GetItem はPKとSKが一致するアイテムを取得する（存在しない場合はerrItemNotFound）
+	GetItem(ctx context.Context, pk, sk string) (OkusuriTable, error)
+	// PutItemIfNotExists は同じキーのアイテムが存在しない場合だけ書き込む（存在する場合はerrConditionFailed）
+	PutItemIfNotExists(ctx context.Context, item OkusuriTable) error
+	// UpdateDataFieldsIfDataEquals は既存アイテムのData内の値が期待値と一致する場合だけData内の値を更新する
+	// （アイテムが存在しない場合や一致しない場合はerrConditionFailed）
+	UpdateDataFieldsIfDataEquals(ctx context.Context, pk, sk string, fields map[string]interface{}, key string, expected interface{}) error
+	// PutItemIfDataEquals は既存アイテムのData内の値が期待値と一致する場合だけ書き込む（一致しない場合はerrConditionFailed）
+	PutItemIfDataEquals(ctx context.Context, item OkusuriTable, key string, expected interface{}) error
+	// DeleteItem はPKとSKが一致するアイテムを削除する（存在しない場合も成功とする）
+	DeleteItem(ctx context.Context, pk, sk string) error
+}
+
+// dynamoStore はguregu/dynamoを使用するitemStoreの実装
+type dynamoStore struct {
+	table dynamo.Table
+}
+
+func newDynamoStore(table dynamo.Table) *dynamoStore {
+	return &dynamoStore{table: table}
+}
+
+func (s *dynamoStore) QueryByGSI1PK(ctx context.Context, gsi1pk string) ([]OkusuriTable, error) {
+	var results []OkusuriTable
+	err := s.table.Get("GSI1PK", gsi1pk).Index(gsi1IndexName).All(ctx, &results)
+	return results, err
+}
+
+func (s *dynamoStore) QueryBySKPrefix(ctx context.Context, pk, prefix string) ([]OkusuriTable, error) {
+	var results []OkusuriTable
+	err := s.table.Get("PK", pk).Range("SK", dynamo.BeginsWith, prefix).All(ctx, &results)
+	return results, err
+}
+
+func (s *dynamoStore) QueryByGSI1PKUpTo(ctx context.Context, gsi1pk, maxGSI1SK string) ([]OkusuriTable, error) {
+	var results []OkusuriTable
+	err := s.table.Get("GSI1PK", gsi1pk).
+		Range("GSI1SK", dynamo.LessOrEqual, maxGSI1SK).
+		Index(gsi1IndexName).
+		All(ctx, &results)
+	return results, err
+}
+
+func (s *dynamoStore) ScanBySK(ctx context.Context, sk string) ([]OkusuriTable, error) {
+	var results []OkusuriTable
+	err := s.table.Scan().Filter("$ = ?", "SK", sk).All(ctx, &results)
+	return results, err
+}
+
+func (s *dynamoStore) PutItemIfNotExists(ctx context.Context, item OkusuriTable) error {
+	err := s.table.Put(item).If("attribute_not_exists($)", "PK").Run(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return errConditionFailed
+	}
+	return err
+}
+
+func (s *dynamoStore) UpdateDataFieldsIfDataEquals(
+	ctx context.Context, pk, sk string, fields map[string]interface{}, key string, expected interface{},
+) error {
+	update := s.table.Update("PK", pk).Range("SK", sk).If("'Data'.$ = ?", key, expected)
+	for key, value := range fields {
+		update = update.Set(fmt.Sprintf("'Data'.'%s'", key), value)
+	}
+	err := update.Run(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return errConditionFailed
+	}
+	return err
+}
+
+func (s *dynamoStore) PutItemIfDataEquals(ctx context.Context, item OkusuriTable, key string, expected interface{}) error {
+	err := s.table.Put(item).If("'Data'.$ = ?", key, expected).Run(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return errConditionFailed
+	}
+	return err
+}
+
+func (s *dynamoStore) GetItem(ctx context.Context, pk, sk string) (OkusuriTable, error) {
+	var result OkusuriTable
+	err := s.table.Get("PK", pk).Range("SK", dynamo.Equal, sk).One(ctx, &result)
+	if errors.Is(err, dynamo.ErrNotFound) {
+		return OkusuriTable{}, errItemNotFound
+	}
+	return result, err
+}
+
+func (s *dynamoStore) DeleteItem(ctx context.Context, pk, sk string) error {
+	return s.table.Delete("PK", pk).Range("SK", sk).Run(ctx)
+}
@@ -8,7 +8,7 @@ cd "$(dirname "$0")/.."
 
 # Dockerイメージをビルド
 cd backend
-docker build -t okusuri-api:latest .
+docker build --build-context shared=../shared -t okusuri-api:latest .
 
 echo "✅ Dockerイメージビルド完了: okusuri-api:latest"
 echo "📝 次のステップ: task deploy:backend でECRにプッシュ"
\ No newline at end of file
@@ -16,7 +16,7 @@ aws ecr get-login-password --region ap-northeast-1 | docker login --username AWS
 # Dockerイメージのビルドとプッシュ
 echo "🐳 Building and pushing API Lambda image..."
 cd ../backend
-docker build -t okusuri-api .
+docker build --build-context shared=../shared -t okusuri-api .
 cd ../infra
 docker tag okusuri-api:latest $ECR_REPO_URL:latest
 docker push $ECR_REPO_URL:latest
@@ -0,0 +1,63 @@
+// Package clock は現在時刻の取得を抽象化し、テストで時刻を固定できるようにする
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock は現在時刻を返す
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System は実際の現在時刻を返すClockを返す
+func System() Clock {
+	return systemClock{}
+}
+
+// Fixed は設定した時刻を返すテスト用のClock
+// SetやAdvanceで時刻を変更するまで同じ時刻を返し続ける
+type Fixed struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixed は指定した時刻を返すFixedを作成する
+func NewFixed(now time.Time) *Fixed {
+	return &Fixed{now: now}
+}
+
+// Now は設定されている時刻を返す
+func (c *Fixed) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set は時刻を変更する
+func (c *Fixed) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance は時刻をdだけ進める
+func (c *Fixed) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// AdvanceDays は時刻を暦日でdays日進める（夏時間の切り替えがあっても時刻は変わらない）
+func (c *Fixed) AdvanceDays(days int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.AddDate(0, 0, days)
+}
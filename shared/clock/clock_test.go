@@ -0,0 +1,24 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixed(t *testing.T) {
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	c := NewFixed(start)
+	assert.Equal(t, start, c.Now())
+	assert.Equal(t, start, c.Now(), "進めるまで同じ時刻を返す")
+
+	c.Advance(90 * time.Minute)
+	assert.Equal(t, start.Add(90*time.Minute), c.Now())
+
+	c.AdvanceDays(14)
+	assert.Equal(t, time.Date(2025, 9, 15, 10, 30, 0, 0, time.UTC), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
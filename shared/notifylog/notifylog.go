@@ -0,0 +1,44 @@
+// Package notifylog は通知の送信履歴（NOTIFYLOG#）アイテムのソートキーの形式を提供する
+// 通知Lambdaは送信のたびに書き込み、バックエンドAPIは期間を指定して読み出すため、同じ形式を共有する
+package notifylog
+
+import (
+	"strings"
+	"time"
+)
+
+// SKPrefix は送信履歴のソートキーの接頭辞（NOTIFYLOG#{送信日時}#{id}）
+const SKPrefix = "NOTIFYLOG#"
+
+// timestampLayout はソートキーに埋め込む送信日時の形式
+// 文字列の順序が時刻の順序と一致するよう、UTCかつ固定長（マイクロ秒まで）にする
+const timestampLayout = "2006-01-02T15:04:05.000000Z"
+
+// SK は送信日時とIDから送信履歴のソートキーを作成する
+// 同じ時刻に複数の端末へ送信してもキーが重複しないよう、IDで区別する
+func SK(at time.Time, id string) string {
+	return Bound(at) + "#" + id
+}
+
+// Bound は指定日時の送信履歴のソートキーの境界を返す
+// 同じ日時に送信した履歴のソートキーはいずれもこの値より大きい
+func Bound(at time.Time) string {
+	return SKPrefix + at.UTC().Format(timestampLayout)
+}
+
+// Parse はソートキーから送信日時とIDを取り出す
+func Parse(sk string) (time.Time, string, bool) {
+	rest, ok := strings.CutPrefix(sk, SKPrefix)
+	if !ok {
+		return time.Time{}, "", false
+	}
+	timestamp, id, ok := strings.Cut(rest, "#")
+	if !ok || id == "" {
+		return time.Time{}, "", false
+	}
+	at, err := time.Parse(timestampLayout, timestamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return at, id, true
+}
@@ -0,0 +1,40 @@
+package notifylog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSK(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	at := time.Date(2025, 9, 1, 8, 0, 0, 123456789, tokyo)
+
+	sk := SK(at, "a1b2")
+	assert.Equal(t, "NOTIFYLOG#2025-08-31T23:00:00.123456Z#a1b2", sk, "UTCのマイクロ秒までの固定長で埋め込む")
+
+	parsed, id, ok := Parse(sk)
+	require.True(t, ok)
+	assert.True(t, parsed.Equal(at.Truncate(time.Microsecond)))
+	assert.Equal(t, "a1b2", id)
+
+	for _, invalid := range []string{"MEDICATION#2025-09-01#x", "NOTIFYLOG#2025-09-01", "NOTIFYLOG#invalid#x", "NOTIFYLOG#2025-08-31T23:00:00.123456Z#"} {
+		_, _, ok := Parse(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestBoundOrdersByTime(t *testing.T) {
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	inside := []string{SK(start, "x"), SK(start.Add(time.Microsecond), "a"), SK(end.Add(-time.Microsecond), "z")}
+	for _, sk := range inside {
+		assert.True(t, Bound(start) < sk && sk < Bound(end), sk)
+	}
+	assert.Greater(t, Bound(end), SK(start.Add(23*time.Hour), "~"))
+	assert.Less(t, Bound(end), SK(end, "0"), "終了日時ちょうどの履歴は範囲外")
+}
@@ -0,0 +1,121 @@
+// Package reminder はリマインダーの時刻を時間帯（バケット）に振り分ける計算を提供する
+// バックエンドAPIはスケジュールの保存時に、通知Lambdaは送信対象の検索時に同じ計算を使う
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Interval は通知Lambdaの実行間隔であり、リマインダー時刻を振り分けるバケットの幅
+const Interval = 5 * time.Minute
+
+// bucketKeyPrefix はGSI1PKに格納するバケットキーの接頭辞
+const bucketKeyPrefix = "REMINDER#"
+
+// clockLayout はリマインダー時刻の形式（HH:MM）
+const clockLayout = "15:04"
+
+// MaxCatchUp は前回の実行から間が空いた場合に遡って処理する期間の上限
+// これより前のリマインダー時刻は、送っても服用のきっかけにならないため送らない
+const MaxCatchUp = time.Hour
+
+// dstTolerance は保存時と送信時でUTCオフセットが変わった（夏時間の切り替えなど）場合に検索する前後の幅
+const dstTolerance = time.Hour
+
+// ErrInvalidClock はリマインダー時刻の形式が不正な場合のエラー
+var ErrInvalidClock = errors.New("invalid reminder time")
+
+// ErrInvalidWeekday は曜日が0（日曜日）〜6（土曜日）の範囲外の場合のエラー
+var ErrInvalidWeekday = errors.New("invalid weekday")
+
+// Schedule はユーザーのタイムゾーンでのリマインダー時刻と曜日
+type Schedule struct {
+	Clock    string         // 通知する時刻（HH:MM形式、ユーザーのタイムゾーン）
+	Weekdays []time.Weekday // 通知する曜日（空の場合は毎日）
+}
+
+// ValidateClock はリマインダー時刻がHH:MM形式かつバケットの境界にあるかを検証する
+func ValidateClock(clock string) error {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil || t.Format(clockLayout) != clock {
+		return fmt.Errorf("%w: %q はHH:MM形式で指定してください", ErrInvalidClock, clock)
+	}
+	if t.Minute()%int(Interval/time.Minute) != 0 {
+		return fmt.Errorf("%w: %q は%d分単位で指定してください", ErrInvalidClock, clock, int(Interval/time.Minute))
+	}
+	return nil
+}
+
+// ValidateWeekdays は曜日が0（日曜日）〜6（土曜日）の範囲にあるかを検証する
+func ValidateWeekdays(weekdays []time.Weekday) error {
+	for _, weekday := range weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("%w: %d", ErrInvalidWeekday, weekday)
+		}
+	}
+	return nil
+}
+
+// Bucket は時刻が属するバケット（UTCのHH:MM）を返す
+func Bucket(t time.Time) string {
+	return t.UTC().Truncate(Interval).Format(clockLayout)
+}
+
+// BucketKey はバケットをGSI1PKに格納するキーに変換する
+func BucketKey(bucket string) string {
+	return bucketKeyPrefix + bucket
+}
+
+// UTCBucket はユーザーのタイムゾーンでのリマインダー時刻が、基準日時点で属するUTCのバケットを返す
+func UTCBucket(clock string, loc *time.Location, ref time.Time) (string, error) {
+	if err := ValidateClock(clock); err != nil {
+		return "", err
+	}
+	t, _ := time.Parse(clockLayout, clock)
+
+	local := ref.In(loc)
+	at := time.Date(local.Year(), local.Month(), local.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	return Bucket(at), nil
+}
+
+// CandidateBuckets は現在時刻に送信対象となりうるバケットキーを返す
+// 保存後にUTCオフセットが変わったスケジュールも拾えるよう、前後のバケットも含める
+func CandidateBuckets(now time.Time) []string {
+	return []string{
+		BucketKey(Bucket(now)),
+		BucketKey(Bucket(now.Add(-dstTolerance))),
+		BucketKey(Bucket(now.Add(dstTolerance))),
+	}
+}
+
+// PendingBuckets は前回処理したバケットの次から現在時刻のバケットまでの、各バケットの開始時刻を古い順に返す
+// 実行時刻がずれて処理されなかったバケットも次の実行で処理できるようにする
+// 前回処理したバケットが不明な場合は現在時刻のバケットだけを、MaxCatchUpより前の場合はMaxCatchUpの範囲だけを返す
+func PendingBuckets(last, now time.Time) []time.Time {
+	current := now.UTC().Truncate(Interval)
+	start := current
+	if !last.IsZero() {
+		start = last.UTC().Truncate(Interval).Add(Interval)
+		if oldest := current.Add(Interval - MaxCatchUp); start.Before(oldest) {
+			start = oldest
+		}
+	}
+
+	var buckets []time.Time
+	for t := start; !t.After(current); t = t.Add(Interval) {
+		buckets = append(buckets, t)
+	}
+	return buckets
+}
+
+// IsDue はユーザーのタイムゾーンで現在時刻がスケジュールの時刻・曜日に一致するかを判定する
+func IsDue(schedule Schedule, loc *time.Location, now time.Time) bool {
+	local := now.In(loc)
+	if len(schedule.Weekdays) > 0 && !slices.Contains(schedule.Weekdays, local.Weekday()) {
+		return false
+	}
+	return local.Truncate(Interval).Format(clockLayout) == schedule.Clock
+}
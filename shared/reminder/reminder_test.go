@@ -0,0 +1,114 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateClock(t *testing.T) {
+	for _, clock := range []string{"00:00", "09:05", "21:30", "23:55"} {
+		assert.NoError(t, ValidateClock(clock), clock)
+	}
+	for _, clock := range []string{"", "9:00", "24:00", "09:03", "09:00:00", "nine"} {
+		assert.ErrorIs(t, ValidateClock(clock), ErrInvalidClock, clock)
+	}
+}
+
+func TestValidateWeekdays(t *testing.T) {
+	assert.NoError(t, ValidateWeekdays(nil))
+	assert.NoError(t, ValidateWeekdays([]time.Weekday{time.Sunday, time.Saturday}))
+	assert.ErrorIs(t, ValidateWeekdays([]time.Weekday{7}), ErrInvalidWeekday)
+	assert.ErrorIs(t, ValidateWeekdays([]time.Weekday{-1}), ErrInvalidWeekday)
+}
+
+func TestUTCBucket(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	winter := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	summer := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		clock string
+		loc   *time.Location
+		ref   time.Time
+		want  string
+	}{
+		{"日本時間の朝はUTCの前日夜", "08:00", tokyo, winter, "23:00"},
+		{"日本時間の夜", "21:30", tokyo, summer, "12:30"},
+		{"ニューヨークの冬時間", "08:00", newYork, winter, "13:00"},
+		{"ニューヨークの夏時間", "08:00", newYork, summer, "12:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UTCBucket(tt.clock, tt.loc, tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = UTCBucket("08:01", tokyo, winter)
+	assert.ErrorIs(t, err, ErrInvalidClock)
+}
+
+func TestCandidateBuckets(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 3, 20, 0, time.UTC)
+	assert.Equal(t, []string{"REMINDER#12:00", "REMINDER#11:00", "REMINDER#13:00"}, CandidateBuckets(now))
+}
+
+func TestPendingBuckets(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 3, 20, 0, time.UTC)
+	at := func(hour, minute int) time.Time { return time.Date(2025, 7, 15, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		last time.Time
+		want []time.Time
+	}{
+		{name: "前回処理したバケットが不明な場合は現在時刻のバケットだけ", want: []time.Time{at(12, 0)}},
+		{name: "前回の次のバケット", last: at(11, 55), want: []time.Time{at(12, 0)}},
+		{name: "実行時刻がずれて処理されなかったバケットを含める", last: at(11, 45), want: []time.Time{at(11, 50), at(11, 55), at(12, 0)}},
+		{name: "現在時刻のバケットを処理済み", last: at(12, 0), want: nil},
+		{name: "長く間が空いた場合は1時間分だけ", last: at(9, 0), want: []time.Time{
+			at(11, 5), at(11, 10), at(11, 15), at(11, 20), at(11, 25), at(11, 30),
+			at(11, 35), at(11, 40), at(11, 45), at(11, 50), at(11, 55), at(12, 0),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PendingBuckets(tt.last, now))
+		})
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 2025-09-01（月）08:02 JST
+	monday := time.Date(2025, 8, 31, 23, 2, 0, 0, time.UTC)
+
+	assert.True(t, IsDue(Schedule{Clock: "08:00"}, tokyo, monday), "曜日未指定は毎日")
+	assert.True(t, IsDue(Schedule{Clock: "08:00", Weekdays: []time.Weekday{time.Monday}}, tokyo, monday))
+	assert.False(t, IsDue(Schedule{Clock: "08:00", Weekdays: []time.Weekday{time.Sunday}}, tokyo, monday),
+		"UTCでは日曜日でもユーザーのタイムゾーンの曜日で判定する")
+	assert.False(t, IsDue(Schedule{Clock: "08:05"}, tokyo, monday))
+
+	t.Run("冬時間に保存したスケジュールを夏時間の候補バケットから拾える", func(t *testing.T) {
+		winterBucket, err := UTCBucket("08:00", newYork, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		// 夏時間の08:00（UTC 12:00）
+		now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+		assert.Contains(t, CandidateBuckets(now), BucketKey(winterBucket))
+		assert.True(t, IsDue(Schedule{Clock: "08:00"}, newYork, now))
+	})
+}
@@ -0,0 +1,60 @@
+package status
+
+// BleedingSeverity は出血の程度
+type BleedingSeverity string
+
+const (
+	BleedingNone     BleedingSeverity = "none"     // 出血なし
+	BleedingSpotting BleedingSeverity = "spotting" // 点状出血（下着に付く程度）
+	BleedingLight    BleedingSeverity = "light"    // 少量
+	BleedingModerate BleedingSeverity = "moderate" // 中等量
+	BleedingHeavy    BleedingSeverity = "heavy"    // 多量
+)
+
+// bleedingSeverityLevels は出血の程度を軽い順に並べたもの
+var bleedingSeverityLevels = []BleedingSeverity{
+	BleedingNone,
+	BleedingSpotting,
+	BleedingLight,
+	BleedingModerate,
+	BleedingHeavy,
+}
+
+// ParseBleedingSeverity は文字列を出血の程度に変換する（不明な値の場合はfalseを返す）
+func ParseBleedingSeverity(value string) (BleedingSeverity, bool) {
+	for _, severity := range bleedingSeverityLevels {
+		if string(severity) == value {
+			return severity, true
+		}
+	}
+	return "", false
+}
+
+// SeverityFromHasBleeding は程度を記録する前の出血の有無を出血の程度に変換する
+// 程度が分からない出血は中等量として扱う
+func SeverityFromHasBleeding(hasBleeding bool) BleedingSeverity {
+	if hasBleeding {
+		return BleedingModerate
+	}
+	return BleedingNone
+}
+
+// IsBleeding は出血があるかどうかを返す（点状出血を含む）
+func (s BleedingSeverity) IsBleeding() bool {
+	return s.level() > 0
+}
+
+// AtLeast は出血の程度がthreshold以上かどうかを返す
+func (s BleedingSeverity) AtLeast(threshold BleedingSeverity) bool {
+	return s.level() >= threshold.level()
+}
+
+// level は出血の程度の順位を返す（出血なし・不明な値は0）
+func (s BleedingSeverity) level() int {
+	for i, severity := range bleedingSeverityLevels {
+		if severity == s {
+			return i
+		}
+	}
+	return 0
+}
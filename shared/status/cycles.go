@@ -0,0 +1,111 @@
+package status
+
+import "time"
+
+// BreakTiming はレジメンに対して休薬に入った時期
+type BreakTiming string
+
+const (
+	BreakTimingOnTime BreakTiming = "on_time" // レジメンどおり
+	BreakTimingEarly  BreakTiming = "early"   // 休薬期間の前から服用していない
+	BreakTimingLate   BreakTiming = "late"    // 休薬期間に入ってからも服用した
+)
+
+// Cycle は服用期間とそれに続く休薬期間からなる1周期
+type Cycle struct {
+	Start                time.Time   // 服用期間の初日
+	IntakeDays           int         // 服用期間の日数
+	TakenDays            int         // 服用期間のうち服用した日数
+	TriggerBleedingDates []time.Time // 休薬のきっかけとなった連続出血の日付（出血で休薬に入った場合、古い順）
+	RestStart            time.Time   // 休薬期間の初日（まだ休薬に入っていない場合はゼロ値）
+	RestEnd              time.Time   // 休薬期間の最終日（休薬中の場合は当日、まだ休薬に入っていない場合はゼロ値）
+	Timing               BreakTiming // 休薬に入った時期（まだ休薬に入っていない場合は空）
+	TimingDays           int         // 休薬期間より前に服用をやめた日数、または休薬期間に入ってから服用した日数
+	Ongoing              bool        // 当日がこの周期の服用期間または休薬期間に含まれるかどうか
+}
+
+// Cycles は最初の服用日から当日までの服用記録を、服用期間とそれに続く休薬期間ごとの周期に古い順に分割する
+// 服用期間・休薬期間はTimelineで判定したフェーズに従い、休薬期間が明けた日から次の周期とする
+func (e *Engine) Cycles(input Input) ([]Cycle, error) {
+	if len(input.Logs) == 0 {
+		return nil, nil
+	}
+	days, err := e.Timeline(input, earliestTakenAt(input.Logs), input.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	var cycles []Cycle
+	for i := 0; i < len(days); {
+		cycle := Cycle{Start: days[i].Date}
+		for ; i < len(days) && days[i].Phase == PhaseIntake; i++ {
+			cycle.IntakeDays++
+			if days[i].Taken {
+				cycle.TakenDays++
+			}
+		}
+		if i == len(days) {
+			cycle.Ongoing = true
+			cycles = append(cycles, cycle)
+			break
+		}
+
+		restIndex := i
+		cycle.RestStart = days[restIndex].Date
+		for ; i < len(days) && days[i].Phase == PhaseRest; i++ {
+			cycle.RestEnd = days[i].Date
+		}
+		cycle.Ongoing = i == len(days)
+		cycle.TriggerBleedingDates = triggerBleedingDates(days, restIndex, input.Regimen)
+		cycle.Timing, cycle.TimingDays = breakTiming(days, restIndex, len(cycle.TriggerBleedingDates) > 0)
+		cycles = append(cycles, cycle)
+	}
+	return cycles, nil
+}
+
+// triggerBleedingDates は休薬期間の初日まで続くしきい値以上の連続出血が休薬に入る日数に達している場合、その日付を古い順に返す
+// 出血で休薬に入るのはフレキシブル投与だけのため、それ以外のレジメンではnilを返す
+func triggerBleedingDates(days []Day, restIndex int, regimen Regimen) []time.Time {
+	if regimen.normalizedType() != RegimenTypeFlexibleExtended || regimen.BleedingTriggerDays <= 0 {
+		return nil
+	}
+
+	first := restIndex
+	for first > 0 && regimen.countsAsBleeding(days[first-1].Severity) {
+		first--
+	}
+	if !regimen.countsAsBleeding(days[restIndex].Severity) || restIndex-first+1 < regimen.BleedingTriggerDays {
+		return nil
+	}
+
+	dates := make([]time.Time, 0, restIndex-first+1)
+	for _, day := range days[first : restIndex+1] {
+		dates = append(dates, day.Date)
+	}
+	return dates
+}
+
+// breakTiming は休薬期間の前後の服用状況から休薬に入った時期を判定する
+// 出血で休薬に入った場合、休薬期間の初日の服用はその日の出血を記録したものとして数えない
+func breakTiming(days []Day, restIndex int, triggeredByBleeding bool) (BreakTiming, int) {
+	lateDays := 0
+	start := restIndex
+	if triggeredByBleeding {
+		start++
+	}
+	for i := start; i < len(days) && days[i].Phase == PhaseRest && days[i].Taken; i++ {
+		lateDays++
+	}
+	if lateDays > 0 {
+		return BreakTimingLate, lateDays
+	}
+
+	earlyDays := 0
+	for i := restIndex - 1; i >= 0 && days[i].Phase == PhaseIntake && !days[i].Taken; i-- {
+		earlyDays++
+	}
+	if earlyDays > 0 {
+		return BreakTimingEarly, earlyDays
+	}
+	return BreakTimingOnTime, 0
+}
@@ -0,0 +1,118 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineCycles(t *testing.T) {
+	fixed := Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7, CycleStartDate: "2025-09-01"}
+
+	tests := []struct {
+		name    string
+		regimen Regimen
+		logs    []Log
+		now     time.Time
+		want    []Cycle
+	}{
+		{
+			// 出血で休薬に入った日は休薬期間に含め、休薬明けの日から次の周期とする
+			name:    "フレキシブル: 出血のたびに周期が区切られる",
+			regimen: DefaultRegimen(),
+			logs:    statsScenario(),
+			now:     time.Date(2025, 10, 31, 12, 0, 0, 0, statsJST),
+			want: []Cycle{
+				{
+					Start:                jstDate(9, 1),
+					IntakeDays:           22,
+					TakenDays:            22,
+					TriggerBleedingDates: []time.Time{jstDate(9, 21), jstDate(9, 22), jstDate(9, 23)},
+					RestStart:            jstDate(9, 23),
+					RestEnd:              jstDate(9, 25),
+					Timing:               BreakTimingOnTime,
+				},
+				{
+					Start:                jstDate(9, 26),
+					IntakeDays:           27,
+					TakenDays:            26,
+					TriggerBleedingDates: []time.Time{jstDate(10, 21), jstDate(10, 22), jstDate(10, 23)},
+					RestStart:            jstDate(10, 23),
+					RestEnd:              jstDate(10, 25),
+					Timing:               BreakTimingOnTime,
+				},
+				{Start: jstDate(10, 26), IntakeDays: 6, TakenDays: 5, Ongoing: true},
+			},
+		},
+		{
+			name:    "フレキシブル: 休薬に入ってからも服用した",
+			regimen: DefaultRegimen(),
+			logs: concat(
+				dailyLogs(jstDate(9, 1), jstDate(9, 20), false),
+				dailyLogs(jstDate(9, 21), jstDate(9, 24), true),
+			),
+			now: time.Date(2025, 9, 27, 12, 0, 0, 0, statsJST),
+			want: []Cycle{
+				{
+					Start:                jstDate(9, 1),
+					IntakeDays:           22,
+					TakenDays:            22,
+					TriggerBleedingDates: []time.Time{jstDate(9, 21), jstDate(9, 22), jstDate(9, 23)},
+					RestStart:            jstDate(9, 23),
+					RestEnd:              jstDate(9, 25),
+					Timing:               BreakTimingLate,
+					TimingDays:           1,
+				},
+				{Start: jstDate(9, 26), IntakeDays: 2, Ongoing: true},
+			},
+		},
+		{
+			// 1周期目は休薬の2日前に服用をやめ、2周期目は休薬期間に入ってから2日服用した
+			name:    "周期投与: 休薬の前倒しと後ろ倒し",
+			regimen: fixed,
+			logs: concat(
+				dailyLogs(jstDate(9, 1), jstDate(9, 19), false),
+				dailyLogs(jstDate(9, 29), jstDate(10, 21), false),
+			),
+			now: time.Date(2025, 10, 24, 12, 0, 0, 0, statsJST),
+			want: []Cycle{
+				{
+					Start:      jstDate(9, 1),
+					IntakeDays: 21,
+					TakenDays:  19,
+					RestStart:  jstDate(9, 22),
+					RestEnd:    jstDate(9, 28),
+					Timing:     BreakTimingEarly,
+					TimingDays: 2,
+				},
+				{
+					Start:      jstDate(9, 29),
+					IntakeDays: 21,
+					TakenDays:  21,
+					RestStart:  jstDate(10, 20),
+					RestEnd:    jstDate(10, 24),
+					Timing:     BreakTimingLate,
+					TimingDays: 2,
+					Ongoing:    true,
+				},
+			},
+		},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Cycles(Input{Logs: tt.logs, Now: tt.now, Regimen: tt.regimen, Location: statsJST})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEngineCyclesWithoutLogs(t *testing.T) {
+	got, err := NewEngine().Cycles(Input{Now: time.Now(), Regimen: DefaultRegimen()})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
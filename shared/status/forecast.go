@@ -0,0 +1,62 @@
+package status
+
+import "time"
+
+// maxForecastDays は残りの錠数がなくなる日を予測する最大の日数
+const maxForecastDays = 366
+
+// Forecast は手持ちの錠数から予測した服用の見通し
+type Forecast struct {
+	Doses        int       // 手持ちで服用できる回数
+	LastDoseDate time.Time // 手持ちで服用できる最後の日（1回分もない場合はゼロ値）
+	RunOutDate   time.Time // 手持ちがなく服用できなくなる最初の服用予定日（1年以内になくならない場合はゼロ値）
+}
+
+// ForecastRunOut は手持ちの錠数（1回pillsPerDose錠）がなくなる日を予測する
+// 当日に服用していない場合は当日から、服用済みの場合は翌日から、服用期間の日は出血なしで服用し
+// 休薬期間の日は服用しないものとして日ごとにステータスを計算するため、今後の休薬期間の分だけ後ろにずれる
+func (e *Engine) ForecastRunOut(input Input, pills, pillsPerDose int) (Forecast, error) {
+	strategy, ok := e.strategies[input.Regimen.normalizedType()]
+	if !ok {
+		return Forecast{}, ErrUnsupportedRegimenType
+	}
+
+	forecast := Forecast{}
+	if pillsPerDose > 0 && pills > 0 {
+		forecast.Doses = pills / pillsPerDose
+	}
+
+	loc := input.Location
+	if loc == nil {
+		loc = input.Now.Location()
+	}
+	logs := sortedLogs(input.Logs, loc)
+	today := truncateToDay(input.Now.In(loc))
+	start := today
+	if len(logs) > 0 && !logs[0].TakenAt.Before(today) {
+		start = today.AddDate(0, 0, 1)
+	}
+
+	remaining := forecast.Doses
+	for date := start; daysBetween(today, date) <= maxForecastDays; date = date.AddDate(0, 0, 1) {
+		result := strategy.Evaluate(Input{
+			Logs:     logs,
+			Now:      date,
+			Regimen:  input.Regimen,
+			Location: loc,
+		})
+		if result.Phase == PhaseRest {
+			continue
+		}
+		if remaining == 0 {
+			forecast.RunOutDate = date
+			break
+		}
+
+		// 予測した服用を最新の服用記録として加える（logsは新しい順）
+		remaining--
+		forecast.LastDoseDate = date
+		logs = append([]Log{{TakenAt: date, Severity: BleedingNone}}, logs...)
+	}
+	return forecast, nil
+}
@@ -0,0 +1,119 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineForecastRunOut(t *testing.T) {
+	engine := NewEngine()
+	continuous := Regimen{Type: RegimenTypeContinuous}
+	fixed := Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7, CycleStartDate: "2025-09-01"}
+
+	// 9/1〜9/20に服用し、9/21〜9/23の出血で9/23〜9/25に休薬する
+	bleeding := append(dailyLogs(jstDate(9, 1), jstDate(9, 20), false), dailyLogs(jstDate(9, 21), jstDate(9, 23), true)...)
+
+	tests := []struct {
+		name         string
+		logs         []Log
+		now          time.Time
+		regimen      Regimen
+		pills        int
+		pillsPerDose int
+		want         Forecast
+	}{
+		{
+			name:         "記録がない場合は当日から毎日服用する",
+			now:          jstDate(9, 1).Add(10 * time.Hour),
+			regimen:      continuous,
+			pills:        10,
+			pillsPerDose: 1,
+			want:         Forecast{Doses: 10, LastDoseDate: jstDate(9, 10), RunOutDate: jstDate(9, 11)},
+		},
+		{
+			name:         "当日に服用済みの場合は翌日から服用する",
+			logs:         dailyLogs(jstDate(8, 25), jstDate(9, 1), false),
+			now:          jstDate(9, 1).Add(10 * time.Hour),
+			regimen:      continuous,
+			pills:        10,
+			pillsPerDose: 1,
+			want:         Forecast{Doses: 10, LastDoseDate: jstDate(9, 11), RunOutDate: jstDate(9, 12)},
+		},
+		{
+			name:         "1回に複数錠を服用する場合は端数を数えない",
+			logs:         dailyLogs(jstDate(8, 25), jstDate(9, 1), false),
+			now:          jstDate(9, 1).Add(10 * time.Hour),
+			regimen:      continuous,
+			pills:        7,
+			pillsPerDose: 2,
+			want:         Forecast{Doses: 3, LastDoseDate: jstDate(9, 4), RunOutDate: jstDate(9, 5)},
+		},
+		{
+			name:         "周期投与では今後の休薬期間を飛ばす",
+			logs:         dailyLogs(jstDate(9, 1), jstDate(9, 20), false),
+			now:          jstDate(9, 20).Add(10 * time.Hour),
+			regimen:      fixed,
+			pills:        10,
+			pillsPerDose: 1,
+			want:         Forecast{Doses: 10, LastDoseDate: jstDate(10, 7), RunOutDate: jstDate(10, 8)},
+		},
+		{
+			name:         "休薬期間中は休薬が明けてから服用する",
+			logs:         bleeding,
+			now:          jstDate(9, 24).Add(10 * time.Hour),
+			regimen:      DefaultRegimen(),
+			pills:        3,
+			pillsPerDose: 1,
+			want:         Forecast{Doses: 3, LastDoseDate: jstDate(9, 28), RunOutDate: jstDate(9, 29)},
+		},
+		{
+			name:         "手持ちがない場合は次の服用予定日になくなる",
+			logs:         bleeding,
+			now:          jstDate(9, 24).Add(10 * time.Hour),
+			regimen:      DefaultRegimen(),
+			pills:        0,
+			pillsPerDose: 1,
+			want:         Forecast{RunOutDate: jstDate(9, 26)},
+		},
+		{
+			name:         "上限日数の連続服用による休薬を飛ばす",
+			logs:         dailyLogs(jstDate(9, 1), jstDate(9, 10), false),
+			now:          jstDate(9, 10).Add(10 * time.Hour),
+			regimen:      Regimen{Type: RegimenTypeFlexibleExtended, RestPeriodDays: 4, BleedingTriggerDays: 3, MaxContinuousDays: 14},
+			pills:        8,
+			pillsPerDose: 1,
+			// 9/11〜9/14に服用し、9/15〜9/18に休薬する
+			want: Forecast{Doses: 8, LastDoseDate: jstDate(9, 22), RunOutDate: jstDate(9, 23)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.ForecastRunOut(Input{
+				Logs:     tt.logs,
+				Now:      tt.now,
+				Regimen:  tt.regimen,
+				Location: statsJST,
+			}, tt.pills, tt.pillsPerDose)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Doses, got.Doses)
+			assert.Equal(t, tt.want.LastDoseDate.Format(dateLayout), got.LastDoseDate.Format(dateLayout))
+			assert.Equal(t, tt.want.RunOutDate.Format(dateLayout), got.RunOutDate.Format(dateLayout))
+		})
+	}
+
+	t.Run("1年以内になくならない場合は予測しない", func(t *testing.T) {
+		got, err := engine.ForecastRunOut(Input{Now: jstDate(9, 1), Regimen: continuous, Location: statsJST}, 1000, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, got.Doses)
+		assert.True(t, got.RunOutDate.IsZero())
+	})
+
+	t.Run("未対応のレジメン種別はエラーを返す", func(t *testing.T) {
+		_, err := engine.ForecastRunOut(Input{Now: jstDate(9, 1), Regimen: Regimen{Type: "unknown"}}, 10, 1)
+		assert.ErrorIs(t, err, ErrUnsupportedRegimenType)
+	})
+}
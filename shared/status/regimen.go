@@ -0,0 +1,84 @@
+package status
+
+import (
+	"fmt"
+	"time"
+)
+
+// レジメンの種類
+const (
+	RegimenTypeFlexibleExtended = "flexible_extended" // 連続出血で休薬に入るフレキシブル長期投与
+	RegimenTypeFixedCycle       = "fixed_cycle"       // 決まった日数ごとに休薬する周期投与（21/7、24/4など）
+	RegimenTypeContinuous       = "continuous"        // 休薬を設けない連続投与
+)
+
+// Regimen はステータス計算に使用する服薬ルール
+type Regimen struct {
+	Type                    string // レジメンの種類（空の場合はフレキシブル投与として扱う）
+	RestPeriodDays          int    // 休薬期間の日数
+	BleedingTriggerDays     int    // 休薬に入る連続出血日数（フレキシブル投与）
+	MinIntakeDaysBeforeRest int    // 休薬を開始できる最低連続服用日数（0の場合は制限なし）
+	MaxContinuousDays       int    // 強制的に休薬に入る連続服用日数（フレキシブル投与、0の場合は上限なし）
+	ActiveDays              int    // 1周期あたりの服用日数（周期投与）
+	CycleStartDate          string // 周期の起点日（YYYY-MM-DD形式、周期投与で空の場合は最初の服用日）
+	// BleedingThreshold は休薬に入る連続出血として数える出血の最低の程度（空の場合は点状出血を含む全ての出血）
+	BleedingThreshold BleedingSeverity
+}
+
+// DefaultRegimen はレジメン未設定のユーザーに適用する服薬ルールを返す
+// 連続3日間の出血で4日間の休薬に入る従来のルールと同じ
+func DefaultRegimen() Regimen {
+	return Regimen{
+		Type:                    RegimenTypeFlexibleExtended,
+		RestPeriodDays:          4,
+		BleedingTriggerDays:     3,
+		MinIntakeDaysBeforeRest: 0,
+	}
+}
+
+// Validate はレジメンの種類ごとに必要な設定値が揃っているかを検証する
+// 種類が登録されているかどうかはEngine.Supportsで確認する
+func (r Regimen) Validate() error {
+	if r.CycleStartDate != "" {
+		if _, err := time.Parse(dateLayout, r.CycleStartDate); err != nil {
+			return fmt.Errorf("cycleStartDateはYYYY-MM-DD形式で指定してください")
+		}
+	}
+
+	if r.BleedingThreshold != "" {
+		if severity, ok := ParseBleedingSeverity(string(r.BleedingThreshold)); !ok || !severity.IsBleeding() {
+			return fmt.Errorf("bleedingThresholdはspotting・light・moderate・heavyのいずれかを指定してください")
+		}
+	}
+
+	switch r.Type {
+	case RegimenTypeFlexibleExtended, "":
+		if r.RestPeriodDays < 1 || r.BleedingTriggerDays < 1 {
+			return fmt.Errorf("restPeriodDaysとbleedingTriggerDaysは1以上を指定してください")
+		}
+		if r.MaxContinuousDays != 0 && r.MaxContinuousDays < r.MinIntakeDaysBeforeRest {
+			return fmt.Errorf("maxContinuousDaysはminIntakeDaysBeforeRest以上を指定してください")
+		}
+	case RegimenTypeFixedCycle:
+		if r.ActiveDays < 1 || r.RestPeriodDays < 1 {
+			return fmt.Errorf("activeDaysとrestPeriodDaysは1以上を指定してください")
+		}
+	}
+	return nil
+}
+
+// normalizedType は種類が未指定の場合にフレキシブル投与を返す
+func (r Regimen) normalizedType() string {
+	if r.Type == "" {
+		return RegimenTypeFlexibleExtended
+	}
+	return r.Type
+}
+
+// countsAsBleeding は出血の程度が休薬に入る連続出血として数えるしきい値以上かどうかを返す
+func (r Regimen) countsAsBleeding(severity BleedingSeverity) bool {
+	if !severity.IsBleeding() {
+		return false
+	}
+	return r.BleedingThreshold == "" || severity.AtLeast(r.BleedingThreshold)
+}
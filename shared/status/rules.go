@@ -0,0 +1,223 @@
+package status
+
+import (
+	"sort"
+	"time"
+)
+
+// calculateRestPeriodStatus は休薬期間の状態を計算する
+func calculateRestPeriodStatus(logs []Log, now time.Time, regimen Regimen) (bool, int, int) {
+	// 日付ごとに整理したログを取得（同じ日の重複を除去）
+	dateLogMap := make(map[string]Log)
+	for _, log := range logs {
+		dateStr := log.TakenAt.Format(dateLayout)
+		// 同じ日付の場合は最新のログを使用
+		// logsは新しい順にソートされているため、最初に見つけたログが最新
+		if _, exists := dateLogMap[dateStr]; !exists {
+			dateLogMap[dateStr] = log
+		}
+	}
+
+	// 日付を過去順（降順）にソート
+	var dates []time.Time
+	for _, log := range dateLogMap {
+		dates = append(dates, log.TakenAt)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+
+	// 連続出血日数を計算
+	consecutiveBleedingDays := 0
+	var consecutiveBleedingDates []time.Time
+	lastDate := time.Time{}
+
+	for _, date := range dates {
+		// 日付を正規化（時間部分を削除）
+		currDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+		// 日付の文字列を取得
+		dateStr := currDate.Format(dateLayout)
+		log := dateLogMap[dateStr]
+
+		if regimen.countsAsBleeding(log.severity()) {
+			// 初めての出血日または連続している場合
+			if consecutiveBleedingDays == 0 || lastDate.IsZero() {
+				consecutiveBleedingDays = 1
+				consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
+			} else {
+				// 前日からの連続か確認（夏時間の切り替え日は23時間/25時間になるため日数に丸める）
+				if daysBetween(currDate, lastDate) == 1 {
+					consecutiveBleedingDays++
+					consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
+				} else {
+					// 日付が連続していない場合はリセット
+					consecutiveBleedingDays = 1
+					consecutiveBleedingDates = []time.Time{currDate}
+				}
+			}
+		} else {
+			// 出血がない場合はリセット
+			break
+		}
+
+		lastDate = currDate
+	}
+
+	// 規定日数以上の連続出血がある場合、休薬期間判定（出血で休薬に入らないレジメンでは判定しない）
+	trigger := regimen.BleedingTriggerDays
+	if trigger > 0 && consecutiveBleedingDays >= trigger && len(consecutiveBleedingDates) >= trigger &&
+		isRestAllowed(dates, consecutiveBleedingDates[len(consecutiveBleedingDates)-1], regimen) {
+		// 休薬開始日は連続出血の最初の日
+		restStartDate := consecutiveBleedingDates[len(consecutiveBleedingDates)-1]
+
+		// 休薬終了日は休薬開始日から休薬日数後の終日
+		restEndDate := restStartDate.AddDate(0, 0, regimen.RestPeriodDays)
+		restEndDate = time.Date(
+			restEndDate.Year(), restEndDate.Month(), restEndDate.Day(),
+			23, 59, 59, 0, restEndDate.Location(),
+		)
+
+		// 現在が休薬期間内かどうか
+		if now.Before(restEndDate) {
+			// 残り日数を計算（今日を含めて休薬終了日までの日数）
+			daysLeft := daysBetween(truncateToDay(now), truncateToDay(restEndDate)) + 1
+			return true, daysLeft, consecutiveBleedingDays
+		}
+	}
+
+	return false, 0, consecutiveBleedingDays
+}
+
+// calculateCurrentStreak は現在の連続服用日数を計算する
+func calculateCurrentStreak(logs []Log, now time.Time, regimen Regimen) int {
+	uniqueDates := extractUniqueDates(logs)
+	lastRestPeriodEndDate := findLastRestPeriodEndDate(logs, uniqueDates, regimen)
+	return countConsecutiveDays(uniqueDates, lastRestPeriodEndDate, now)
+}
+
+// findLastRestPeriodEndDate は最後の休薬期間終了日を探す
+func findLastRestPeriodEndDate(logs []Log, uniqueDates []time.Time, regimen Regimen) time.Time {
+	trigger := regimen.BleedingTriggerDays
+	if trigger <= 0 {
+		return time.Time{}
+	}
+	consecutiveBleedingCount := 0
+	var bleedingDates []time.Time
+
+	for i, log := range logs {
+		if regimen.countsAsBleeding(log.severity()) {
+			consecutiveBleedingCount++
+			bleedingDates = append(bleedingDates, log.TakenAt)
+
+			if consecutiveBleedingCount >= trigger {
+				oldestBleedingDate := bleedingDates[len(bleedingDates)-1]
+				if isRestAllowed(uniqueDates, oldestBleedingDate, regimen) {
+					return oldestBleedingDate.AddDate(0, 0, regimen.RestPeriodDays)
+				}
+			}
+		} else {
+			consecutiveBleedingCount = 0
+			bleedingDates = nil
+
+			// 最低服用日数を満たさず休薬に入らなかった出血は休薬期間として扱わない
+			if i >= trigger && precededByBleeding(logs, i, trigger, regimen) &&
+				isRestAllowed(uniqueDates, logs[i-1].TakenAt, regimen) {
+				return log.TakenAt
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// precededByBleeding は logs[i] の直前（より新しい側）に規定件数のしきい値以上の出血記録が続いているかを判定する
+func precededByBleeding(logs []Log, i int, count int, regimen Regimen) bool {
+	for j := 1; j <= count; j++ {
+		if !regimen.countsAsBleeding(logs[i-j].severity()) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRestAllowed は出血開始日までの連続服用日数が、休薬を開始できる最低日数を満たしているかを判定する
+// datesは重複を除去した服用日の降順リスト
+func isRestAllowed(dates []time.Time, bleedingStart time.Time, regimen Regimen) bool {
+	if regimen.MinIntakeDaysBeforeRest <= 0 {
+		return true
+	}
+
+	// 出血開始日から遡って、途切れずに服用している日数を数える
+	triggerDay := truncateToDay(bleedingStart).AddDate(0, 0, regimen.BleedingTriggerDays-1)
+	intakeDays := 0
+	var lastDate time.Time
+	for _, date := range dates {
+		currDate := truncateToDay(date)
+		if currDate.After(triggerDay) {
+			continue
+		}
+		if !lastDate.IsZero() && daysBetween(currDate, lastDate) != 1 {
+			break
+		}
+		intakeDays++
+		lastDate = currDate
+	}
+
+	return intakeDays >= regimen.MinIntakeDaysBeforeRest
+}
+
+// truncateToDay は時刻部分を切り捨てた日付を返す
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// extractUniqueDates は重複を除去した日付リストを取得する
+func extractUniqueDates(logs []Log) []time.Time {
+	dateMap := make(map[string]time.Time)
+	for _, log := range logs {
+		dateStr := log.TakenAt.Format(dateLayout)
+		if _, exists := dateMap[dateStr]; !exists {
+			dateMap[dateStr] = log.TakenAt
+		}
+	}
+
+	var dates []time.Time
+	for _, date := range dateMap {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+	return dates
+}
+
+// countConsecutiveDays は休薬期間後からの連続日数をカウントする
+func countConsecutiveDays(dates []time.Time, restEndDate time.Time, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+
+	streak := 0
+	lastDate := today
+
+	for _, date := range dates {
+		currDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+		if !restEndDate.IsZero() && currDate.Before(restEndDate) {
+			break
+		}
+
+		if currDate.Equal(today) || currDate.Equal(yesterday) {
+			streak++
+			lastDate = currDate
+			continue
+		}
+
+		if daysBetween(currDate, lastDate) == 1 {
+			streak++
+			lastDate = currDate
+		} else {
+			break
+		}
+	}
+	return streak
+}
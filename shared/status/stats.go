@@ -0,0 +1,123 @@
+package status
+
+import "time"
+
+// Stats は期間内の服薬の統計
+type Stats struct {
+	LongestStreak       int            // 期間内の最長連続服用日数
+	BleedingDays        int            // 出血があった日数
+	MonthlyBleedingDays []MonthlyCount // 月ごとの出血日数（期間内の全ての月、古い順）
+	RestPeriods         []Period       // 期間と重なる休薬期間（古い順）
+	AverageCycleLength  float64        // 休薬開始日から次の休薬開始日までの平均日数（休薬期間が2回未満の場合は0）
+	ScheduledDays       int            // 服用期間だった日数
+	TakenDays           int            // 服用期間だった日のうち服用した日数
+	AdherenceRate       float64        // 服用率（%、服用期間だった日がない場合は0）
+}
+
+// MonthlyCount は月ごとの日数
+type MonthlyCount struct {
+	Month time.Time // 月の初日
+	Days  int
+}
+
+// Period は休薬期間
+type Period struct {
+	Start time.Time // 休薬期間の初日
+	End   time.Time // 休薬期間の最終日（休薬中の場合は当日）
+}
+
+// Stats はfromからtoまでの服薬の統計を計算する（toが当日より後の場合は当日まで）
+// 休薬期間はTimelineで休薬期間と判定された日が続く期間とし、期間の境界をまたぐ場合も実際の初日・最終日を返す
+// 服用率は最初の服用日以降の服用期間の日のうち服用した日の割合とし、当日は服用済みの場合だけ数える
+func (e *Engine) Stats(input Input, from, to time.Time) (Stats, error) {
+	loc := input.Location
+	if loc == nil {
+		loc = input.Now.Location()
+	}
+	start := truncateToDay(from.In(loc))
+	end := truncateToDay(to.In(loc))
+	today := truncateToDay(input.Now.In(loc))
+	if end.After(today) {
+		end = today
+	}
+
+	stats := Stats{}
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, loc); !month.After(end); month = month.AddDate(0, 1, 0) {
+		stats.MonthlyBleedingDays = append(stats.MonthlyBleedingDays, MonthlyCount{Month: month})
+	}
+
+	// 休薬期間やその日の連続服用日数は過去の記録から決まるため、最初の服用日から計算する
+	if len(input.Logs) == 0 {
+		return stats, nil
+	}
+	days, err := e.Timeline(input, earliestTakenAt(input.Logs), end)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	var current *Period
+	for _, day := range days {
+		if day.Phase == PhaseRest {
+			if current == nil {
+				current = &Period{Start: day.Date}
+			}
+			current.End = day.Date
+		} else if current != nil {
+			stats.addRestPeriod(*current, start)
+			current = nil
+		}
+
+		if day.Date.Before(start) {
+			continue
+		}
+		if day.Streak > stats.LongestStreak {
+			stats.LongestStreak = day.Streak
+		}
+		if day.Bleeding {
+			stats.BleedingDays++
+			for i := range stats.MonthlyBleedingDays {
+				month := stats.MonthlyBleedingDays[i].Month
+				if month.Year() == day.Date.Year() && month.Month() == day.Date.Month() {
+					stats.MonthlyBleedingDays[i].Days++
+				}
+			}
+		}
+		if day.Phase == PhaseIntake && (day.Taken || day.Date.Before(today)) {
+			stats.ScheduledDays++
+			if day.Taken {
+				stats.TakenDays++
+			}
+		}
+	}
+	if current != nil {
+		stats.addRestPeriod(*current, start)
+	}
+
+	if len(stats.RestPeriods) >= 2 {
+		periods := stats.RestPeriods
+		total := daysBetween(periods[0].Start, periods[len(periods)-1].Start)
+		stats.AverageCycleLength = float64(total) / float64(len(periods)-1)
+	}
+	if stats.ScheduledDays > 0 {
+		stats.AdherenceRate = float64(stats.TakenDays) / float64(stats.ScheduledDays) * 100
+	}
+	return stats, nil
+}
+
+// addRestPeriod は期間の開始日以降に終わる休薬期間を追加する
+func (s *Stats) addRestPeriod(period Period, start time.Time) {
+	if !period.End.Before(start) {
+		s.RestPeriods = append(s.RestPeriods, period)
+	}
+}
+
+// earliestTakenAt は最初の服用日時を返す（logsは順不同、空でないこと）
+func earliestTakenAt(logs []Log) time.Time {
+	first := logs[0].TakenAt
+	for _, log := range logs[1:] {
+		if log.TakenAt.Before(first) {
+			first = log.TakenAt
+		}
+	}
+	return first
+}
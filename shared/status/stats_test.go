@@ -0,0 +1,163 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statsJST = time.FixedZone("JST", 9*60*60)
+
+// jstDate は日本時間の日付（0時）を返す
+func jstDate(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 0, 0, 0, 0, statsJST)
+}
+
+// dailyLogs はfromからtoまで毎朝8時（日本時間）に服用した記録を作成する（skipの日は服用しない）
+func dailyLogs(from, to time.Time, hasBleeding bool, skip ...time.Time) []Log {
+	var logs []Log
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		skipped := false
+		for _, s := range skip {
+			skipped = skipped || s.Equal(date)
+		}
+		if !skipped {
+			logs = append(logs, Log{TakenAt: date.Add(8 * time.Hour), HasBleeding: hasBleeding})
+		}
+	}
+	return logs
+}
+
+// statsScenario は2か月分の服用記録（日本時間）
+// 9/21〜9/23と10/21〜10/23に出血して休薬し、10/5は飲み忘れ、10/31（当日）はまだ服用していない
+func statsScenario() []Log {
+	return concat(
+		dailyLogs(jstDate(9, 1), jstDate(9, 20), false),
+		dailyLogs(jstDate(9, 21), jstDate(9, 23), true),
+		dailyLogs(jstDate(9, 26), jstDate(10, 20), false, jstDate(10, 5)),
+		dailyLogs(jstDate(10, 21), jstDate(10, 23), true),
+		dailyLogs(jstDate(10, 26), jstDate(10, 30), false),
+	)
+}
+
+func TestEngineStats(t *testing.T) {
+	// 2025-10-31 12:00（日本時間、UTCでは03:00）
+	now := time.Date(2025, 10, 31, 3, 0, 0, 0, time.UTC)
+	input := Input{Logs: statsScenario(), Now: now, Regimen: DefaultRegimen(), Location: statsJST}
+	september := Period{Start: jstDate(9, 23), End: jstDate(9, 25)}
+	october := Period{Start: jstDate(10, 23), End: jstDate(10, 25)}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     Stats
+	}{
+		{
+			// 休薬期間は3日連続の出血で休薬に入った日から出血初日の4日後まで
+			// 服用期間の日は休薬期間（6日）と服用前の当日を除く54日で、飲み忘れた10/5以外は服用した
+			name: "全期間",
+			from: jstDate(9, 1),
+			to:   jstDate(10, 31),
+			want: Stats{
+				LongestStreak: 22,
+				BleedingDays:  6,
+				MonthlyBleedingDays: []MonthlyCount{
+					{Month: jstDate(9, 1), Days: 3},
+					{Month: jstDate(10, 1), Days: 3},
+				},
+				RestPeriods:        []Period{september, october},
+				AverageCycleLength: 30,
+				ScheduledDays:      54,
+				TakenDays:          53,
+				AdherenceRate:      float64(53) / 54 * 100,
+			},
+		},
+		{
+			// 連続服用日数は休薬明けから数え、飲み忘れで途切れる（10/6〜10/22の17日）
+			name: "10月だけ",
+			from: jstDate(10, 1),
+			to:   jstDate(10, 31),
+			want: Stats{
+				LongestStreak:       17,
+				BleedingDays:        3,
+				MonthlyBleedingDays: []MonthlyCount{{Month: jstDate(10, 1), Days: 3}},
+				RestPeriods:         []Period{october},
+				ScheduledDays:       27,
+				TakenDays:           26,
+				AdherenceRate:       float64(26) / 27 * 100,
+			},
+		},
+		{
+			name: "期間の境界をまたぐ休薬期間は実際の初日から返す",
+			from: jstDate(9, 24),
+			to:   jstDate(9, 30),
+			want: Stats{
+				LongestStreak:       5,
+				MonthlyBleedingDays: []MonthlyCount{{Month: jstDate(9, 1)}},
+				RestPeriods:         []Period{september},
+				ScheduledDays:       5,
+				TakenDays:           5,
+				AdherenceRate:       100,
+			},
+		},
+		{
+			name: "当日より後は数えない",
+			from: jstDate(10, 26),
+			to:   jstDate(11, 30),
+			want: Stats{
+				LongestStreak:       5,
+				MonthlyBleedingDays: []MonthlyCount{{Month: jstDate(10, 1)}},
+				ScheduledDays:       5,
+				TakenDays:           5,
+				AdherenceRate:       100,
+			},
+		},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Stats(input, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.LongestStreak, got.LongestStreak)
+			assert.Equal(t, tt.want.BleedingDays, got.BleedingDays)
+			assert.Equal(t, tt.want.MonthlyBleedingDays, got.MonthlyBleedingDays)
+			assert.Equal(t, tt.want.RestPeriods, got.RestPeriods)
+			assert.Equal(t, tt.want.AverageCycleLength, got.AverageCycleLength)
+			assert.Equal(t, tt.want.ScheduledDays, got.ScheduledDays)
+			assert.Equal(t, tt.want.TakenDays, got.TakenDays)
+			assert.InDelta(t, tt.want.AdherenceRate, got.AdherenceRate, 1e-9)
+		})
+	}
+}
+
+func TestEngineStatsWithoutLogs(t *testing.T) {
+	now := time.Date(2025, 10, 31, 3, 0, 0, 0, time.UTC)
+	got, err := NewEngine().Stats(Input{Now: now, Regimen: DefaultRegimen(), Location: statsJST}, jstDate(9, 15), jstDate(10, 31))
+	require.NoError(t, err)
+	assert.Equal(t, Stats{
+		MonthlyBleedingDays: []MonthlyCount{{Month: jstDate(9, 1)}, {Month: jstDate(10, 1)}},
+	}, got)
+}
+
+func TestEngineTimeline(t *testing.T) {
+	// 21日服用・7日休薬の周期投与を9/1から開始し、毎日服用する
+	regimen := Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7, CycleStartDate: "2025-09-01"}
+	logs := dailyLogs(jstDate(9, 1), jstDate(9, 21), false)
+	now := time.Date(2025, 9, 30, 12, 0, 0, 0, statsJST)
+
+	days, err := NewEngine().Timeline(Input{Logs: logs, Now: now, Regimen: regimen, Location: statsJST}, jstDate(9, 20), jstDate(10, 10))
+	require.NoError(t, err)
+	require.Len(t, days, 11, "当日（9/30）までを返す")
+
+	assert.Equal(t, Day{Date: jstDate(9, 20), Taken: true, Severity: BleedingNone, Phase: PhaseIntake, Streak: 20}, days[0])
+	assert.Equal(t, Day{Date: jstDate(9, 21), Taken: true, Severity: BleedingNone, Phase: PhaseIntake, Streak: 21}, days[1])
+	assert.Equal(t, Day{Date: jstDate(9, 22), Severity: BleedingNone, Phase: PhaseRest}, days[2])
+	assert.Equal(t, Day{Date: jstDate(9, 28), Severity: BleedingNone, Phase: PhaseRest}, days[8])
+	assert.Equal(t, Day{Date: jstDate(9, 29), Severity: BleedingNone, Phase: PhaseIntake}, days[9], "次の周期が始まる")
+
+	_, err = NewEngine().Timeline(Input{Now: now, Regimen: Regimen{Type: "unknown"}}, jstDate(9, 1), jstDate(9, 30))
+	assert.ErrorIs(t, err, ErrUnsupportedRegimenType)
+}
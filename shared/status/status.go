@@ -0,0 +1,120 @@
+// Package status は服用記録とレジメンから服薬ステータス（連続服用日数・休薬期間など）を計算する
+// バックエンドAPIと通知Lambdaの両方から使用し、アプリと通知で判定が食い違わないようにする
+package status
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// ErrUnsupportedRegimenType は計算方法が登録されていないレジメンの種類を指定した場合のエラー
+var ErrUnsupportedRegimenType = errors.New("unsupported regimen type")
+
+// Phase は服薬フェーズ
+type Phase string
+
+const (
+	PhaseIntake Phase = "intake" // 服用期間
+	PhaseRest   Phase = "rest"   // 休薬期間
+)
+
+// Log はステータス計算に使用する服用記録
+type Log struct {
+	TakenAt     time.Time        // 服用日時
+	HasBleeding bool             // 出血の有無（Severityが空の場合に使用する）
+	Severity    BleedingSeverity // 出血の程度（空の場合はHasBleedingから判定する）
+}
+
+// severity は出血の程度を返す（程度を記録する前の記録はHasBleedingから変換する）
+func (l Log) severity() BleedingSeverity {
+	if l.Severity != "" {
+		return l.Severity
+	}
+	return SeverityFromHasBleeding(l.HasBleeding)
+}
+
+// Input はステータス計算の入力
+type Input struct {
+	Logs     []Log // Engine.Evaluateでは順不同、Strategy.Evaluateには新しい順にソート済みで渡される
+	Now      time.Time
+	Regimen  Regimen
+	Location *time.Location // 日付の境界に使用するユーザーのタイムゾーン（nilの場合はNowのタイムゾーン）
+}
+
+// Result はステータス計算の結果
+type Result struct {
+	CurrentStreak           int       // 現在の連続服用日数
+	IsRestPeriod            bool      // 休薬期間中かどうか
+	RestDaysLeft            int       // 休薬期間の残り日数（休薬期間中の場合）
+	ConsecutiveBleedingDays int       // 連続出血日数（レジメンの出血の程度のしきい値以上の日だけを数える）
+	RegimenType             string    // 適用されたレジメンの種類
+	Phase                   Phase     // 現在のフェーズ
+	NextRestDate            time.Time // 次の休薬開始予定日（予測できない場合はゼロ値）
+}
+
+// Strategy はレジメンの種類ごとの服薬ステータスの計算方法
+type Strategy interface {
+	Evaluate(input Input) Result
+}
+
+// Engine はレジメンの種類に応じた計算方法でステータスを計算する
+type Engine struct {
+	strategies map[string]Strategy
+}
+
+// NewEngine は組み込みのレジメンの計算方法を登録したEngineを作成する
+func NewEngine() *Engine {
+	return &Engine{
+		strategies: map[string]Strategy{
+			RegimenTypeFlexibleExtended: FlexibleExtendedStrategy{},
+			RegimenTypeFixedCycle:       FixedCycleStrategy{},
+			RegimenTypeContinuous:       ContinuousStrategy{},
+		},
+	}
+}
+
+// Register はレジメンの種類に対応する計算方法を登録する
+// 組み込みの種類を指定した場合は計算方法を置き換える
+func (e *Engine) Register(regimenType string, strategy Strategy) {
+	e.strategies[regimenType] = strategy
+}
+
+// Supports はレジメンの種類に対応する計算方法が登録されているかを返す
+func (e *Engine) Supports(regimenType string) bool {
+	_, ok := e.strategies[regimenType]
+	return ok
+}
+
+// Evaluate は服用記録を新しい順に並べ替え、レジメンの種類に応じた計算方法でステータスを計算する
+// Locationが指定されている場合は、現在時刻と服用日時をそのタイムゾーンに変換してから日付を判定する
+func (e *Engine) Evaluate(input Input) (Result, error) {
+	strategy, ok := e.strategies[input.Regimen.normalizedType()]
+	if !ok {
+		return Result{}, ErrUnsupportedRegimenType
+	}
+
+	if input.Location != nil {
+		input.Now = input.Now.In(input.Location)
+	}
+	input.Logs = sortedLogs(input.Logs, input.Location)
+
+	return strategy.Evaluate(input), nil
+}
+
+// sortedLogs は服用記録をコピーし、locのタイムゾーンに変換して新しい順に並べ替える（locがnilの場合は変換しない）
+func sortedLogs(input []Log, loc *time.Location) []Log {
+	logs := make([]Log, len(input))
+	copy(logs, input)
+	if loc != nil {
+		for i := range logs {
+			logs[i].TakenAt = logs[i].TakenAt.In(loc)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].TakenAt.After(logs[j].TakenAt)
+	})
+	return logs
+}
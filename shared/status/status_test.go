@@ -0,0 +1,438 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 9, 25, 12, 0, 0, 0, time.UTC)
+
+// day はtestNowからdaysAgo日前の日付を返す（負の値の場合は未来日）
+func day(daysAgo int) time.Time {
+	return truncateToDay(testNow).AddDate(0, 0, -daysAgo)
+}
+
+// intakeRange はfrom日前からto日前まで毎日服用した記録を作成する
+func intakeRange(from, to int, hasBleeding bool) []Log {
+	var logs []Log
+	for daysAgo := from; daysAgo >= to; daysAgo-- {
+		logs = append(logs, Log{TakenAt: day(daysAgo).Add(9 * time.Hour), HasBleeding: hasBleeding})
+	}
+	return logs
+}
+
+func concat(logs ...[]Log) []Log {
+	var result []Log
+	for _, l := range logs {
+		result = append(result, l...)
+	}
+	return result
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	withMax := func(maxDays int) Regimen {
+		regimen := DefaultRegimen()
+		regimen.MaxContinuousDays = maxDays
+		return regimen
+	}
+	fixed := func(activeDays, restDays int, cycleStart string) Regimen {
+		return Regimen{Type: RegimenTypeFixedCycle, ActiveDays: activeDays, RestPeriodDays: restDays, CycleStartDate: cycleStart}
+	}
+
+	tests := []struct {
+		name    string
+		regimen Regimen
+		logs    []Log
+		want    Result
+	}{
+		{
+			name:    "フレキシブル: 記録がない場合は服用期間",
+			regimen: DefaultRegimen(),
+			want:    Result{RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			name:    "フレキシブル: 出血なしで10日連続服用",
+			regimen: DefaultRegimen(),
+			logs:    intakeRange(9, 0, false),
+			want:    Result{CurrentStreak: 10, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			name:    "フレキシブル: 昨日まで服用していれば連続が途切れない",
+			regimen: DefaultRegimen(),
+			logs:    intakeRange(5, 1, false),
+			want:    Result{CurrentStreak: 5, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			name:    "フレキシブル: 2日以上記録がなければ連続は0",
+			regimen: DefaultRegimen(),
+			logs:    intakeRange(5, 2, false),
+			want:    Result{CurrentStreak: 0, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			name:    "フレキシブル: 連続2日の出血では休薬しない",
+			regimen: DefaultRegimen(),
+			logs:    concat(intakeRange(9, 2, false), intakeRange(1, 0, true)),
+			want: Result{
+				CurrentStreak: 10, ConsecutiveBleedingDays: 2,
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "フレキシブル: 連続3日の出血で休薬期間に入る",
+			regimen: DefaultRegimen(),
+			logs:    concat(intakeRange(9, 3, false), intakeRange(2, 0, true)),
+			want: Result{
+				IsRestPeriod: true, RestDaysLeft: 3, ConsecutiveBleedingDays: 3,
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseRest,
+			},
+		},
+		{
+			name:    "フレキシブル: 休薬期間明けは休薬終了日から連続日数を数える",
+			regimen: DefaultRegimen(),
+			logs:    concat(intakeRange(20, 11, false), intakeRange(10, 8, true), intakeRange(3, 0, false)),
+			want:    Result{CurrentStreak: 4, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			name: "フレキシブル: 最低服用日数に満たない出血では休薬しない",
+			regimen: func() Regimen {
+				regimen := DefaultRegimen()
+				regimen.MinIntakeDaysBeforeRest = 10
+				return regimen
+			}(),
+			logs: concat(intakeRange(5, 3, false), intakeRange(2, 0, true)),
+			want: Result{
+				CurrentStreak: 6, ConsecutiveBleedingDays: 3,
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "フレキシブル: 上限日数の連続服用で強制的に休薬に入る",
+			regimen: withMax(5),
+			logs:    intakeRange(5, 1, false),
+			want: Result{
+				IsRestPeriod: true, RestDaysLeft: 4, NextRestDate: day(-9),
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseRest,
+			},
+		},
+		{
+			name:    "フレキシブル: 上限日数がある場合は次回休薬日を返す",
+			regimen: withMax(120),
+			logs:    intakeRange(23, 0, false),
+			want: Result{
+				CurrentStreak: 24, NextRestDate: day(23).AddDate(0, 0, 120),
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "周期投与: 記録も起点日もない場合は服用期間",
+			regimen: fixed(21, 7, ""),
+			want:    Result{RegimenType: RegimenTypeFixedCycle, Phase: PhaseIntake},
+		},
+		{
+			name:    "周期投与: 起点日が未設定の場合は最初の服用日から数える",
+			regimen: fixed(21, 7, ""),
+			logs:    intakeRange(5, 0, false),
+			want: Result{
+				CurrentStreak: 6, NextRestDate: day(5).AddDate(0, 0, 21),
+				RegimenType: RegimenTypeFixedCycle, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "周期投与: 21/7の服用日数を過ぎると休薬期間",
+			regimen: fixed(21, 7, day(23).Format(dateLayout)),
+			logs:    intakeRange(23, 3, false),
+			want: Result{
+				IsRestPeriod: true, RestDaysLeft: 5, NextRestDate: day(23).AddDate(0, 0, 28+21),
+				RegimenType: RegimenTypeFixedCycle, Phase: PhaseRest,
+			},
+		},
+		{
+			name:    "周期投与: 24/4の2周期目",
+			regimen: fixed(24, 4, day(30).Format(dateLayout)),
+			logs:    intakeRange(2, 0, false),
+			want: Result{
+				CurrentStreak: 3, NextRestDate: day(2).AddDate(0, 0, 24),
+				RegimenType: RegimenTypeFixedCycle, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "周期投与: 起点日が未来の場合は開始前の服用期間",
+			regimen: fixed(21, 7, day(-3).Format(dateLayout)),
+			want: Result{
+				NextRestDate: day(-3).AddDate(0, 0, 21),
+				RegimenType:  RegimenTypeFixedCycle, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "周期投与: 出血があっても休薬に入らない",
+			regimen: fixed(21, 7, day(5).Format(dateLayout)),
+			logs:    intakeRange(5, 0, true),
+			want: Result{
+				CurrentStreak: 6, ConsecutiveBleedingDays: 6, NextRestDate: day(5).AddDate(0, 0, 21),
+				RegimenType: RegimenTypeFixedCycle, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "連続投与: 出血が続いても休薬しない",
+			regimen: Regimen{Type: RegimenTypeContinuous},
+			logs:    concat(intakeRange(29, 5, false), intakeRange(4, 0, true)),
+			want: Result{
+				CurrentStreak: 30, ConsecutiveBleedingDays: 5,
+				RegimenType: RegimenTypeContinuous, Phase: PhaseIntake,
+			},
+		},
+		{
+			name:    "種類が未指定の場合はフレキシブル投与として計算する",
+			regimen: Regimen{RestPeriodDays: 4, BleedingTriggerDays: 3},
+			logs:    intakeRange(2, 0, false),
+			want:    Result{CurrentStreak: 3, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Evaluate(Input{Logs: tt.logs, Now: testNow, Regimen: tt.regimen})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEngineEvaluateSortsLogs(t *testing.T) {
+	logs := concat(intakeRange(9, 3, false), intakeRange(2, 0, true))
+	reversed := make([]Log, len(logs))
+	for i, log := range logs {
+		reversed[len(logs)-1-i] = log
+	}
+
+	engine := NewEngine()
+	want, err := engine.Evaluate(Input{Logs: logs, Now: testNow, Regimen: DefaultRegimen()})
+	require.NoError(t, err)
+	got, err := engine.Evaluate(Input{Logs: reversed, Now: testNow, Regimen: DefaultRegimen()})
+	require.NoError(t, err)
+
+	assert.Equal(t, want, got)
+	assert.True(t, got.IsRestPeriod)
+	assert.Equal(t, day(9).Add(9*time.Hour), logs[0].TakenAt, "入力のスライスは並べ替えない")
+}
+
+func TestEngineEvaluateLocation(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	// 日本時間の朝8時に3日連続で出血ありの服用（UTCでは前日の23時）
+	var logs []Log
+	for _, date := range []int{23, 24, 25} {
+		logs = append(logs, Log{TakenAt: time.Date(2025, 9, date, 8, 0, 0, 0, jst), HasBleeding: true})
+	}
+	now := time.Date(2025, 9, 25, 12, 0, 0, 0, jst)
+
+	tests := []struct {
+		name             string
+		location         *time.Location
+		wantRestDaysLeft int
+	}{
+		// 休薬開始は9/23（JST）、9/27の終わりまで休薬
+		{name: "ユーザーのタイムゾーンで日付を判定する", location: jst, wantRestDaysLeft: 3},
+		// 休薬開始が9/22（UTC）と判定され、1日早く終わる
+		{name: "UTCで判定すると日付がずれる", location: time.UTC, wantRestDaysLeft: 2},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Evaluate(Input{Logs: logs, Now: now, Regimen: DefaultRegimen(), Location: tt.location})
+			require.NoError(t, err)
+			assert.True(t, got.IsRestPeriod)
+			assert.Equal(t, tt.wantRestDaysLeft, got.RestDaysLeft)
+		})
+	}
+}
+
+func TestEngineEvaluateDaylightSavingTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 3/8に夏時間が始まり、3/7〜3/8の間は23時間になる
+	logsWithBleeding := func(bleedingFrom int) []Log {
+		var logs []Log
+		for date := 6; date <= 10; date++ {
+			logs = append(logs, Log{TakenAt: time.Date(2026, 3, date, 9, 0, 0, 0, newYork), HasBleeding: date >= bleedingFrom})
+		}
+		return logs
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, newYork)
+
+	tests := []struct {
+		name string
+		logs []Log
+		want Result
+	}{
+		{
+			name: "夏時間の切り替えを挟んでも連続服用が途切れない",
+			logs: logsWithBleeding(11),
+			want: Result{CurrentStreak: 5, RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseIntake},
+		},
+		{
+			// 休薬開始は3/8、3/12の終わりまで休薬
+			name: "夏時間の切り替えを挟んでも連続出血が途切れない",
+			logs: logsWithBleeding(8),
+			want: Result{
+				IsRestPeriod: true, RestDaysLeft: 3, ConsecutiveBleedingDays: 3,
+				RegimenType: RegimenTypeFlexibleExtended, Phase: PhaseRest,
+			},
+		},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Evaluate(Input{Logs: tt.logs, Now: now, Regimen: DefaultRegimen(), Location: newYork})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type stubStrategy struct{ result Result }
+
+func (s stubStrategy) Evaluate(Input) Result { return s.result }
+
+func TestEngineRegister(t *testing.T) {
+	engine := NewEngine()
+
+	_, err := engine.Evaluate(Input{Now: testNow, Regimen: Regimen{Type: "custom"}})
+	assert.ErrorIs(t, err, ErrUnsupportedRegimenType)
+	assert.False(t, engine.Supports("custom"))
+
+	engine.Register("custom", stubStrategy{result: Result{RegimenType: "custom", Phase: PhaseRest}})
+	assert.True(t, engine.Supports("custom"))
+
+	got, err := engine.Evaluate(Input{Now: testNow, Regimen: Regimen{Type: "custom"}})
+	require.NoError(t, err)
+	assert.Equal(t, PhaseRest, got.Phase)
+}
+
+func TestRegimenValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		regimen Regimen
+		wantErr bool
+	}{
+		{name: "デフォルト", regimen: DefaultRegimen()},
+		{name: "フレキシブル: 休薬日数が0", regimen: Regimen{Type: RegimenTypeFlexibleExtended, BleedingTriggerDays: 3}, wantErr: true},
+		{name: "フレキシブル: 上限日数が最低服用日数未満", regimen: Regimen{
+			Type: RegimenTypeFlexibleExtended, RestPeriodDays: 4, BleedingTriggerDays: 3,
+			MinIntakeDaysBeforeRest: 30, MaxContinuousDays: 20,
+		}, wantErr: true},
+		{name: "周期投与", regimen: Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7, CycleStartDate: "2025-09-01"}},
+		{name: "周期投与: 服用日数が0", regimen: Regimen{Type: RegimenTypeFixedCycle, RestPeriodDays: 7}, wantErr: true},
+		{name: "周期投与: 起点日の形式が不正", regimen: Regimen{Type: RegimenTypeFixedCycle, ActiveDays: 21, RestPeriodDays: 7, CycleStartDate: "2025/09/01"}, wantErr: true},
+		{name: "連続投与", regimen: Regimen{Type: RegimenTypeContinuous}},
+		{name: "出血の程度のしきい値", regimen: Regimen{
+			Type: RegimenTypeFlexibleExtended, RestPeriodDays: 4, BleedingTriggerDays: 3, BleedingThreshold: BleedingLight,
+		}},
+		{name: "出血の程度のしきい値が出血なし", regimen: Regimen{
+			Type: RegimenTypeFlexibleExtended, RestPeriodDays: 4, BleedingTriggerDays: 3, BleedingThreshold: BleedingNone,
+		}, wantErr: true},
+		{name: "出血の程度のしきい値が不明", regimen: Regimen{
+			Type: RegimenTypeFlexibleExtended, RestPeriodDays: 4, BleedingTriggerDays: 3, BleedingThreshold: "severe",
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.regimen.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEngineEvaluateBleedingThreshold(t *testing.T) {
+	// severityDays はfrom日前からto日前まで毎日、指定した程度の出血ありで服用した記録を作成する
+	severityDays := func(from, to int, severity BleedingSeverity) []Log {
+		logs := intakeRange(from, to, false)
+		for i := range logs {
+			logs[i].Severity = severity
+		}
+		return logs
+	}
+	withThreshold := func(threshold BleedingSeverity) Regimen {
+		regimen := DefaultRegimen()
+		regimen.BleedingThreshold = threshold
+		return regimen
+	}
+
+	tests := []struct {
+		name         string
+		regimen      Regimen
+		logs         []Log
+		wantRest     bool
+		wantBleeding int
+	}{
+		{
+			name:         "しきい値が未設定の場合は点状出血も数える",
+			regimen:      DefaultRegimen(),
+			logs:         concat(intakeRange(9, 3, false), severityDays(2, 0, BleedingSpotting)),
+			wantRest:     true,
+			wantBleeding: 3,
+		},
+		{
+			name:         "しきい値未満の出血は数えない",
+			regimen:      withThreshold(BleedingLight),
+			logs:         concat(intakeRange(9, 3, false), severityDays(2, 1, BleedingLight), severityDays(0, 0, BleedingSpotting)),
+			wantBleeding: 0,
+		},
+		{
+			name:         "しきい値以上の出血が続けば休薬に入る",
+			regimen:      withThreshold(BleedingLight),
+			logs:         concat(intakeRange(9, 3, false), severityDays(2, 2, BleedingHeavy), severityDays(1, 0, BleedingLight)),
+			wantRest:     true,
+			wantBleeding: 3,
+		},
+		{
+			name:         "程度を記録する前の出血は中等量として扱う",
+			regimen:      withThreshold(BleedingModerate),
+			logs:         concat(intakeRange(9, 3, false), intakeRange(2, 0, true)),
+			wantRest:     true,
+			wantBleeding: 3,
+		},
+		{
+			name:         "程度を記録する前の出血は多量のしきい値には達しない",
+			regimen:      withThreshold(BleedingHeavy),
+			logs:         concat(intakeRange(9, 3, false), intakeRange(2, 0, true)),
+			wantBleeding: 0,
+		},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Evaluate(Input{Logs: tt.logs, Now: testNow, Regimen: tt.regimen})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRest, got.IsRestPeriod)
+			assert.Equal(t, tt.wantBleeding, got.ConsecutiveBleedingDays)
+		})
+	}
+}
+
+func TestBleedingSeverity(t *testing.T) {
+	severity, ok := ParseBleedingSeverity("moderate")
+	assert.True(t, ok)
+	assert.Equal(t, BleedingModerate, severity)
+	_, ok = ParseBleedingSeverity("severe")
+	assert.False(t, ok)
+
+	assert.True(t, BleedingHeavy.AtLeast(BleedingLight))
+	assert.False(t, BleedingSpotting.AtLeast(BleedingLight))
+	assert.False(t, BleedingNone.IsBleeding())
+	assert.True(t, BleedingSpotting.IsBleeding())
+	assert.Equal(t, BleedingModerate, SeverityFromHasBleeding(true))
+	assert.Equal(t, BleedingNone, SeverityFromHasBleeding(false))
+}
@@ -0,0 +1,169 @@
+package status
+
+import (
+	"math"
+	"time"
+)
+
+// FlexibleExtendedStrategy は連続出血で休薬に入るフレキシブル長期投与の計算方法
+// MaxContinuousDaysが設定されている場合は、出血がなくてもその日数で強制的に休薬に入る
+type FlexibleExtendedStrategy struct{}
+
+func (FlexibleExtendedStrategy) Evaluate(input Input) Result {
+	result := Result{
+		RegimenType: RegimenTypeFlexibleExtended,
+		Phase:       PhaseIntake,
+	}
+	if len(input.Logs) == 0 {
+		return result
+	}
+
+	regimen := input.Regimen
+	today := truncateToDay(input.Now)
+
+	// 休薬期間の判定と連続出血日数の計算
+	isInRestPeriod, restDaysLeft, consecutiveBleedingDays := calculateRestPeriodStatus(input.Logs, input.Now, regimen)
+	result.ConsecutiveBleedingDays = consecutiveBleedingDays
+	if isInRestPeriod {
+		result.IsRestPeriod = true
+		result.Phase = PhaseRest
+		result.RestDaysLeft = restDaysLeft
+		if regimen.MaxContinuousDays > 0 {
+			result.NextRestDate = today.AddDate(0, 0, restDaysLeft+regimen.MaxContinuousDays)
+		}
+		return result
+	}
+
+	// 休薬期間中でなければ、現在の連続服用日数を計算
+	result.CurrentStreak = calculateCurrentStreak(input.Logs, input.Now, regimen)
+	if regimen.MaxContinuousDays <= 0 {
+		// 上限がない場合、次の休薬は出血次第のため予測できない
+		return result
+	}
+
+	// 連続服用日数の上限による強制休薬
+	runStart, runLength := latestIntakeRun(extractUniqueDates(input.Logs))
+	if runLength >= regimen.MaxContinuousDays {
+		forcedRestStart := runStart.AddDate(0, 0, regimen.MaxContinuousDays)
+		forcedRestEnd := forcedRestStart.AddDate(0, 0, regimen.RestPeriodDays)
+		if !today.Before(forcedRestStart) && today.Before(forcedRestEnd) {
+			result.IsRestPeriod = true
+			result.Phase = PhaseRest
+			result.RestDaysLeft = daysBetween(today, forcedRestEnd)
+			result.CurrentStreak = 0
+			result.NextRestDate = forcedRestEnd.AddDate(0, 0, regimen.MaxContinuousDays)
+			return result
+		}
+	}
+
+	// 現在の連続服用の開始日から上限日数後が次の休薬開始日
+	streakStart := today
+	if result.CurrentStreak > 0 {
+		streakStart = runStart
+	}
+	result.NextRestDate = streakStart.AddDate(0, 0, regimen.MaxContinuousDays)
+	return result
+}
+
+// FixedCycleStrategy は服用日数と休薬日数を繰り返す周期投与（21/7、24/4など）の計算方法
+type FixedCycleStrategy struct{}
+
+func (FixedCycleStrategy) Evaluate(input Input) Result {
+	result := Result{
+		RegimenType: RegimenTypeFixedCycle,
+		Phase:       PhaseIntake,
+	}
+
+	regimen := input.Regimen
+	today := truncateToDay(input.Now)
+	uniqueDates := extractUniqueDates(input.Logs)
+
+	// 周期の起点日（未設定の場合は最初の服用日）
+	anchor, ok := cycleAnchor(regimen, uniqueDates, input.Now.Location())
+	if !ok {
+		return result
+	}
+	if len(input.Logs) > 0 {
+		_, _, result.ConsecutiveBleedingDays = calculateRestPeriodStatus(input.Logs, input.Now, regimen)
+	}
+
+	elapsed := daysBetween(anchor, today)
+	if elapsed < 0 {
+		// 周期開始前
+		result.NextRestDate = anchor.AddDate(0, 0, regimen.ActiveDays)
+		return result
+	}
+
+	cycleLength := regimen.ActiveDays + regimen.RestPeriodDays
+	dayInCycle := elapsed % cycleLength
+	cycleStart := today.AddDate(0, 0, -dayInCycle)
+
+	if dayInCycle < regimen.ActiveDays {
+		result.CurrentStreak = countConsecutiveDays(uniqueDates, cycleStart, input.Now)
+		result.NextRestDate = cycleStart.AddDate(0, 0, regimen.ActiveDays)
+		return result
+	}
+
+	result.IsRestPeriod = true
+	result.Phase = PhaseRest
+	result.RestDaysLeft = cycleLength - dayInCycle
+	result.NextRestDate = cycleStart.AddDate(0, 0, cycleLength+regimen.ActiveDays)
+	return result
+}
+
+// ContinuousStrategy は休薬を設けない連続投与の計算方法
+type ContinuousStrategy struct{}
+
+func (ContinuousStrategy) Evaluate(input Input) Result {
+	result := Result{
+		RegimenType: RegimenTypeContinuous,
+		Phase:       PhaseIntake,
+	}
+	if len(input.Logs) == 0 {
+		return result
+	}
+
+	_, _, result.ConsecutiveBleedingDays = calculateRestPeriodStatus(input.Logs, input.Now, input.Regimen)
+	result.CurrentStreak = countConsecutiveDays(extractUniqueDates(input.Logs), time.Time{}, input.Now)
+	return result
+}
+
+// cycleAnchor は周期投与の起点日を返す
+func cycleAnchor(regimen Regimen, uniqueDates []time.Time, loc *time.Location) (time.Time, bool) {
+	if regimen.CycleStartDate != "" {
+		anchor, err := time.ParseInLocation(dateLayout, regimen.CycleStartDate, loc)
+		if err == nil {
+			return anchor, true
+		}
+	}
+	if len(uniqueDates) == 0 {
+		return time.Time{}, false
+	}
+	// uniqueDatesは降順のため末尾が最初の服用日
+	return truncateToDay(uniqueDates[len(uniqueDates)-1]), true
+}
+
+// latestIntakeRun は最新の服用日から途切れずに続く服用期間の開始日と日数を返す
+// datesは重複を除去した服用日の降順リスト
+func latestIntakeRun(dates []time.Time) (time.Time, int) {
+	if len(dates) == 0 {
+		return time.Time{}, 0
+	}
+
+	runStart := truncateToDay(dates[0])
+	length := 1
+	for _, date := range dates[1:] {
+		currDate := truncateToDay(date)
+		if daysBetween(currDate, runStart) != 1 {
+			break
+		}
+		runStart = currDate
+		length++
+	}
+	return runStart, length
+}
+
+// daysBetween はfromからtoまでの日数を返す（夏時間による23/25時間の日も1日として扱う）
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
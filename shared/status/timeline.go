@@ -0,0 +1,77 @@
+package status
+
+import (
+	"sort"
+	"time"
+)
+
+// Day は1日分の服用記録と、その日のステータス
+type Day struct {
+	Date     time.Time        // その日の0時（ユーザーのタイムゾーン）
+	Taken    bool             // 服用記録があるかどうか
+	Bleeding bool             // 出血ありの服用記録があるかどうか（点状出血を含む）
+	Severity BleedingSeverity // その日の最も重い出血の程度
+	Phase    Phase            // その日のフェーズ
+	Streak   int              // その日の連続服用日数
+}
+
+// Timeline はfromからtoまでの各日のステータスを古い順に計算する（toが当日より後の場合は当日まで）
+// 各日のステータスはその日の0時を現在時刻とし、その日までの服用記録だけで計算するため、
+// 過去の日についてもその日にアプリが表示していたステータスと一致する
+func (e *Engine) Timeline(input Input, from, to time.Time) ([]Day, error) {
+	strategy, ok := e.strategies[input.Regimen.normalizedType()]
+	if !ok {
+		return nil, ErrUnsupportedRegimenType
+	}
+
+	loc := input.Location
+	if loc == nil {
+		loc = input.Now.Location()
+	}
+	logs := sortedLogs(input.Logs, loc)
+	today := truncateToDay(input.Now.In(loc))
+	last := truncateToDay(to.In(loc))
+	if last.After(today) {
+		last = today
+	}
+
+	taken := make(map[string]bool)
+	severities := make(map[string]BleedingSeverity)
+	for _, log := range logs {
+		dateStr := log.TakenAt.Format(dateLayout)
+		taken[dateStr] = true
+		if severity := log.severity(); !severities[dateStr].AtLeast(severity) {
+			severities[dateStr] = severity
+		}
+	}
+
+	var days []Day
+	for date := truncateToDay(from.In(loc)); !date.After(last); date = date.AddDate(0, 0, 1) {
+		// logsは新しい順のため、その日の終わりまでの服用記録は末尾側に続く
+		endOfDay := date.AddDate(0, 0, 1)
+		i := sort.Search(len(logs), func(i int) bool {
+			return logs[i].TakenAt.Before(endOfDay)
+		})
+		result := strategy.Evaluate(Input{
+			Logs:     logs[i:],
+			Now:      date,
+			Regimen:  input.Regimen,
+			Location: loc,
+		})
+
+		dateStr := date.Format(dateLayout)
+		severity := severities[dateStr]
+		if severity == "" {
+			severity = BleedingNone
+		}
+		days = append(days, Day{
+			Date:     date,
+			Taken:    taken[dateStr],
+			Bleeding: severity.IsBleeding(),
+			Severity: severity,
+			Phase:    result.Phase,
+			Streak:   result.CurrentStreak,
+		})
+	}
+	return days, nil
+}